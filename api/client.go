@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -19,6 +21,26 @@ type Client struct {
 	APIKey  string
 	BaseURL string
 	HTTP    *http.Client
+
+	// DryRun, when set, receives every request that changes anything
+	// instead of the API; such requests succeed without a response body.
+	DryRun io.Writer
+}
+
+// Error is an unsuccessful API response.
+type Error struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("API error (HTTP %d): %s", e.StatusCode, e.Body)
+}
+
+// IsNotFound reports whether err is an API response with status 404.
+func IsNotFound(err error) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
 }
 
 func NewClient(apiKey string, timeout time.Duration) *Client {
@@ -46,6 +68,10 @@ func (c *Client) doJSON(req *http.Request, out any) error {
 }
 
 func (c *Client) doRequest(req *http.Request, out any) error {
+	if c.DryRun != nil && mutates(req) {
+		return c.printRequest(req)
+	}
+
 	resp, err := c.HTTP.Do(req)
 	if err != nil {
 		return fmt.Errorf("request failed: %w", err)
@@ -55,9 +81,9 @@ func (c *Client) doRequest(req *http.Request, out any) error {
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		body, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return fmt.Errorf("API error (HTTP %d): (unreadable body)", resp.StatusCode)
+			return &Error{StatusCode: resp.StatusCode, Body: "(unreadable body)"}
 		}
-		return fmt.Errorf("API error (HTTP %d): %s", resp.StatusCode, string(body))
+		return &Error{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	if out != nil {
@@ -68,6 +94,37 @@ func (c *Client) doRequest(req *http.Request, out any) error {
 	return nil
 }
 
+// mutates reports whether a request changes anything. Creating queries and
+// query results only runs read-only queries.
+func mutates(req *http.Request) bool {
+	if req.Method == http.MethodGet {
+		return false
+	}
+	if req.Method == http.MethodPost && (strings.Contains(req.URL.Path, "/1/queries/") || strings.Contains(req.URL.Path, "/1/query_results/")) {
+		return false
+	}
+	return true
+}
+
+// printRequest writes the method, URL and indented JSON body of a request
+// to DryRun.
+func (c *Client) printRequest(req *http.Request) error {
+	fmt.Fprintf(c.DryRun, "DRY RUN: %s %s\n", req.Method, req.URL)
+	if req.Body == nil {
+		return nil
+	}
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return fmt.Errorf("reading request body: %w", err)
+	}
+	var indented bytes.Buffer
+	if json.Indent(&indented, body, "", "  ") == nil {
+		body = indented.Bytes()
+	}
+	fmt.Fprintf(c.DryRun, "%s\n", body)
+	return nil
+}
+
 // Generic CRUD helpers
 
 // List retrieves a list of resources at the given path.
@@ -136,6 +193,26 @@ func Update[T any](c *Client, ctx context.Context, path string, body *T) (*T, er
 	return &result, nil
 }
 
+// Patch sends a partial update of the resource at the given path and returns
+// the updated resource.
+func Patch[T any](c *Client, ctx context.Context, path string, body *T) (*T, error) {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("encoding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPatch, c.BaseURL+path, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, err
+	}
+
+	var result T
+	if err := c.doJSON(req, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
 // Delete removes the resource at the given path.
 func Delete(c *Client, ctx context.Context, path string) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, c.BaseURL+path, nil)
@@ -0,0 +1,38 @@
+package api
+
+import "context"
+
+// DatasetDefinition names the column or derived column that plays a role in
+// a dataset.
+type DatasetDefinition struct {
+	Name       string `json:"name"`
+	ColumnType string `json:"column_type,omitempty"`
+}
+
+// DatasetDefinitions maps semantic roles such as trace ID or duration to
+// columns. Only set fields are sent in an update; a definition with an
+// empty name clears it.
+type DatasetDefinitions struct {
+	AnnotationType *DatasetDefinition `json:"annotation_type,omitempty"`
+	DurationMs     *DatasetDefinition `json:"duration_ms,omitempty"`
+	Error          *DatasetDefinition `json:"error,omitempty"`
+	LinkSpanID     *DatasetDefinition `json:"link_span_id,omitempty"`
+	LinkTraceID    *DatasetDefinition `json:"link_trace_id,omitempty"`
+	Name           *DatasetDefinition `json:"name,omitempty"`
+	ParentID       *DatasetDefinition `json:"parent_id,omitempty"`
+	Route          *DatasetDefinition `json:"route,omitempty"`
+	ServiceName    *DatasetDefinition `json:"service_name,omitempty"`
+	SpanID         *DatasetDefinition `json:"span_id,omitempty"`
+	SpanKind       *DatasetDefinition `json:"span_kind,omitempty"`
+	Status         *DatasetDefinition `json:"status,omitempty"`
+	TraceID        *DatasetDefinition `json:"trace_id,omitempty"`
+	User           *DatasetDefinition `json:"user,omitempty"`
+}
+
+func (c *Client) GetDatasetDefinitions(ctx context.Context, dataset string) (*DatasetDefinitions, error) {
+	return Get[DatasetDefinitions](c, ctx, "/1/dataset_definitions/"+dataset)
+}
+
+func (c *Client) UpdateDatasetDefinitions(ctx context.Context, dataset string, defs *DatasetDefinitions) (*DatasetDefinitions, error) {
+	return Patch[DatasetDefinitions](c, ctx, "/1/dataset_definitions/"+dataset, defs)
+}
@@ -0,0 +1,127 @@
+// Package budget does the error budget arithmetic behind SLOs and burn alerts.
+//
+// Burn rates are multiples of the sustainable rate: a burn rate of 1 uses up
+// exactly the whole budget over the SLO's time period, a burn rate of 10 uses
+// it up in a tenth of the period.
+package budget
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+// PerMillion is the denominator of SLO targets and budget rate thresholds.
+const PerMillion = 1_000_000
+
+// SLO is the part of an SLO definition that determines its error budget.
+type SLO struct {
+	TargetPerMillion int
+	TimePeriodDays   int
+}
+
+// Validate checks that the target and period describe a usable budget.
+func (s SLO) Validate() error {
+	if s.TargetPerMillion <= 0 || s.TargetPerMillion >= PerMillion {
+		return fmt.Errorf("target per million must be between 1 and 999999, got %d", s.TargetPerMillion)
+	}
+	if s.TimePeriodDays <= 0 {
+		return fmt.Errorf("time period days must be positive, got %d", s.TimePeriodDays)
+	}
+	return nil
+}
+
+// Period returns the SLO's evaluation window.
+func (s SLO) Period() time.Duration {
+	return time.Duration(s.TimePeriodDays) * 24 * time.Hour
+}
+
+// AllowedBadFraction returns the fraction of eligible events that may fail.
+func (s SLO) AllowedBadFraction() float64 {
+	return float64(PerMillion-s.TargetPerMillion) / PerMillion
+}
+
+// AllowedDowntime returns how long a total outage could last before the
+// whole budget is spent, assuming evenly distributed traffic.
+func (s SLO) AllowedDowntime() time.Duration {
+	return time.Duration(float64(s.Period()) * s.AllowedBadFraction())
+}
+
+// TimeToExhaustion returns how long the remaining budget (a fraction of the
+// whole budget, 0–1) lasts at burnRate. ok is false when the budget is never
+// exhausted at that rate.
+func (s SLO) TimeToExhaustion(remaining, burnRate float64) (d time.Duration, ok bool) {
+	if remaining <= 0 {
+		return 0, true
+	}
+	if burnRate <= 0 {
+		return 0, false
+	}
+	return time.Duration(remaining * float64(s.Period()) / burnRate), true
+}
+
+// BurnRateForExhaustion returns the burn rate at which the remaining budget
+// runs out within the given number of minutes, which is what an
+// exhaustion_time burn alert with those exhaustion minutes fires on.
+func (s SLO) BurnRateForExhaustion(remaining float64, minutes int) float64 {
+	if minutes <= 0 {
+		return math.Inf(1)
+	}
+	return remaining * float64(s.Period()) / (float64(minutes) * float64(time.Minute))
+}
+
+// ExhaustionMinutes is the inverse of BurnRateForExhaustion: the exhaustion
+// minutes that make an exhaustion_time alert fire at burnRate.
+func (s SLO) ExhaustionMinutes(remaining, burnRate float64) int {
+	d, ok := s.TimeToExhaustion(remaining, burnRate)
+	if !ok {
+		return 0
+	}
+	return int(math.Round(d.Minutes()))
+}
+
+// DecreasePerMillion returns how much of the budget, in parts per million,
+// burning at burnRate consumes within window. This is the
+// budget_rate_decrease_threshold_per_million a budget_rate burn alert with
+// that window needs to fire on burnRate.
+func (s SLO) DecreasePerMillion(burnRate float64, window time.Duration) int {
+	return int(math.Round(burnRate * float64(window) / float64(s.Period()) * PerMillion))
+}
+
+// BurnRateForDecrease is the inverse of DecreasePerMillion.
+func (s SLO) BurnRateForDecrease(perMillion int, window time.Duration) float64 {
+	if window <= 0 {
+		return math.Inf(1)
+	}
+	return float64(perMillion) / PerMillion * float64(s.Period()) / float64(window)
+}
+
+// FormatDuration formats d for people, e.g. "43m 12s" or "3d 4h", keeping
+// at most two adjacent units.
+func FormatDuration(d time.Duration) string {
+	if d < time.Second {
+		return d.Round(time.Millisecond).String()
+	}
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+	var parts []string
+	for _, u := range units {
+		n := d / u.size
+		d -= n * u.size
+		if n > 0 {
+			parts = append(parts, fmt.Sprintf("%d%s", n, u.name))
+		}
+		if len(parts) == 2 || (len(parts) == 1 && n == 0) {
+			break
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,75 @@
+package budget_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/LarsEckart/hccli/budget"
+)
+
+func TestSLOBudget(t *testing.T) {
+	slo := budget.SLO{TargetPerMillion: 999000, TimePeriodDays: 30}
+	if err := slo.Validate(); err != nil {
+		t.Fatalf("Validate returned error: %v", err)
+	}
+
+	if got := slo.AllowedBadFraction(); math.Abs(got-0.001) > 1e-12 {
+		t.Errorf("AllowedBadFraction = %v, want 0.001", got)
+	}
+	if got := slo.AllowedDowntime(); got != 43*time.Minute+12*time.Second {
+		t.Errorf("AllowedDowntime = %v, want 43m12s", got)
+	}
+
+	d, ok := slo.TimeToExhaustion(0.5, 2)
+	if !ok || d != 180*time.Hour {
+		t.Errorf("TimeToExhaustion(0.5, 2) = %v, %v; want 180h, true", d, ok)
+	}
+	if _, ok := slo.TimeToExhaustion(0.5, 0); ok {
+		t.Error("expected no exhaustion at burn rate 0")
+	}
+
+	if got := slo.BurnRateForExhaustion(1, 1440); got != 30 {
+		t.Errorf("BurnRateForExhaustion(1, 1440) = %v, want 30", got)
+	}
+	if got := slo.ExhaustionMinutes(1, 30); got != 1440 {
+		t.Errorf("ExhaustionMinutes(1, 30) = %v, want 1440", got)
+	}
+	if got := slo.DecreasePerMillion(14.4, time.Hour); got != 20000 {
+		t.Errorf("DecreasePerMillion(14.4, 1h) = %v, want 20000", got)
+	}
+	if got := slo.BurnRateForDecrease(50000, 6*time.Hour); math.Abs(got-6) > 1e-9 {
+		t.Errorf("BurnRateForDecrease(50000, 6h) = %v, want 6", got)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	for _, slo := range []budget.SLO{
+		{TargetPerMillion: 0, TimePeriodDays: 30},
+		{TargetPerMillion: 1000000, TimePeriodDays: 30},
+		{TargetPerMillion: 999000, TimePeriodDays: 0},
+	} {
+		if err := slo.Validate(); err == nil {
+			t.Errorf("expected Validate(%+v) to fail", slo)
+		}
+	}
+}
+
+func TestFormatDuration(t *testing.T) {
+	tests := []struct {
+		d    time.Duration
+		want string
+	}{
+		{43*time.Minute + 12*time.Second, "43m 12s"},
+		{30 * 24 * time.Hour, "30d"},
+		{76*time.Hour + 5*time.Minute, "3d 4h"},
+		{26*time.Hour + 30*time.Second, "1d 2h"},
+		{24*time.Hour + 5*time.Minute, "1d"},
+		{500 * time.Millisecond, "500ms"},
+	}
+	for _, tt := range tests {
+		if got := budget.FormatDuration(tt.d); got != tt.want {
+			t.Errorf("FormatDuration(%v) = %q, want %q", tt.d, got, tt.want)
+		}
+	}
+}
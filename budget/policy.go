@@ -0,0 +1,124 @@
+package budget
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Alert types understood by burn alerts.
+const (
+	ExhaustionTime = "exhaustion_time"
+	BudgetRate     = "budget_rate"
+)
+
+// minBudgetRateWindow is the shortest window a budget_rate alert accepts.
+const minBudgetRateWindow = 60
+
+// Policy is a named set of burn alerts to create for an SLO.
+type Policy struct {
+	Name   string        `json:"name"`
+	Alerts []PolicyAlert `json:"alerts"`
+}
+
+// PolicyAlert describes one burn alert of a policy. Thresholds are given
+// either as a burn rate, which is translated for the SLO's time period, or
+// literally as exhaustion minutes or a decrease per million.
+type PolicyAlert struct {
+	Description        string  `json:"description"`
+	Severity           string  `json:"severity"`
+	AlertType          string  `json:"alert_type"`
+	BurnRate           float64 `json:"burn_rate,omitempty"`
+	ExhaustionMinutes  int     `json:"exhaustion_minutes,omitempty"`
+	WindowMinutes      int     `json:"window_minutes,omitempty"`
+	DecreasePerMillion int     `json:"decrease_per_million,omitempty"`
+}
+
+// Threshold is a policy alert resolved for a specific SLO.
+type Threshold struct {
+	Description        string  `json:"description"`
+	Severity           string  `json:"severity"`
+	AlertType          string  `json:"alert_type"`
+	ExhaustionMinutes  int     `json:"exhaustion_minutes,omitempty"`
+	WindowMinutes      int     `json:"window_minutes,omitempty"`
+	DecreasePerMillion int     `json:"decrease_per_million,omitempty"`
+	BurnRate           float64 `json:"burn_rate"`
+}
+
+// Policies are the built-in policies, by name.
+//
+// "standard" follows the multi-window, multi-burn-rate alerts of the SRE
+// workbook: page when 2% of a 30-day budget burns within an hour or 5%
+// within six hours, open a ticket when 10% burns within three days, and
+// back those up with exhaustion-time alerts on the remaining budget.
+var Policies = map[string]Policy{
+	"standard": {
+		Name: "standard",
+		Alerts: []PolicyAlert{
+			{Description: "standard: fast burn (page)", Severity: "page", AlertType: BudgetRate, WindowMinutes: 60, BurnRate: 14.4},
+			{Description: "standard: medium burn (page)", Severity: "page", AlertType: BudgetRate, WindowMinutes: 360, BurnRate: 6},
+			{Description: "standard: slow burn (ticket)", Severity: "ticket", AlertType: BudgetRate, WindowMinutes: 4320, BurnRate: 1},
+			{Description: "standard: exhausted within 4 hours (page)", Severity: "page", AlertType: ExhaustionTime, ExhaustionMinutes: 240},
+			{Description: "standard: exhausted within 1 day (ticket)", Severity: "ticket", AlertType: ExhaustionTime, ExhaustionMinutes: 1440},
+		},
+	},
+}
+
+// Resolve computes the concrete thresholds of every alert in the policy for slo.
+// Budget rate windows longer than the SLO's time period are shortened to it.
+func (p Policy) Resolve(slo SLO) ([]Threshold, error) {
+	if err := slo.Validate(); err != nil {
+		return nil, err
+	}
+	if len(p.Alerts) == 0 {
+		return nil, fmt.Errorf("policy %q has no alerts", p.Name)
+	}
+
+	periodMinutes := int(slo.Period() / time.Minute)
+	seen := map[string]bool{}
+	thresholds := make([]Threshold, 0, len(p.Alerts))
+	for i, a := range p.Alerts {
+		if a.Description == "" {
+			return nil, fmt.Errorf("alert %d: description is required", i+1)
+		}
+		if seen[a.Description] {
+			return nil, fmt.Errorf("alert %d: duplicate description %q", i+1, a.Description)
+		}
+		seen[a.Description] = true
+
+		t := Threshold{Description: a.Description, Severity: a.Severity, AlertType: a.AlertType}
+		switch a.AlertType {
+		case ExhaustionTime:
+			switch {
+			case a.ExhaustionMinutes > 0:
+				t.ExhaustionMinutes = a.ExhaustionMinutes
+			case a.BurnRate > 0:
+				t.ExhaustionMinutes = slo.ExhaustionMinutes(1, a.BurnRate)
+			default:
+				return nil, fmt.Errorf("alert %q: exhaustion_minutes or burn_rate is required", a.Description)
+			}
+			t.BurnRate = slo.BurnRateForExhaustion(1, t.ExhaustionMinutes)
+		case BudgetRate:
+			if a.WindowMinutes < minBudgetRateWindow {
+				return nil, fmt.Errorf("alert %q: window_minutes must be at least %d", a.Description, minBudgetRateWindow)
+			}
+			t.WindowMinutes = min(a.WindowMinutes, periodMinutes)
+			window := time.Duration(t.WindowMinutes) * time.Minute
+			switch {
+			case a.DecreasePerMillion > 0:
+				t.DecreasePerMillion = a.DecreasePerMillion
+			case a.BurnRate > 0:
+				t.DecreasePerMillion = slo.DecreasePerMillion(a.BurnRate, window)
+			default:
+				return nil, fmt.Errorf("alert %q: decrease_per_million or burn_rate is required", a.Description)
+			}
+			t.DecreasePerMillion = max(1, min(t.DecreasePerMillion, PerMillion))
+			t.BurnRate = slo.BurnRateForDecrease(t.DecreasePerMillion, window)
+		default:
+			return nil, fmt.Errorf("alert %q: alert_type must be %s or %s", a.Description, ExhaustionTime, BudgetRate)
+		}
+		t.BurnRate = math.Round(t.BurnRate*100) / 100
+		thresholds = append(thresholds, t)
+	}
+	return thresholds, nil
+}
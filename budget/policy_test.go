@@ -0,0 +1,68 @@
+package budget_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LarsEckart/hccli/budget"
+)
+
+func TestStandardPolicy(t *testing.T) {
+	thresholds, err := budget.Policies["standard"].Resolve(budget.SLO{TargetPerMillion: 999000, TimePeriodDays: 30})
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	if len(thresholds) != 5 {
+		t.Fatalf("expected 5 thresholds, got %d", len(thresholds))
+	}
+
+	fast := thresholds[0]
+	if fast.AlertType != budget.BudgetRate || fast.WindowMinutes != 60 || fast.DecreasePerMillion != 20000 || fast.BurnRate != 14.4 {
+		t.Errorf("unexpected fast burn threshold: %+v", fast)
+	}
+	slow := thresholds[2]
+	if slow.WindowMinutes != 4320 || slow.DecreasePerMillion != 100000 {
+		t.Errorf("unexpected slow burn threshold: %+v", slow)
+	}
+	if e := thresholds[3]; e.ExhaustionMinutes != 240 || e.BurnRate != 180 {
+		t.Errorf("unexpected exhaustion threshold: %+v", e)
+	}
+}
+
+func TestPolicyResolveShortPeriod(t *testing.T) {
+	thresholds, err := budget.Policies["standard"].Resolve(budget.SLO{TargetPerMillion: 990000, TimePeriodDays: 1})
+	if err != nil {
+		t.Fatalf("Resolve returned error: %v", err)
+	}
+	slow := thresholds[2]
+	if slow.WindowMinutes != 1440 || slow.DecreasePerMillion != 1000000 {
+		t.Errorf("expected window clamped to the period, got %+v", slow)
+	}
+}
+
+func TestPolicyResolveErrors(t *testing.T) {
+	slo := budget.SLO{TargetPerMillion: 999000, TimePeriodDays: 30}
+	tests := []struct {
+		name    string
+		alerts  []budget.PolicyAlert
+		wantErr string
+	}{
+		{"no alerts", nil, "no alerts"},
+		{"missing description", []budget.PolicyAlert{{AlertType: budget.ExhaustionTime, ExhaustionMinutes: 60}}, "description is required"},
+		{"duplicate", []budget.PolicyAlert{
+			{Description: "a", AlertType: budget.ExhaustionTime, ExhaustionMinutes: 60},
+			{Description: "a", AlertType: budget.ExhaustionTime, ExhaustionMinutes: 120},
+		}, "duplicate description"},
+		{"short window", []budget.PolicyAlert{{Description: "a", AlertType: budget.BudgetRate, WindowMinutes: 30, BurnRate: 2}}, "at least 60"},
+		{"no threshold", []budget.PolicyAlert{{Description: "a", AlertType: budget.BudgetRate, WindowMinutes: 60}}, "burn_rate is required"},
+		{"bad type", []budget.PolicyAlert{{Description: "a", AlertType: "other"}}, "alert_type must be"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := budget.Policy{Name: "p", Alerts: tt.alerts}.Resolve(slo)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Resolve error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
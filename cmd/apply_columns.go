@@ -0,0 +1,342 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/datafile"
+	"github.com/LarsEckart/hccli/expr"
+	"github.com/urfave/cli/v3"
+)
+
+// applyColumnsFile is the input of apply-columns.
+type applyColumnsFile struct {
+	Columns        []api.Column        `json:"columns"`
+	DerivedColumns []api.DerivedColumn `json:"derived_columns"`
+}
+
+// applyResult reports what happened to one column or derived column.
+type applyResult struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Action string `json:"action"`
+	ID     string `json:"id,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+func ApplyColumnsCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "apply-columns",
+		Category: "Columns",
+		Usage:    "Create or update columns and derived columns from a file",
+		Description: `Create or update the columns and derived columns listed in a YAML or
+JSON file. Columns are matched by key_name and derived columns by alias;
+fields left out of a column, and a left out derived column description,
+keep their current value. Items that already match are reported as
+unchanged.
+
+  columns:
+    - key_name: http.route
+      type: string
+      description: Route template of the request
+    - key_name: debug.payload
+      hidden: true
+  derived_columns:
+    - alias: is_error
+      expression: GTE($http.status_code, 500)
+      description: Server errors
+
+Columns are applied before derived columns, and derived columns after
+the derived columns of the file they reference. Expressions are linted
+first unless --no-lint is given. Failures are reported per item without
+stopping the others.
+
+Example:
+
+  hccli apply-columns --dataset api --file columns.yaml`,
+		Flags: []cli.Flag{
+			DatasetFlag(),
+			&cli.StringFlag{
+				Name:     "file",
+				Usage:    "YAML or JSON file of columns and derived columns",
+				Required: true,
+			},
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Usage: "Maximum number of concurrent API requests",
+				Value: 4,
+			},
+			&cli.BoolFlag{
+				Name:  "no-lint",
+				Usage: "Send derived column expressions without checking them first",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "Output format: text or json",
+				Value: "text",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			dataset := cmd.String("dataset")
+			concurrency := int(cmd.Int("concurrency"))
+
+			var file applyColumnsFile
+			if err := datafile.Read(cmd.String("file"), &file); err != nil {
+				return fmt.Errorf("reading columns file: %w", err)
+			}
+			if err := validateColumnsFile(&file); err != nil {
+				return err
+			}
+
+			columns, err := client.ListColumns(ctx, dataset)
+			if err != nil {
+				return fmt.Errorf("listing columns: %w", err)
+			}
+			derived, err := client.ListDerivedColumns(ctx, dataset)
+			if err != nil {
+				return fmt.Errorf("listing derived columns: %w", err)
+			}
+
+			var known func(string) bool
+			if !cmd.Bool("no-lint") {
+				existing, err := knownColumns(ctx, client, dataset)
+				if err != nil {
+					return err
+				}
+				inFile := map[string]bool{}
+				for _, c := range file.Columns {
+					inFile[c.KeyName] = true
+				}
+				for _, dc := range file.DerivedColumns {
+					inFile[dc.Alias] = true
+				}
+				known = func(name string) bool { return inFile[name] || existing == nil || existing(name) }
+			}
+
+			existingColumns := map[string]api.Column{}
+			for _, c := range columns {
+				existingColumns[c.KeyName] = c
+			}
+			columnResults := make([]applyResult, len(file.Columns))
+			parallel(len(file.Columns), concurrency, func(i int) {
+				columnResults[i] = applyColumn(ctx, client, dataset, file.Columns[i], existingColumns)
+			})
+
+			existingDerived := map[string]api.DerivedColumn{}
+			for _, dc := range derived {
+				existingDerived[dc.Alias] = dc
+			}
+			derivedResults := make([]applyResult, len(file.DerivedColumns))
+			for _, wave := range derivedColumnWaves(file.DerivedColumns) {
+				parallel(len(wave), concurrency, func(j int) {
+					i := wave[j]
+					derivedResults[i] = applyDerivedColumn(ctx, client, dataset, file.DerivedColumns[i], existingDerived, known)
+				})
+			}
+
+			results := append(columnResults, derivedResults...)
+			if cmd.String("output") == "json" {
+				if err := printJSON(results); err != nil {
+					return err
+				}
+			} else {
+				printApplyResults(results)
+			}
+
+			var failed int
+			for _, r := range results {
+				if r.Error != "" {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d item(s) failed", failed, len(results))
+			}
+			return nil
+		},
+	}
+}
+
+func validateColumnsFile(file *applyColumnsFile) error {
+	seen := map[string]bool{}
+	for i, c := range file.Columns {
+		if c.KeyName == "" {
+			return fmt.Errorf("columns[%d]: key_name is required", i)
+		}
+		if seen["column:"+c.KeyName] {
+			return fmt.Errorf("columns[%d]: duplicate key_name %s", i, c.KeyName)
+		}
+		seen["column:"+c.KeyName] = true
+	}
+	for i, dc := range file.DerivedColumns {
+		if dc.Alias == "" || dc.Expression == "" {
+			return fmt.Errorf("derived_columns[%d]: alias and expression are required", i)
+		}
+		if seen["derived_column:"+dc.Alias] {
+			return fmt.Errorf("derived_columns[%d]: duplicate alias %s", i, dc.Alias)
+		}
+		seen["derived_column:"+dc.Alias] = true
+	}
+	return nil
+}
+
+func applyColumn(ctx context.Context, client *api.Client, dataset string, want api.Column, existing map[string]api.Column) applyResult {
+	res := applyResult{Kind: graphColumn, Name: want.KeyName}
+	cur, ok := existing[want.KeyName]
+	if !ok {
+		created, err := client.CreateColumn(ctx, dataset, &api.Column{
+			KeyName:     want.KeyName,
+			Type:        want.Type,
+			Description: want.Description,
+			Hidden:      want.Hidden,
+		})
+		if err == nil {
+			res.ID = created.ID
+		}
+		return finishApply(res, "created", err)
+	}
+
+	res.ID = cur.ID
+	merged := api.Column{KeyName: cur.KeyName, Type: cur.Type, Description: cur.Description, Hidden: cur.Hidden}
+	if want.Type != "" {
+		merged.Type = want.Type
+	}
+	if want.Description != "" {
+		merged.Description = want.Description
+	}
+	if want.Hidden != nil {
+		merged.Hidden = want.Hidden
+	}
+	if merged.Type == cur.Type && merged.Description == cur.Description && boolValue(merged.Hidden) == boolValue(cur.Hidden) {
+		res.Action = "unchanged"
+		return res
+	}
+	_, err := client.UpdateColumn(ctx, dataset, cur.ID, &merged)
+	return finishApply(res, "updated", err)
+}
+
+func applyDerivedColumn(ctx context.Context, client *api.Client, dataset string, want api.DerivedColumn, existing map[string]api.DerivedColumn, known func(string) bool) applyResult {
+	res := applyResult{Kind: graphDerivedColumn, Name: want.Alias}
+	cur, ok := existing[want.Alias]
+	if ok {
+		res.ID = cur.ID
+		// As for columns, an empty description keeps the live one.
+		if want.Description == "" {
+			want.Description = cur.Description
+		}
+		if cur.Expression == want.Expression && cur.Description == want.Description {
+			res.Action = "unchanged"
+			return res
+		}
+	}
+	if known != nil {
+		if problems := expr.Lint(want.Expression, known); len(problems) > 0 {
+			msgs := make([]string, len(problems))
+			for i, p := range problems {
+				msgs[i] = p.String()
+			}
+			res.Action = "failed"
+			res.Error = "lint: " + strings.Join(msgs, "; ")
+			return res
+		}
+	}
+
+	col := &api.DerivedColumn{Alias: want.Alias, Expression: want.Expression, Description: want.Description}
+	if !ok {
+		created, err := client.CreateDerivedColumn(ctx, dataset, col)
+		if err == nil {
+			res.ID = created.ID
+		}
+		return finishApply(res, "created", err)
+	}
+	_, err := client.UpdateDerivedColumn(ctx, dataset, cur.ID, col)
+	return finishApply(res, "updated", err)
+}
+
+func finishApply(res applyResult, action string, err error) applyResult {
+	if err != nil {
+		res.Action = "failed"
+		res.Error = err.Error()
+		return res
+	}
+	res.Action = action
+	return res
+}
+
+func boolValue(b *bool) bool {
+	return b != nil && *b
+}
+
+// derivedColumnWaves groups the indexes of derived columns so that every
+// derived column comes after those of the file it references. Derived
+// columns in a reference cycle end up in the last wave together.
+func derivedColumnWaves(columns []api.DerivedColumn) [][]int {
+	index := map[string]int{}
+	for i, dc := range columns {
+		index[dc.Alias] = i
+	}
+	deps := make([][]int, len(columns))
+	for i, dc := range columns {
+		n, err := expr.Parse(dc.Expression)
+		if err != nil {
+			continue
+		}
+		for _, ref := range expr.Columns(n) {
+			if j, ok := index[ref]; ok && j != i {
+				deps[i] = append(deps[i], j)
+			}
+		}
+	}
+
+	done := make([]bool, len(columns))
+	var waves [][]int
+	for remaining := len(columns); remaining > 0; {
+		var wave []int
+		for i := range columns {
+			if done[i] {
+				continue
+			}
+			ready := true
+			for _, j := range deps[i] {
+				ready = ready && done[j]
+			}
+			if ready {
+				wave = append(wave, i)
+			}
+		}
+		if len(wave) == 0 {
+			for i := range columns {
+				if !done[i] {
+					wave = append(wave, i)
+				}
+			}
+		}
+		for _, i := range wave {
+			done[i] = true
+		}
+		remaining -= len(wave)
+		waves = append(waves, wave)
+	}
+	return waves
+}
+
+func printApplyResults(results []applyResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "KIND\tNAME\tACTION\tID\tERROR")
+	for _, r := range results {
+		id := r.ID
+		if id == "" {
+			id = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", r.Kind, r.Name, r.Action, id, r.Error)
+	}
+	_ = w.Flush()
+}
@@ -12,7 +12,10 @@ func AuthCmd() *cli.Command {
 		Category: "Auth",
 		Usage:    "Show API key info and permissions",
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			auth, err := client.GetAuth(ctx)
 			if err != nil {
@@ -30,7 +33,10 @@ func AuthV2Cmd() *cli.Command {
 		Category: "Auth",
 		Usage:    "Show management API key info and permissions (v2)",
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			auth, err := client.GetAuthV2(ctx)
 			if err != nil {
@@ -0,0 +1,806 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/datafile"
+	"github.com/urfave/cli/v3"
+)
+
+// environmentDataset is the pseudo-dataset for environment-wide resources.
+const environmentDataset = "__all__"
+
+// backupManifest describes an export tree: what was exported, how many of
+// each resource, and the checksum of every data file.
+type backupManifest struct {
+	ExportedAt string         `json:"exported_at"`
+	Format     string         `json:"format"`
+	Counts     map[string]int `json:"counts"`
+	Files      []backupFile   `json:"files"`
+}
+
+type backupFile struct {
+	Path   string `json:"path"`
+	Kind   string `json:"kind"`
+	Count  int    `json:"count"`
+	SHA256 string `json:"sha256"`
+}
+
+// exportedQueryAnnotation is a query annotation together with the query it
+// names, so that the query can be recreated in another environment.
+type exportedQueryAnnotation struct {
+	api.QueryAnnotation
+	Query *api.Query `json:"query,omitempty"`
+}
+
+// exportedQuery is a query spec and the dataset it belongs to.
+type exportedQuery struct {
+	Dataset string    `json:"dataset"`
+	Query   api.Query `json:"query"`
+}
+
+// exportedBoard is a board together with its views and the specs of the
+// queries its panels reference, keyed by the original query ID.
+type exportedBoard struct {
+	api.Board
+	Views   []api.BoardView          `json:"views,omitempty"`
+	Queries map[string]exportedQuery `json:"queries,omitempty"`
+}
+
+// backupAction is one line of export or restore output.
+type backupAction struct {
+	Action  string `json:"action"`
+	Kind    string `json:"kind"`
+	Dataset string `json:"dataset,omitempty"`
+	Name    string `json:"name"`
+	ID      string `json:"id,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// Export file names within a dataset directory.
+const (
+	datasetFile          = "dataset"
+	columnsFile          = "columns"
+	derivedColumnsFile   = "derived_columns"
+	queryAnnotationsFile = "query_annotations"
+	markerSettingsFile   = "marker_settings"
+	slosFile             = "slos"
+	burnAlertsFile       = "burn_alerts"
+)
+
+func ExportAllCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "export-all",
+		Category: "Backup",
+		Usage:    "Export all environment configuration to a directory tree",
+		Description: `Walk every dataset and write its columns, derived columns, query
+annotations, marker settings, SLOs and burn alerts, plus all boards with
+their views, to a directory tree. A manifest with resource counts and
+SHA-256 checksums of every file is written alongside.
+
+Layout:
+
+  DIR/manifest.json
+  DIR/datasets/<slug>/{dataset,columns,derived_columns,query_annotations,
+                       marker_settings,slos,burn_alerts}.json
+  DIR/boards/<id>.json
+
+Environment-wide resources are exported under datasets/__all__.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "dir",
+				Usage:    "Directory to write the export to",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "format",
+				Usage: "File format: json or yaml",
+				Value: "json",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			dir := cmd.String("dir")
+			format := cmd.String("format")
+			if format != "json" && format != "yaml" {
+				return fmt.Errorf("invalid format %q: expected json or yaml", format)
+			}
+
+			manifest := &backupManifest{
+				ExportedAt: time.Now().UTC().Format(time.RFC3339),
+				Format:     format,
+				Counts:     map[string]int{},
+			}
+			write := func(rel, kind string, count int, v any) error {
+				rel = filepath.ToSlash(rel) + "." + format
+				sum, err := datafile.Write(filepath.Join(dir, rel), v)
+				if err != nil {
+					return fmt.Errorf("writing %s: %w", rel, err)
+				}
+				manifest.Files = append(manifest.Files, backupFile{Path: rel, Kind: kind, Count: count, SHA256: sum})
+				manifest.Counts[kind] += count
+				return nil
+			}
+
+			datasets, err := client.ListDatasets(ctx)
+			if err != nil {
+				return fmt.Errorf("listing datasets: %w", err)
+			}
+			sort.Slice(datasets, func(i, j int) bool { return datasets[i].Slug < datasets[j].Slug })
+
+			// queryDatasets remembers which dataset each annotated query belongs
+			// to, so board panels can be resolved without a dataset of their own.
+			queryDatasets := map[string]string{}
+
+			slugs := []string{environmentDataset}
+			for _, ds := range datasets {
+				if err := write(filepath.Join("datasets", ds.Slug, datasetFile), "datasets", 1, ds); err != nil {
+					return err
+				}
+				slugs = append(slugs, ds.Slug)
+			}
+
+			for _, slug := range slugs {
+				base := filepath.Join("datasets", slug)
+				env := slug == environmentDataset
+
+				if !env {
+					cols, err := client.ListColumns(ctx, slug)
+					if err != nil {
+						return fmt.Errorf("listing columns for %s: %w", slug, err)
+					}
+					if err := write(filepath.Join(base, columnsFile), "columns", len(cols), cols); err != nil {
+						return err
+					}
+				}
+
+				dcs, err := client.ListDerivedColumns(ctx, slug)
+				if err != nil {
+					if env {
+						warnSkipped("environment-wide resources", err)
+						continue
+					}
+					return fmt.Errorf("listing derived columns for %s: %w", slug, err)
+				}
+				if err := write(filepath.Join(base, derivedColumnsFile), "derived_columns", len(dcs), dcs); err != nil {
+					return err
+				}
+
+				annotations, err := client.ListQueryAnnotations(ctx, slug)
+				if err != nil {
+					return fmt.Errorf("listing query annotations for %s: %w", slug, err)
+				}
+				exported := make([]exportedQueryAnnotation, 0, len(annotations))
+				for _, a := range annotations {
+					ea := exportedQueryAnnotation{QueryAnnotation: a}
+					if q, err := client.GetQuery(ctx, slug, a.QueryID); err == nil {
+						ea.Query = q
+						queryDatasets[a.QueryID] = slug
+					}
+					exported = append(exported, ea)
+				}
+				if err := write(filepath.Join(base, queryAnnotationsFile), "query_annotations", len(exported), exported); err != nil {
+					return err
+				}
+
+				settings, err := client.ListMarkerSettings(ctx, slug)
+				if err != nil {
+					return fmt.Errorf("listing marker settings for %s: %w", slug, err)
+				}
+				if err := write(filepath.Join(base, markerSettingsFile), "marker_settings", len(settings), settings); err != nil {
+					return err
+				}
+
+				slos, err := client.ListSLOs(ctx, slug)
+				if err != nil {
+					return fmt.Errorf("listing SLOs for %s: %w", slug, err)
+				}
+				if err := write(filepath.Join(base, slosFile), "slos", len(slos), slos); err != nil {
+					return err
+				}
+
+				var alerts []api.BurnAlert
+				for _, slo := range slos {
+					as, err := client.ListBurnAlerts(ctx, slug, slo.ID)
+					if err != nil {
+						return fmt.Errorf("listing burn alerts for SLO %s: %w", slo.ID, err)
+					}
+					for _, a := range as {
+						if a.SLO == nil {
+							a.SLO = &api.BurnAlertSLO{ID: slo.ID}
+						}
+						alerts = append(alerts, a)
+					}
+				}
+				if err := write(filepath.Join(base, burnAlertsFile), "burn_alerts", len(alerts), alerts); err != nil {
+					return err
+				}
+			}
+
+			boards, err := client.ListBoards(ctx)
+			if err != nil {
+				return fmt.Errorf("listing boards: %w", err)
+			}
+			for _, b := range boards {
+				eb := exportedBoard{Board: b, Queries: map[string]exportedQuery{}}
+				if full, err := client.GetBoard(ctx, b.ID); err == nil {
+					eb.Board = *full
+				}
+				views, err := client.ListBoardViews(ctx, b.ID)
+				if err != nil {
+					return fmt.Errorf("listing views for board %s: %w", b.ID, err)
+				}
+				eb.Views = views
+				for _, p := range eb.Panels {
+					if p.QueryPanel == nil {
+						continue
+					}
+					qid := p.QueryPanel.QueryID
+					ds, ok := queryDatasets[qid]
+					if !ok {
+						ds = environmentDataset
+					}
+					q, err := client.GetQuery(ctx, ds, qid)
+					if err != nil {
+						warnSkipped(fmt.Sprintf("query %s on board %q", qid, b.Name), err)
+						continue
+					}
+					eb.Queries[qid] = exportedQuery{Dataset: ds, Query: *q}
+				}
+				if err := write(filepath.Join("boards", b.ID), "boards", 1, eb); err != nil {
+					return err
+				}
+			}
+
+			if _, err := datafile.Write(filepath.Join(dir, "manifest."+format), manifest); err != nil {
+				return fmt.Errorf("writing manifest: %w", err)
+			}
+			return printJSON(manifest)
+		},
+	}
+}
+
+func RestoreCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "restore",
+		Category: "Backup",
+		Usage:    "Recreate resources from an export-all directory tree",
+		Description: `Recreate datasets, columns, derived columns, query annotations, marker
+settings, SLOs, burn alerts and boards from a tree written by export-all.
+
+Resources are created in dependency order: datasets, columns and derived
+columns first, then SLOs (which reference derived columns), then burn
+alerts and boards (which reference SLOs and queries). Resources that
+already exist with the same key (column name, derived column alias,
+annotation name, marker type, SLO name, burn alert description, board
+name) are skipped. References to SLOs, queries and query annotations are
+remapped to the newly created IDs.
+
+Checksums in the manifest are verified before anything is created.
+Resources that fail to restore are reported with action "error" and the
+command fails.`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "dir",
+				Usage:    "Directory containing an export-all tree",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show what would be created without making changes",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			dir := cmd.String("dir")
+
+			manifest, err := readBackupManifest(dir)
+			if err != nil {
+				return err
+			}
+
+			r := &restorer{
+				client:      client,
+				dir:         dir,
+				dryRun:      dryRun(cmd),
+				sloIDs:      map[string]string{},
+				queryIDs:    map[string]string{},
+				annotations: map[string]string{},
+			}
+			if err := r.run(ctx, manifest); err != nil {
+				return err
+			}
+			if err := printJSON(r.actions); err != nil {
+				return err
+			}
+			failed := 0
+			for _, a := range r.actions {
+				if a.Action == "error" {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d resource(s) failed to restore", failed)
+			}
+			return nil
+		},
+	}
+}
+
+// readBackupManifest reads the manifest in dir and verifies every file's checksum.
+func readBackupManifest(dir string) (*backupManifest, error) {
+	var manifest backupManifest
+	path := filepath.Join(dir, "manifest.json")
+	if _, err := os.Stat(path); err != nil {
+		path = filepath.Join(dir, "manifest.yaml")
+	}
+	if err := datafile.Read(path, &manifest); err != nil {
+		return nil, fmt.Errorf("reading manifest: %w", err)
+	}
+
+	for _, f := range manifest.Files {
+		sum, err := datafile.Checksum(filepath.Join(dir, filepath.FromSlash(f.Path)))
+		if err != nil {
+			return nil, err
+		}
+		if sum != f.SHA256 {
+			return nil, fmt.Errorf("checksum mismatch for %s", f.Path)
+		}
+	}
+	return &manifest, nil
+}
+
+// restorer recreates resources from an export tree and records what it did.
+type restorer struct {
+	client  *api.Client
+	dir     string
+	dryRun  bool
+	actions []backupAction
+
+	// Maps from IDs in the export to IDs of the recreated resources.
+	sloIDs      map[string]string
+	queryIDs    map[string]string
+	annotations map[string]string
+}
+
+func (r *restorer) record(action, kind, dataset, name, id string, err error) {
+	a := backupAction{Action: action, Kind: kind, Dataset: dataset, Name: name, ID: id}
+	if err != nil {
+		a.Action = "error"
+		a.Error = err.Error()
+	}
+	r.actions = append(r.actions, a)
+}
+
+// filesOf returns the manifest files of a kind, grouped by dataset directory.
+func filesOf(m *backupManifest, kind string) []backupFile {
+	var out []backupFile
+	for _, f := range m.Files {
+		if f.Kind == kind {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// datasetOf returns the dataset slug for a file under datasets/<slug>/.
+func datasetOf(f backupFile) string {
+	return filepath.Base(filepath.Dir(filepath.FromSlash(f.Path)))
+}
+
+func (r *restorer) load(f backupFile, v any) error {
+	return datafile.Read(filepath.Join(r.dir, filepath.FromSlash(f.Path)), v)
+}
+
+func (r *restorer) run(ctx context.Context, m *backupManifest) error {
+	steps := []func(context.Context, *backupManifest) error{
+		r.restoreDatasets,
+		r.restoreColumns,
+		r.restoreDerivedColumns,
+		r.restoreQueryAnnotations,
+		r.restoreMarkerSettings,
+		r.restoreSLOs,
+		r.restoreBurnAlerts,
+		r.restoreBoards,
+	}
+	for _, step := range steps {
+		if err := step(ctx, m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *restorer) restoreDatasets(ctx context.Context, m *backupManifest) error {
+	for _, f := range filesOf(m, "datasets") {
+		var ds api.Dataset
+		if err := r.load(f, &ds); err != nil {
+			return err
+		}
+		if _, err := r.client.GetDataset(ctx, ds.Slug); err == nil {
+			r.record("skip", "dataset", "", ds.Slug, ds.Slug, nil)
+			continue
+		}
+		if r.dryRun {
+			r.record("create", "dataset", "", ds.Slug, "", nil)
+			continue
+		}
+		created, err := r.client.CreateDataset(ctx, &api.Dataset{
+			Name:            ds.Name,
+			Description:     ds.Description,
+			ExpandJSONDepth: ds.ExpandJSONDepth,
+		})
+		if err != nil {
+			r.record("create", "dataset", "", ds.Slug, "", err)
+			continue
+		}
+		r.record("create", "dataset", "", ds.Slug, created.Slug, nil)
+	}
+	return nil
+}
+
+func (r *restorer) restoreColumns(ctx context.Context, m *backupManifest) error {
+	for _, f := range filesOf(m, "columns") {
+		dataset := datasetOf(f)
+		var cols []api.Column
+		if err := r.load(f, &cols); err != nil {
+			return err
+		}
+		existing := map[string]bool{}
+		if live, err := r.client.ListColumns(ctx, dataset); err == nil {
+			for _, c := range live {
+				existing[c.KeyName] = true
+			}
+		}
+		for _, c := range cols {
+			if existing[c.KeyName] {
+				r.record("skip", "column", dataset, c.KeyName, "", nil)
+				continue
+			}
+			if r.dryRun {
+				r.record("create", "column", dataset, c.KeyName, "", nil)
+				continue
+			}
+			created, err := r.client.CreateColumn(ctx, dataset, &api.Column{
+				KeyName:     c.KeyName,
+				Type:        c.Type,
+				Description: c.Description,
+				Hidden:      c.Hidden,
+			})
+			if err != nil {
+				r.record("create", "column", dataset, c.KeyName, "", err)
+				continue
+			}
+			r.record("create", "column", dataset, c.KeyName, created.ID, nil)
+		}
+	}
+	return nil
+}
+
+func (r *restorer) restoreDerivedColumns(ctx context.Context, m *backupManifest) error {
+	for _, f := range filesOf(m, "derived_columns") {
+		dataset := datasetOf(f)
+		var dcs []api.DerivedColumn
+		if err := r.load(f, &dcs); err != nil {
+			return err
+		}
+		existing := map[string]bool{}
+		if live, err := r.client.ListDerivedColumns(ctx, dataset); err == nil {
+			for _, dc := range live {
+				existing[dc.Alias] = true
+			}
+		}
+		for _, dc := range dcs {
+			if existing[dc.Alias] {
+				r.record("skip", "derived_column", dataset, dc.Alias, "", nil)
+				continue
+			}
+			if r.dryRun {
+				r.record("create", "derived_column", dataset, dc.Alias, "", nil)
+				continue
+			}
+			created, err := r.client.CreateDerivedColumn(ctx, dataset, &api.DerivedColumn{
+				Alias:       dc.Alias,
+				Expression:  dc.Expression,
+				Description: dc.Description,
+			})
+			if err != nil {
+				r.record("create", "derived_column", dataset, dc.Alias, "", err)
+				continue
+			}
+			r.record("create", "derived_column", dataset, dc.Alias, created.ID, nil)
+		}
+	}
+	return nil
+}
+
+func (r *restorer) restoreQueryAnnotations(ctx context.Context, m *backupManifest) error {
+	for _, f := range filesOf(m, "query_annotations") {
+		dataset := datasetOf(f)
+		var annotations []exportedQueryAnnotation
+		if err := r.load(f, &annotations); err != nil {
+			return err
+		}
+		existing := map[string]string{}
+		if live, err := r.client.ListQueryAnnotations(ctx, dataset); err == nil {
+			for _, a := range live {
+				existing[a.Name] = a.ID
+			}
+		}
+		for _, a := range annotations {
+			if id, ok := existing[a.Name]; ok {
+				r.annotations[a.ID] = id
+				r.record("skip", "query_annotation", dataset, a.Name, id, nil)
+				continue
+			}
+			if a.Query == nil {
+				r.record("skip", "query_annotation", dataset, a.Name, "", fmt.Errorf("export has no query spec"))
+				continue
+			}
+			if r.dryRun {
+				r.record("create", "query_annotation", dataset, a.Name, "", nil)
+				continue
+			}
+			queryID, err := r.createQuery(ctx, dataset, a.QueryID, *a.Query)
+			if err != nil {
+				r.record("create", "query_annotation", dataset, a.Name, "", err)
+				continue
+			}
+			created, err := r.client.CreateQueryAnnotation(ctx, dataset, &api.QueryAnnotation{
+				Name:        a.Name,
+				Description: a.Description,
+				QueryID:     queryID,
+			})
+			if err != nil {
+				r.record("create", "query_annotation", dataset, a.Name, "", err)
+				continue
+			}
+			r.annotations[a.ID] = created.ID
+			r.record("create", "query_annotation", dataset, a.Name, created.ID, nil)
+		}
+	}
+	return nil
+}
+
+func (r *restorer) restoreMarkerSettings(ctx context.Context, m *backupManifest) error {
+	for _, f := range filesOf(m, "marker_settings") {
+		dataset := datasetOf(f)
+		var settings []api.MarkerSetting
+		if err := r.load(f, &settings); err != nil {
+			return err
+		}
+		existing := map[string]bool{}
+		if live, err := r.client.ListMarkerSettings(ctx, dataset); err == nil {
+			for _, s := range live {
+				existing[s.Type] = true
+			}
+		}
+		for _, s := range settings {
+			if existing[s.Type] {
+				r.record("skip", "marker_setting", dataset, s.Type, "", nil)
+				continue
+			}
+			if r.dryRun {
+				r.record("create", "marker_setting", dataset, s.Type, "", nil)
+				continue
+			}
+			created, err := r.client.CreateMarkerSetting(ctx, dataset, &api.MarkerSetting{Type: s.Type, Color: s.Color})
+			if err != nil {
+				r.record("create", "marker_setting", dataset, s.Type, "", err)
+				continue
+			}
+			r.record("create", "marker_setting", dataset, s.Type, created.ID, nil)
+		}
+	}
+	return nil
+}
+
+func (r *restorer) restoreSLOs(ctx context.Context, m *backupManifest) error {
+	for _, f := range filesOf(m, "slos") {
+		dataset := datasetOf(f)
+		var slos []api.SLO
+		if err := r.load(f, &slos); err != nil {
+			return err
+		}
+		existing := map[string]string{}
+		if live, err := r.client.ListSLOs(ctx, dataset); err == nil {
+			for _, s := range live {
+				existing[s.Name] = s.ID
+			}
+		}
+		for _, s := range slos {
+			if id, ok := existing[s.Name]; ok {
+				r.sloIDs[s.ID] = id
+				r.record("skip", "slo", dataset, s.Name, id, nil)
+				continue
+			}
+			if r.dryRun {
+				r.record("create", "slo", dataset, s.Name, "", nil)
+				continue
+			}
+			created, err := r.client.CreateSLO(ctx, dataset, &api.SLO{
+				Name:             s.Name,
+				Description:      s.Description,
+				SLI:              s.SLI,
+				TimePeriodDays:   s.TimePeriodDays,
+				TargetPerMillion: s.TargetPerMillion,
+				Tags:             s.Tags,
+				DatasetSlugs:     s.DatasetSlugs,
+			})
+			if err != nil {
+				r.record("create", "slo", dataset, s.Name, "", err)
+				continue
+			}
+			r.sloIDs[s.ID] = created.ID
+			r.record("create", "slo", dataset, s.Name, created.ID, nil)
+		}
+	}
+	return nil
+}
+
+func (r *restorer) restoreBurnAlerts(ctx context.Context, m *backupManifest) error {
+	for _, f := range filesOf(m, "burn_alerts") {
+		dataset := datasetOf(f)
+		var alerts []api.BurnAlert
+		if err := r.load(f, &alerts); err != nil {
+			return err
+		}
+		existing := map[string]map[string]bool{}
+		for _, a := range alerts {
+			if a.SLO == nil {
+				continue
+			}
+			sloID := mapID(r.sloIDs, a.SLO.ID)
+			if _, ok := existing[sloID]; ok || sloID == "" {
+				continue
+			}
+			existing[sloID] = map[string]bool{}
+			if live, err := r.client.ListBurnAlerts(ctx, dataset, sloID); err == nil {
+				for _, l := range live {
+					existing[sloID][l.Description] = true
+				}
+			}
+		}
+		for _, a := range alerts {
+			if a.SLO == nil {
+				continue
+			}
+			name := a.Description
+			if name == "" {
+				name = a.AlertType
+			}
+			sloID := mapID(r.sloIDs, a.SLO.ID)
+			if existing[sloID][a.Description] {
+				r.record("skip", "burn_alert", dataset, name, "", nil)
+				continue
+			}
+			if r.dryRun {
+				r.record("create", "burn_alert", dataset, name, "", nil)
+				continue
+			}
+			created, err := r.client.CreateBurnAlert(ctx, dataset, &api.BurnAlert{
+				Description:                           a.Description,
+				AlertType:                             a.AlertType,
+				ExhaustionMinutes:                     a.ExhaustionMinutes,
+				BudgetRateWindowMinutes:               a.BudgetRateWindowMinutes,
+				BudgetRateDecreaseThresholdPerMillion: a.BudgetRateDecreaseThresholdPerMillion,
+				SLO:                                   &api.BurnAlertSLO{ID: sloID},
+				Recipients:                            a.Recipients,
+			})
+			if err != nil {
+				r.record("create", "burn_alert", dataset, name, "", err)
+				continue
+			}
+			r.record("create", "burn_alert", dataset, name, created.ID, nil)
+		}
+	}
+	return nil
+}
+
+func (r *restorer) restoreBoards(ctx context.Context, m *backupManifest) error {
+	existing := map[string]bool{}
+	if live, err := r.client.ListBoards(ctx); err == nil {
+		for _, b := range live {
+			existing[b.Name] = true
+		}
+	}
+
+	for _, f := range filesOf(m, "boards") {
+		var eb exportedBoard
+		if err := r.load(f, &eb); err != nil {
+			return err
+		}
+		if existing[eb.Name] {
+			r.record("skip", "board", "", eb.Name, "", nil)
+			continue
+		}
+		if r.dryRun {
+			r.record("create", "board", "", eb.Name, "", nil)
+			continue
+		}
+
+		board := eb.Board
+		board.ID = ""
+		board.Links = nil
+		board.Panels = nil
+		var panelErr error
+		for _, p := range eb.Panels {
+			switch {
+			case p.QueryPanel != nil:
+				qp := *p.QueryPanel
+				if spec, ok := eb.Queries[qp.QueryID]; ok {
+					id, err := r.createQuery(ctx, spec.Dataset, qp.QueryID, spec.Query)
+					if err != nil {
+						panelErr = err
+						continue
+					}
+					qp.QueryID = id
+				}
+				qp.QueryAnnotationID = mapID(r.annotations, qp.QueryAnnotationID)
+				p.QueryPanel = &qp
+			case p.SLOPanel != nil:
+				p.SLOPanel = &api.SLOPanel{SLOID: mapID(r.sloIDs, p.SLOPanel.SLOID)}
+			}
+			board.Panels = append(board.Panels, p)
+		}
+		if panelErr != nil {
+			r.record("create", "board", "", eb.Name, "", panelErr)
+			continue
+		}
+
+		created, err := r.client.CreateBoard(ctx, &board)
+		if err != nil {
+			r.record("create", "board", "", eb.Name, "", err)
+			continue
+		}
+		r.record("create", "board", "", eb.Name, created.ID, nil)
+
+		for _, v := range eb.Views {
+			view, err := r.client.CreateBoardView(ctx, created.ID, &api.BoardView{Name: v.Name, Filters: v.Filters})
+			if err != nil {
+				r.record("create", "board_view", "", eb.Name+"/"+v.Name, "", err)
+				continue
+			}
+			r.record("create", "board_view", "", eb.Name+"/"+v.Name, view.ID, nil)
+		}
+	}
+	return nil
+}
+
+// createQuery recreates a query once per original ID and returns the new ID.
+func (r *restorer) createQuery(ctx context.Context, dataset, oldID string, q api.Query) (string, error) {
+	if id, ok := r.queryIDs[oldID]; ok {
+		return id, nil
+	}
+	q.ID = ""
+	created, err := r.client.CreateQuery(ctx, dataset, &q)
+	if err != nil {
+		return "", fmt.Errorf("recreating query %s: %w", oldID, err)
+	}
+	r.queryIDs[oldID] = created.ID
+	return created.ID, nil
+}
+
+// mapID returns the recreated ID for id, or id itself when it was not recreated.
+func mapID(ids map[string]string, id string) string {
+	if mapped, ok := ids[id]; ok {
+		return mapped
+	}
+	return id
+}
+
+func warnSkipped(what string, err error) {
+	fmt.Fprintf(os.Stderr, "⚠️  Skipping %s: %v\n", what, err)
+}
@@ -0,0 +1,236 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/snapshot"
+	"github.com/LarsEckart/hccli/timefmt"
+	"github.com/urfave/cli/v3"
+)
+
+func SnapshotBoardCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "snapshot-board",
+		Category: "Boards",
+		Usage:    "Write a self-contained HTML snapshot of a board over a time window",
+		Description: `Run every query panel of a board over the given window, fetch SLO
+details and markers, and write a single HTML file with inline SVG charts,
+result tables and rendered text panels. The file has no external
+dependencies and can be attached to a postmortem as-is.
+
+Example:
+
+  hccli snapshot-board --id abc123 \
+    --from "2024-02-11 18:00" --to "2024-02-11 19:30" \
+    --output incident-1234.html`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "id",
+				Usage:    "Board ID",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "from",
+				Usage:    `Start of the window (e.g. "2024-02-11 18:00", "2024-02-11T18:00:00Z")`,
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "to",
+				Usage:    `End of the window (e.g. "2024-02-11 18:45", "2024-02-11T18:45:00Z")`,
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "timezone",
+				Usage: `Timezone for parsing dates (e.g. "America/New_York", default UTC)`,
+			},
+			&cli.StringFlag{
+				Name:  "dataset",
+				Usage: "Dataset slug used to run panel queries and look up SLOs and markers",
+				Value: "__all__",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "Path of the HTML file to write (default board-<id>-<from>.html)",
+			},
+			&cli.IntFlag{
+				Name:  "poll-interval",
+				Usage: "Seconds between polling attempts for query results",
+				Value: 2,
+			},
+			&cli.IntFlag{
+				Name:  "query-timeout",
+				Usage: "Maximum seconds to wait for each query result",
+				Value: 60,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			dataset := cmd.String("dataset")
+
+			loc, err := loadLocation(cmd)
+			if err != nil {
+				return err
+			}
+			fromTS, err := timefmt.ParseTimestamp(cmd.String("from"), loc)
+			if err != nil {
+				return fmt.Errorf("invalid from time %q: %w", cmd.String("from"), err)
+			}
+			toTS, err := timefmt.ParseTimestamp(cmd.String("to"), loc)
+			if err != nil {
+				return fmt.Errorf("invalid to time %q: %w", cmd.String("to"), err)
+			}
+			if toTS <= fromTS {
+				return fmt.Errorf("--to must be after --from")
+			}
+
+			board, err := client.GetBoard(ctx, cmd.String("id"))
+			if err != nil {
+				return err
+			}
+
+			markers, err := client.ListMarkers(ctx, dataset)
+			if err != nil {
+				return fmt.Errorf("listing markers: %w", err)
+			}
+
+			snap := &snapshot.Snapshot{
+				Board:       board,
+				From:        time.Unix(fromTS, 0),
+				To:          time.Unix(toTS, 0),
+				GeneratedAt: time.Now(),
+				Markers:     markersInWindow(markers, fromTS, toTS),
+			}
+
+			pollInterval := time.Duration(cmd.Int("poll-interval")) * time.Second
+			timeout := time.Duration(cmd.Int("query-timeout")) * time.Second
+
+			for _, p := range sortedPanels(board.Panels) {
+				panel := snapshot.Panel{Type: p.Type}
+				switch {
+				case p.QueryPanel != nil:
+					panel.Type = "query"
+					panel.QueryStyle = p.QueryPanel.QueryStyle
+					panel.Title = "Query " + p.QueryPanel.QueryID
+					if id := p.QueryPanel.QueryAnnotationID; id != "" {
+						if a, err := client.GetQueryAnnotation(ctx, dataset, id); err == nil {
+							panel.Title = a.Name
+							panel.Description = a.Description
+						}
+					}
+					result, err := runQueryInWindow(ctx, client, dataset, p.QueryPanel.QueryID, fromTS, toTS, pollInterval, timeout)
+					if err != nil {
+						panel.Error = err.Error()
+					}
+					panel.Result = result
+				case p.SLOPanel != nil:
+					panel.Type = "slo"
+					panel.Title = "SLO " + p.SLOPanel.SLOID
+					slo, err := client.GetSLODetailed(ctx, dataset, p.SLOPanel.SLOID)
+					if err != nil {
+						panel.Error = err.Error()
+					} else {
+						panel.Title = slo.Name
+						panel.Description = slo.Description
+						panel.SLO = slo
+					}
+				case p.TextPanel != nil:
+					panel.Type = "text"
+					panel.Text = p.TextPanel.Content
+				default:
+					continue
+				}
+				if panel.Error != "" {
+					fmt.Fprintf(os.Stderr, "⚠️  %s: %s\n", panel.Title, panel.Error)
+				}
+				snap.Panels = append(snap.Panels, panel)
+			}
+
+			path := cmd.String("output")
+			if path == "" {
+				path = fmt.Sprintf("board-%s-%s.html", board.ID, snap.From.UTC().Format("20060102T150405Z"))
+			}
+			f, err := os.Create(path)
+			if err != nil {
+				return err
+			}
+			if err := snapshot.Render(f, snap); err != nil {
+				_ = f.Close()
+				return fmt.Errorf("rendering snapshot: %w", err)
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+
+			return printJSON(map[string]any{
+				"board_id": board.ID,
+				"path":     path,
+				"from":     fromTS,
+				"to":       toTS,
+				"panels":   len(snap.Panels),
+				"markers":  len(snap.Markers),
+			})
+		},
+	}
+}
+
+// runQueryInWindow copies an existing query with its time range replaced by
+// an absolute window and executes it.
+func runQueryInWindow(ctx context.Context, client *api.Client, dataset, queryID string, from, to int64, pollInterval, timeout time.Duration) (*api.QueryResult, error) {
+	q, err := client.GetQuery(ctx, dataset, queryID)
+	if err != nil {
+		return nil, fmt.Errorf("fetching query: %w", err)
+	}
+	q.ID = ""
+	q.TimeRange = 0
+	q.StartTime = int(from)
+	q.EndTime = int(to)
+
+	windowed, err := client.CreateQuery(ctx, dataset, q)
+	if err != nil {
+		return nil, fmt.Errorf("creating windowed query: %w", err)
+	}
+	return pollQueryResult(ctx, client, dataset, windowed.ID, pollInterval, timeout)
+}
+
+// markersInWindow returns markers that overlap the from..to window, ordered by start time.
+func markersInWindow(markers []api.Marker, from, to int64) []api.Marker {
+	var out []api.Marker
+	for _, m := range markers {
+		if m.StartTime == nil {
+			continue
+		}
+		end := *m.StartTime
+		if m.EndTime != nil {
+			end = *m.EndTime
+		}
+		if *m.StartTime <= to && end >= from {
+			out = append(out, m)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return *out[i].StartTime < *out[j].StartTime })
+	return out
+}
+
+// sortedPanels orders panels top-to-bottom, left-to-right by their layout position.
+func sortedPanels(panels []api.BoardPanel) []api.BoardPanel {
+	out := append([]api.BoardPanel(nil), panels...)
+	sort.SliceStable(out, func(i, j int) bool {
+		pi, pj := out[i].Position, out[j].Position
+		if pi == nil || pj == nil {
+			return pi != nil
+		}
+		if pi.YCoordinate != pj.YCoordinate {
+			return pi.YCoordinate < pj.YCoordinate
+		}
+		return pi.XCoordinate < pj.XCoordinate
+	})
+	return out
+}
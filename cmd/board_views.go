@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 
 	"github.com/LarsEckart/hccli/api"
 	"github.com/urfave/cli/v3"
@@ -20,7 +22,10 @@ func ListBoardViewsCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			views, err := client.ListBoardViews(ctx, cmd.String("board-id"))
 			if err != nil {
@@ -50,7 +55,10 @@ func GetBoardViewCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			view, err := client.GetBoardView(ctx, cmd.String("board-id"), cmd.String("view-id"))
 			if err != nil {
@@ -78,15 +86,21 @@ func CreateBoardViewCmd() *cli.Command {
 				Usage:    "View name",
 				Required: true,
 			},
+			&cli.StringSliceFlag{
+				Name:  "filter",
+				Usage: `Filter in "column op [value]" form; repeat for multiple filters (e.g. --filter "status = 200" --filter "name exists")`,
+			},
 			&cli.StringFlag{
-				Name:     "filter-column",
-				Usage:    "Filter column name",
-				Required: true,
+				Name:  "filters-json",
+				Usage: `JSON array of filters, e.g. '[{"column":"status","operation":"=","value":"200"}]'`,
 			},
 			&cli.StringFlag{
-				Name:     "filter-op",
-				Usage:    "Filter operation (e.g. =, !=, >, <, starts-with)",
-				Required: true,
+				Name:  "filter-column",
+				Usage: "Filter column name (single filter; prefer --filter)",
+			},
+			&cli.StringFlag{
+				Name:  "filter-op",
+				Usage: "Filter operation (e.g. =, !=, >, <, starts-with)",
 			},
 			&cli.StringFlag{
 				Name:  "filter-value",
@@ -94,19 +108,19 @@ func CreateBoardViewCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
-
-			filter := api.BoardViewFilter{
-				Column:    cmd.String("filter-column"),
-				Operation: cmd.String("filter-op"),
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
 			}
-			if v := cmd.String("filter-value"); v != "" {
-				filter.Value = v
+
+			filters, err := buildBoardViewFilters(cmd)
+			if err != nil {
+				return err
 			}
 
 			view := &api.BoardView{
 				Name:    cmd.String("name"),
-				Filters: []api.BoardViewFilter{filter},
+				Filters: filters,
 			}
 
 			created, err := client.CreateBoardView(ctx, cmd.String("board-id"), view)
@@ -124,6 +138,8 @@ func UpdateBoardViewCmd() *cli.Command {
 		Name:     "update-board-view",
 		Category: "Board Views",
 		Usage:    "Update a board view by ID",
+		Description: `Only the fields given as flags change; filter flags replace all current
+filters of the view.`,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:     "board-id",
@@ -136,42 +152,59 @@ func UpdateBoardViewCmd() *cli.Command {
 				Required: true,
 			},
 			&cli.StringFlag{
-				Name:     "name",
-				Usage:    "View name",
-				Required: true,
+				Name:  "name",
+				Usage: "View name",
+			},
+			&cli.StringSliceFlag{
+				Name:  "filter",
+				Usage: `Filter in "column op [value]" form; repeat for multiple filters (e.g. --filter "status = 200" --filter "name exists")`,
 			},
 			&cli.StringFlag{
-				Name:     "filter-column",
-				Usage:    "Filter column name",
-				Required: true,
+				Name:  "filters-json",
+				Usage: `JSON array of filters, e.g. '[{"column":"status","operation":"=","value":"200"}]'`,
 			},
 			&cli.StringFlag{
-				Name:     "filter-op",
-				Usage:    "Filter operation (e.g. =, !=, >, <, starts-with)",
-				Required: true,
+				Name:  "filter-column",
+				Usage: "Filter column name (single filter; prefer --filter)",
+			},
+			&cli.StringFlag{
+				Name:  "filter-op",
+				Usage: "Filter operation (e.g. =, !=, >, <, starts-with)",
 			},
 			&cli.StringFlag{
 				Name:  "filter-value",
 				Usage: "Filter value",
 			},
+			ShowDiffFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
-
-			filter := api.BoardViewFilter{
-				Column:    cmd.String("filter-column"),
-				Operation: cmd.String("filter-op"),
+			if err := requireAnySet(cmd, "name", "filter", "filters-json", "filter-column"); err != nil {
+				return err
 			}
-			if v := cmd.String("filter-value"); v != "" {
-				filter.Value = v
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
 			}
 
-			view := &api.BoardView{
-				Name:    cmd.String("name"),
-				Filters: []api.BoardViewFilter{filter},
+			cur, err := client.GetBoardView(ctx, cmd.String("board-id"), cmd.String("view-id"))
+			if err != nil {
+				return err
+			}
+			current := api.BoardView{Name: cur.Name, Filters: cur.Filters}
+			view := current
+			if cmd.IsSet("name") {
+				view.Name = cmd.String("name")
+			}
+			if cmd.IsSet("filter") || cmd.IsSet("filters-json") || cmd.IsSet("filter-column") {
+				if view.Filters, err = buildBoardViewFilters(cmd); err != nil {
+					return err
+				}
+			}
+			if err := showUpdateDiff(cmd, current, view); err != nil {
+				return err
 			}
 
-			updated, err := client.UpdateBoardView(ctx, cmd.String("board-id"), cmd.String("view-id"), view)
+			updated, err := client.UpdateBoardView(ctx, cmd.String("board-id"), cmd.String("view-id"), &view)
 			if err != nil {
 				return err
 			}
@@ -197,10 +230,113 @@ func DeleteBoardViewCmd() *cli.Command {
 				Usage:    "View ID",
 				Required: true,
 			},
+			YesFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			view, err := client.GetBoardView(ctx, cmd.String("board-id"), cmd.String("view-id"))
+			if err != nil {
+				return err
+			}
+			if err := confirmDelete(cmd, "board view", view.Name, view); err != nil {
+				return err
+			}
 			return client.DeleteBoardView(ctx, cmd.String("board-id"), cmd.String("view-id"))
 		},
 	}
 }
+
+func CopyBoardViewsCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "copy-board-views",
+		Category: "Board Views",
+		Usage:    "Copy all views from one board to another",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "from-board-id",
+				Usage:    "Board ID to copy views from",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "to-board-id",
+				Usage:    "Board ID to copy views to",
+				Required: true,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			views, err := client.ListBoardViews(ctx, cmd.String("from-board-id"))
+			if err != nil {
+				return err
+			}
+
+			created := make([]*api.BoardView, 0, len(views))
+			for _, v := range views {
+				view := &api.BoardView{
+					Name:    v.Name,
+					Filters: v.Filters,
+				}
+				c, err := client.CreateBoardView(ctx, cmd.String("to-board-id"), view)
+				if err != nil {
+					return fmt.Errorf("copying view %q: %w", v.Name, err)
+				}
+				created = append(created, c)
+			}
+
+			return printJSON(created)
+		},
+	}
+}
+
+// buildBoardViewFilters collects view filters from --filter, --filters-json
+// and the single --filter-column/--filter-op/--filter-value flags.
+func buildBoardViewFilters(cmd *cli.Command) ([]api.BoardViewFilter, error) {
+	var filters []api.BoardViewFilter
+
+	for _, raw := range cmd.StringSlice("filter") {
+		f, err := parseFilter(raw)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, api.BoardViewFilter{
+			Column:    f.Column,
+			Operation: f.Op,
+			Value:     f.Value,
+		})
+	}
+
+	if fj := cmd.String("filters-json"); fj != "" {
+		var parsed []api.BoardViewFilter
+		if err := json.Unmarshal([]byte(fj), &parsed); err != nil {
+			return nil, fmt.Errorf("parsing filters-json: %w", err)
+		}
+		filters = append(filters, parsed...)
+	}
+
+	if col := cmd.String("filter-column"); col != "" {
+		op := cmd.String("filter-op")
+		if op == "" {
+			return nil, fmt.Errorf("--filter-column requires --filter-op")
+		}
+		filter := api.BoardViewFilter{
+			Column:    col,
+			Operation: op,
+		}
+		if v := cmd.String("filter-value"); v != "" {
+			filter.Value = v
+		}
+		filters = append(filters, filter)
+	}
+
+	if len(filters) == 0 {
+		return nil, fmt.Errorf("at least one filter is required (use --filter, --filters-json or --filter-column/--filter-op)")
+	}
+	return filters, nil
+}
@@ -15,7 +15,10 @@ func ListBoardsCmd() *cli.Command {
 		Category: "Boards",
 		Usage:    "List all boards",
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 			boards, err := client.ListBoards(ctx)
 			if err != nil {
 				return err
@@ -38,7 +41,10 @@ func GetBoardCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 			board, err := client.GetBoard(ctx, cmd.String("id"))
 			if err != nil {
 				return err
@@ -65,7 +71,10 @@ func CreateBoardCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 			board := &api.Board{
 				Name:        cmd.String("name"),
 				Description: cmd.String("description"),
@@ -85,14 +94,15 @@ func UpdateBoardCmd() *cli.Command {
 		Name:     "update-board",
 		Category: "Boards",
 		Usage:    "Update a board by ID",
-		Description: `Update a board's name, description, and panels.
+		Description: `Update a board's name, description, and panels. Only the fields given
+as flags change; the others keep their current value.
 
 To replace the full set of panels, use --panels-json with a JSON array
 from get-board output. This enables adding or removing individual panels:
 
   # Get current panels, remove index 1, and update
   PANELS=$(hccli get-board --id ID | jq 'del(.panels[1]) | .panels')
-  hccli update-board --id ID --name "my board" --panels-json "$PANELS"`,
+  hccli update-board --id ID --panels-json "$PANELS"`,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:     "id",
@@ -100,9 +110,8 @@ from get-board output. This enables adding or removing individual panels:
 				Required: true,
 			},
 			&cli.StringFlag{
-				Name:     "name",
-				Usage:    "Board name",
-				Required: true,
+				Name:  "name",
+				Usage: "Board name",
 			},
 			&cli.StringFlag{
 				Name:  "description",
@@ -110,7 +119,7 @@ from get-board output. This enables adding or removing individual panels:
 			},
 			&cli.StringFlag{
 				Name:  "query-id",
-				Usage: "Query ID for a panel",
+				Usage: "Query ID of a single panel that replaces the current panels",
 			},
 			&cli.StringFlag{
 				Name:  "query-annotation-id",
@@ -125,13 +134,39 @@ from get-board output. This enables adding or removing individual panels:
 				Name:  "panels-json",
 				Usage: "Full JSON array of board panels; use get-board output to build it (overrides --query-id)",
 			},
+			ShowDiffFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
-			board := &api.Board{
-				Name:        cmd.String("name"),
-				Description: cmd.String("description"),
-				Type:        "flexible",
+			if err := requireAnySet(cmd, "name", "description", "query-id", "panels-json"); err != nil {
+				return err
+			}
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			cur, err := client.GetBoard(ctx, cmd.String("id"))
+			if err != nil {
+				return err
+			}
+			current := api.Board{
+				Name:             cur.Name,
+				Description:      cur.Description,
+				Type:             cur.Type,
+				Panels:           cur.Panels,
+				Tags:             cur.Tags,
+				PresetFilters:    cur.PresetFilters,
+				LayoutGeneration: cur.LayoutGeneration,
+			}
+			if current.Type == "" {
+				current.Type = "flexible"
+			}
+			board := current
+			if cmd.IsSet("name") {
+				board.Name = cmd.String("name")
+			}
+			if cmd.IsSet("description") {
+				board.Description = cmd.String("description")
 			}
 
 			if pj := cmd.String("panels-json"); pj != "" {
@@ -156,8 +191,11 @@ from get-board output. This enables adding or removing individual panels:
 					},
 				}
 			}
+			if err := showUpdateDiff(cmd, current, board); err != nil {
+				return err
+			}
 
-			updated, err := client.UpdateBoard(ctx, cmd.String("id"), board)
+			updated, err := client.UpdateBoard(ctx, cmd.String("id"), &board)
 			if err != nil {
 				return err
 			}
@@ -177,9 +215,20 @@ func DeleteBoardCmd() *cli.Command {
 				Usage:    "Board ID",
 				Required: true,
 			},
+			YesFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			board, err := client.GetBoard(ctx, cmd.String("id"))
+			if err != nil {
+				return err
+			}
+			if err := confirmDelete(cmd, "board", board.Name, board); err != nil {
+				return err
+			}
 			return client.DeleteBoard(ctx, cmd.String("id"))
 		},
 	}
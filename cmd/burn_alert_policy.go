@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/budget"
+	"github.com/LarsEckart/hccli/datafile"
+	"github.com/urfave/cli/v3"
+)
+
+// burnAlertPolicyFile is a custom policy read from --policy-file, with
+// optional recipients per severity.
+type burnAlertPolicyFile struct {
+	budget.Policy
+	Recipients map[string][]api.NotificationRecipient `json:"recipients,omitempty"`
+}
+
+// policyResult is the outcome for one alert of a policy.
+type policyResult struct {
+	Action string `json:"action"`
+	ID     string `json:"id,omitempty"`
+	budget.Threshold
+	Recipients int    `json:"recipients"`
+	Error      string `json:"error,omitempty"`
+}
+
+func CreateBurnAlertPolicyCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "create-burn-alert-policy",
+		Category: "Burn Alerts",
+		Usage:    "Create a set of multi-window, multi-burn-rate burn alerts for an SLO",
+		Description: `Create the burn alerts of a policy for an SLO, with thresholds derived
+from the SLO's target and time period.
+
+The built-in "standard" policy follows the SRE workbook:
+
+  page    budget_rate      14.4x burn over 1 hour   (2% of a 30-day budget)
+  page    budget_rate      6x burn over 6 hours     (5% of a 30-day budget)
+  ticket  budget_rate      1x burn over 3 days      (10% of a 30-day budget)
+  page    exhaustion_time  budget exhausted within 4 hours
+  ticket  exhaustion_time  budget exhausted within 1 day
+
+Alerts are matched to existing burn alerts of the SLO by description, so
+running the command again updates changed alerts and leaves the rest
+alone.
+
+Custom policies are YAML or JSON files:
+
+  name: checkout
+  alerts:
+    - description: "checkout: fast burn"
+      severity: page
+      alert_type: budget_rate
+      window_minutes: 60
+      burn_rate: 14.4          # or decrease_per_million: 20000
+    - description: "checkout: exhausted within a day"
+      severity: ticket
+      alert_type: exhaustion_time
+      exhaustion_minutes: 1440 # or burn_rate: 30
+  recipients:
+    page: [{id: pagerduty-recipient-id}]
+    ticket: [{type: email, target: team@example.com}]
+
+Examples:
+
+  hccli create-burn-alert-policy --dataset api --slo-id abc123 --policy standard \
+    --recipients-json '{"page":[{"id":"pd1"}],"ticket":[{"type":"email","target":"team@example.com"}]}'
+  hccli create-burn-alert-policy --dataset api --slo-id abc123 --policy-file checkout.yaml`,
+		Flags: []cli.Flag{
+			DatasetFlag(),
+			&cli.StringFlag{
+				Name:     "slo-id",
+				Usage:    "SLO ID to create the burn alerts for",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "policy",
+				Usage: "Name of a built-in policy",
+				Value: "standard",
+			},
+			&cli.StringFlag{
+				Name:  "policy-file",
+				Usage: "YAML or JSON file with a custom policy (overrides --policy)",
+			},
+			&cli.StringFlag{
+				Name:  "recipients-json",
+				Usage: `JSON object of recipients per severity, e.g. '{"page":[{"id":"abc123"}]}'`,
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			dataset := cmd.String("dataset")
+
+			policy, recipients, err := loadBurnAlertPolicy(cmd)
+			if err != nil {
+				return err
+			}
+
+			slo, err := client.GetSLO(ctx, dataset, cmd.String("slo-id"))
+			if err != nil {
+				return err
+			}
+			thresholds, err := policy.Resolve(budget.SLO{TargetPerMillion: slo.TargetPerMillion, TimePeriodDays: slo.TimePeriodDays})
+			if err != nil {
+				return fmt.Errorf("policy %q: %w", policy.Name, err)
+			}
+			warnMissingRecipients(thresholds, recipients)
+
+			existing, err := client.ListBurnAlerts(ctx, dataset, slo.ID)
+			if err != nil {
+				return fmt.Errorf("listing burn alerts: %w", err)
+			}
+			byDescription := map[string]api.BurnAlert{}
+			for _, ba := range existing {
+				byDescription[ba.Description] = ba
+			}
+
+			results := make([]policyResult, 0, len(thresholds))
+			var failed int
+			for _, t := range thresholds {
+				desired := burnAlertForThreshold(t, recipients[t.Severity])
+				res := policyResult{Threshold: t, Recipients: len(desired.Recipients)}
+
+				live, ok := byDescription[t.Description]
+				switch {
+				case !ok:
+					desired.SLO = &api.BurnAlertSLO{ID: slo.ID}
+					created, err := client.CreateBurnAlert(ctx, dataset, desired)
+					res.Action = "created"
+					if err == nil {
+						res.ID = created.ID
+					} else {
+						res.Error = err.Error()
+					}
+				case burnAlertMatches(desired, &live):
+					res.Action = "unchanged"
+					res.ID = live.ID
+				default:
+					res.Action = "updated"
+					res.ID = live.ID
+					if _, err := client.UpdateBurnAlert(ctx, dataset, live.ID, desired); err != nil {
+						res.Error = err.Error()
+					}
+				}
+				if res.Error != "" {
+					failed++
+				}
+				results = append(results, res)
+			}
+
+			if err := printJSON(results); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d burn alerts failed", failed, len(results))
+			}
+			return nil
+		},
+	}
+}
+
+// loadBurnAlertPolicy returns the policy selected by --policy or
+// --policy-file and the recipients per severity, with --recipients-json
+// taking precedence over recipients from the file.
+func loadBurnAlertPolicy(cmd *cli.Command) (budget.Policy, map[string][]api.NotificationRecipient, error) {
+	var file burnAlertPolicyFile
+	if path := cmd.String("policy-file"); path != "" {
+		if err := datafile.Read(path, &file); err != nil {
+			return budget.Policy{}, nil, fmt.Errorf("reading policy file: %w", err)
+		}
+		if file.Name == "" {
+			file.Name = path
+		}
+	} else {
+		p, ok := budget.Policies[cmd.String("policy")]
+		if !ok {
+			var names []string
+			for name := range budget.Policies {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return budget.Policy{}, nil, fmt.Errorf("unknown policy %q (available: %s)", cmd.String("policy"), strings.Join(names, ", "))
+		}
+		file.Policy = p
+	}
+
+	recipients := file.Recipients
+	if recipients == nil {
+		recipients = map[string][]api.NotificationRecipient{}
+	}
+	if rj := cmd.String("recipients-json"); rj != "" {
+		var flagRecipients map[string][]api.NotificationRecipient
+		if err := json.Unmarshal([]byte(rj), &flagRecipients); err != nil {
+			return budget.Policy{}, nil, fmt.Errorf("parsing recipients-json: %w", err)
+		}
+		for severity, r := range flagRecipients {
+			recipients[severity] = r
+		}
+	}
+	return file.Policy, recipients, nil
+}
+
+func warnMissingRecipients(thresholds []budget.Threshold, recipients map[string][]api.NotificationRecipient) {
+	warned := map[string]bool{}
+	for _, t := range thresholds {
+		if len(recipients[t.Severity]) == 0 && !warned[t.Severity] {
+			warned[t.Severity] = true
+			fmt.Fprintf(os.Stderr, "⚠️  No recipients for severity %q; its burn alerts will not notify anyone\n", t.Severity)
+		}
+	}
+}
+
+func burnAlertForThreshold(t budget.Threshold, recipients []api.NotificationRecipient) *api.BurnAlert {
+	ba := &api.BurnAlert{
+		Description: t.Description,
+		AlertType:   t.AlertType,
+		Recipients:  recipients,
+	}
+	switch t.AlertType {
+	case budget.ExhaustionTime:
+		ba.ExhaustionMinutes = &t.ExhaustionMinutes
+	case budget.BudgetRate:
+		ba.BudgetRateWindowMinutes = &t.WindowMinutes
+		ba.BudgetRateDecreaseThresholdPerMillion = &t.DecreasePerMillion
+	}
+	return ba
+}
+
+// burnAlertMatches reports whether live already has the type, thresholds and
+// recipients of desired.
+func burnAlertMatches(desired, live *api.BurnAlert) bool {
+	liveType := live.AlertType
+	if liveType == "" {
+		liveType = budget.ExhaustionTime
+	}
+	if desired.AlertType != liveType ||
+		!equalIntPtr(desired.ExhaustionMinutes, live.ExhaustionMinutes) ||
+		!equalIntPtr(desired.BudgetRateWindowMinutes, live.BudgetRateWindowMinutes) ||
+		!equalIntPtr(desired.BudgetRateDecreaseThresholdPerMillion, live.BudgetRateDecreaseThresholdPerMillion) {
+		return false
+	}
+	if len(desired.Recipients) != len(live.Recipients) {
+		return false
+	}
+	for _, want := range desired.Recipients {
+		found := false
+		for _, have := range live.Recipients {
+			if (want.ID != "" && want.ID == have.ID) ||
+				(want.ID == "" && want.Type == have.Type && want.Target == have.Target) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func equalIntPtr(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
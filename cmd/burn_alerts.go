@@ -9,26 +9,132 @@ import (
 	"github.com/urfave/cli/v3"
 )
 
+// sloBurnAlert is a burn alert annotated with the name of its SLO.
+type sloBurnAlert struct {
+	SLOName string `json:"slo_name"`
+	api.BurnAlert
+}
+
+// burnAlertFinding is a coverage gap reported by --missing or --no-recipients.
+type burnAlertFinding struct {
+	Issue       string `json:"issue"`
+	SLOID       string `json:"slo_id"`
+	SLOName     string `json:"slo_name"`
+	BurnAlertID string `json:"burn_alert_id,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
 func ListBurnAlertsCmd() *cli.Command {
 	return &cli.Command{
 		Name:     "burn-alerts",
 		Category: "Burn Alerts",
-		Usage:    "List all burn alerts for an SLO",
+		Usage:    "List burn alerts for one SLO or all SLOs in a dataset",
+		Description: `List the burn alerts of an SLO, or of every SLO in the dataset when
+--slo-id is omitted. Alerts listed across SLOs carry the SLO's name in
+slo_name.
+
+--missing and --no-recipients turn the listing into a coverage audit that
+prints one finding per SLO without burn alerts or per burn alert without
+recipients, and exits with code 2 when there are any.`,
 		Flags: []cli.Flag{
 			DatasetFlag(),
 			&cli.StringFlag{
-				Name:     "slo-id",
-				Usage:    "SLO ID to list burn alerts for",
-				Required: true,
+				Name:  "slo-id",
+				Usage: "SLO ID to list burn alerts for (default: all SLOs in the dataset)",
+			},
+			&cli.BoolFlag{
+				Name:  "missing",
+				Usage: "Report SLOs that have no burn alerts",
+			},
+			&cli.BoolFlag{
+				Name:  "no-recipients",
+				Usage: "Report burn alerts without recipients",
+			},
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Usage: "Maximum number of SLOs fetched at once",
+				Value: 8,
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
-			alerts, err := client.ListBurnAlerts(ctx, cmd.String("dataset"), cmd.String("slo-id"))
+			client, err := newClient(cmd)
 			if err != nil {
 				return err
 			}
-			return printJSON(alerts)
+			dataset := cmd.String("dataset")
+			audit := cmd.Bool("missing") || cmd.Bool("no-recipients")
+
+			if id := cmd.String("slo-id"); id != "" && !audit {
+				alerts, err := client.ListBurnAlerts(ctx, dataset, id)
+				if err != nil {
+					return err
+				}
+				return printJSON(alerts)
+			}
+
+			var slos []api.SLO
+			if id := cmd.String("slo-id"); id != "" {
+				slo, err := client.GetSLO(ctx, dataset, id)
+				if err != nil {
+					return err
+				}
+				slos = []api.SLO{*slo}
+			} else {
+				var err error
+				slos, err = client.ListSLOs(ctx, dataset)
+				if err != nil {
+					return fmt.Errorf("listing SLOs: %w", err)
+				}
+			}
+
+			alerts := make([][]api.BurnAlert, len(slos))
+			errs := make([]error, len(slos))
+			parallel(len(slos), int(cmd.Int("concurrency")), func(i int) {
+				alerts[i], errs[i] = client.ListBurnAlerts(ctx, dataset, slos[i].ID)
+			})
+			for i, err := range errs {
+				if err != nil {
+					return fmt.Errorf("listing burn alerts for SLO %s: %w", slos[i].Name, err)
+				}
+			}
+
+			if !audit {
+				out := []sloBurnAlert{}
+				for i, slo := range slos {
+					for _, ba := range alerts[i] {
+						out = append(out, sloBurnAlert{SLOName: slo.Name, BurnAlert: ba})
+					}
+				}
+				return printJSON(out)
+			}
+
+			findings := []burnAlertFinding{}
+			for i, slo := range slos {
+				if cmd.Bool("missing") && len(alerts[i]) == 0 {
+					findings = append(findings, burnAlertFinding{Issue: "no burn alerts", SLOID: slo.ID, SLOName: slo.Name})
+				}
+				if !cmd.Bool("no-recipients") {
+					continue
+				}
+				for _, ba := range alerts[i] {
+					if len(ba.Recipients) == 0 {
+						findings = append(findings, burnAlertFinding{
+							Issue:       "no recipients",
+							SLOID:       slo.ID,
+							SLOName:     slo.Name,
+							BurnAlertID: ba.ID,
+							Description: ba.Description,
+						})
+					}
+				}
+			}
+			if err := printJSON(findings); err != nil {
+				return err
+			}
+			if len(findings) > 0 {
+				return cli.Exit(fmt.Sprintf("%d burn alert coverage finding(s)", len(findings)), exitCodeFindings)
+			}
+			return nil
 		},
 	}
 }
@@ -43,7 +149,10 @@ func GetBurnAlertCmd() *cli.Command {
 			IDFlag("id", "Burn Alert ID"),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 			alert, err := client.GetBurnAlert(ctx, cmd.String("dataset"), cmd.String("id"))
 			if err != nil {
 				return err
@@ -114,7 +223,10 @@ Examples:
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			ba, err := buildBurnAlert(cmd)
 			if err != nil {
@@ -136,13 +248,14 @@ func UpdateBurnAlertCmd() *cli.Command {
 		Name:     "update-burn-alert",
 		Category: "Burn Alerts",
 		Usage:    "Update a burn alert by ID",
+		Description: `Only the fields given as flags change; the others keep their current
+value. Changing --alert-type drops the thresholds of the old type.`,
 		Flags: []cli.Flag{
 			DatasetFlag(),
 			IDFlag("id", "Burn Alert ID"),
 			&cli.StringFlag{
-				Name:     "alert-type",
-				Usage:    "Alert type: exhaustion_time or budget_rate",
-				Required: true,
+				Name:  "alert-type",
+				Usage: "Alert type: exhaustion_time or budget_rate",
 			},
 			&cli.StringFlag{
 				Name:  "description",
@@ -161,20 +274,66 @@ func UpdateBurnAlertCmd() *cli.Command {
 				Usage: "Budget decrease threshold per million (for budget_rate, 1-1000000; 10000 = 1%)",
 			},
 			&cli.StringFlag{
-				Name:     "recipients-json",
-				Usage:    `JSON array of recipients, e.g. '[{"id":"abc123"}]' or '[{"type":"email","target":"a@b.com"}]'`,
-				Required: true,
+				Name:  "recipients-json",
+				Usage: `JSON array of recipients, e.g. '[{"id":"abc123"}]' or '[{"type":"email","target":"a@b.com"}]'`,
 			},
+			ShowDiffFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			if err := requireAnySet(cmd, "alert-type", "description", "exhaustion-minutes", "budget-rate-window-minutes", "budget-rate-decrease-per-million", "recipients-json"); err != nil {
+				return err
+			}
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
-			ba, err := buildBurnAlert(cmd)
+			cur, err := client.GetBurnAlert(ctx, cmd.String("dataset"), cmd.String("id"))
 			if err != nil {
 				return err
 			}
+			current := api.BurnAlert{
+				AlertType:                             cur.AlertType,
+				Description:                           cur.Description,
+				ExhaustionMinutes:                     cur.ExhaustionMinutes,
+				BudgetRateWindowMinutes:               cur.BudgetRateWindowMinutes,
+				BudgetRateDecreaseThresholdPerMillion: cur.BudgetRateDecreaseThresholdPerMillion,
+				Recipients:                            cur.Recipients,
+			}
+			ba := current
+			if cmd.IsSet("alert-type") && cmd.String("alert-type") != ba.AlertType {
+				ba.AlertType = cmd.String("alert-type")
+				ba.ExhaustionMinutes = nil
+				ba.BudgetRateWindowMinutes = nil
+				ba.BudgetRateDecreaseThresholdPerMillion = nil
+			}
+			if cmd.IsSet("description") {
+				ba.Description = cmd.String("description")
+			}
+			if cmd.IsSet("exhaustion-minutes") {
+				v := int(cmd.Int("exhaustion-minutes"))
+				ba.ExhaustionMinutes = &v
+			}
+			if cmd.IsSet("budget-rate-window-minutes") {
+				v := int(cmd.Int("budget-rate-window-minutes"))
+				ba.BudgetRateWindowMinutes = &v
+			}
+			if cmd.IsSet("budget-rate-decrease-per-million") {
+				v := int(cmd.Int("budget-rate-decrease-per-million"))
+				ba.BudgetRateDecreaseThresholdPerMillion = &v
+			}
+			if cmd.IsSet("recipients-json") {
+				var recipients []api.NotificationRecipient
+				if err := json.Unmarshal([]byte(cmd.String("recipients-json")), &recipients); err != nil {
+					return fmt.Errorf("parsing recipients-json: %w", err)
+				}
+				ba.Recipients = recipients
+			}
+			if err := showUpdateDiff(cmd, current, ba); err != nil {
+				return err
+			}
 
-			updated, err := client.UpdateBurnAlert(ctx, cmd.String("dataset"), cmd.String("id"), ba)
+			updated, err := client.UpdateBurnAlert(ctx, cmd.String("dataset"), cmd.String("id"), &ba)
 			if err != nil {
 				return err
 			}
@@ -191,9 +350,20 @@ func DeleteBurnAlertCmd() *cli.Command {
 		Flags: []cli.Flag{
 			DatasetFlag(),
 			IDFlag("id", "Burn Alert ID"),
+			YesFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			alert, err := client.GetBurnAlert(ctx, cmd.String("dataset"), cmd.String("id"))
+			if err != nil {
+				return err
+			}
+			if err := confirmDelete(cmd, "burn alert", alert.ID, alert); err != nil {
+				return err
+			}
 			return client.DeleteBurnAlert(ctx, cmd.String("dataset"), cmd.String("id"))
 		},
 	}
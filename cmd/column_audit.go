@@ -0,0 +1,360 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/timefmt"
+	"github.com/urfave/cli/v3"
+)
+
+// Column statuses in a column audit.
+const (
+	columnActive       = "active"
+	columnStale        = "stale"
+	columnNeverWritten = "never_written"
+	columnUnknown      = "unknown"
+)
+
+type columnAuditEntry struct {
+	KeyName     string   `json:"key_name"`
+	ID          string   `json:"id"`
+	Status      string   `json:"status"`
+	LastWritten string   `json:"last_written,omitempty"`
+	Hidden      bool     `json:"hidden"`
+	References  []string `json:"references"`
+}
+
+type columnAuditSummary struct {
+	Active       int `json:"active"`
+	Stale        int `json:"stale"`
+	NeverWritten int `json:"never_written"`
+	Unknown      int `json:"unknown"`
+	Referenced   int `json:"referenced"`
+	Unreferenced int `json:"unreferenced"`
+	Hidden       int `json:"hidden"`
+}
+
+type columnAuditReport struct {
+	Dataset     string             `json:"dataset"`
+	StaleAfter  string             `json:"stale_after"`
+	Summary     columnAuditSummary `json:"summary"`
+	Columns     []columnAuditEntry `json:"columns"`
+	NewlyHidden []string           `json:"newly_hidden,omitempty"`
+	Unresolved  []string           `json:"unresolved_references,omitempty"`
+}
+
+func ColumnAuditCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "column-audit",
+		Category: "Columns",
+		Usage:    "Classify columns by last write and find where they are used",
+		Description: `Report every column of a dataset with its status and references:
+
+  active         written within --stale-after
+  stale          last written longer ago than --stale-after
+  never_written  no recorded write
+  unknown        last write time could not be parsed
+
+References are collected from derived column expressions (of the dataset
+and environment-wide), SLO SLIs through their derived columns, board
+queries and preset filters, and query annotations.
+
+--hide-stale hides every stale column that has no references. When the
+references of any resource could not be read, they are listed under
+unresolved_references and nothing is hidden.
+
+Example:
+
+  hccli column-audit --dataset api --stale-after "90 days" --hide-stale`,
+		Flags: []cli.Flag{
+			DatasetFlag(),
+			&cli.StringFlag{
+				Name:  "stale-after",
+				Usage: `Age of the last write after which a column is stale (e.g. "30 days")`,
+				Value: "30 days",
+			},
+			&cli.BoolFlag{
+				Name:  "hide-stale",
+				Usage: "Hide stale columns that are not referenced anywhere",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			dataset := cmd.String("dataset")
+
+			staleSecs, err := timefmt.ParseTimeRange(cmd.String("stale-after"))
+			if err != nil {
+				return fmt.Errorf("invalid stale-after: %w", err)
+			}
+			cutoff := time.Now().Add(-time.Duration(staleSecs) * time.Second)
+
+			columns, err := client.ListColumns(ctx, dataset)
+			if err != nil {
+				return err
+			}
+			refs, unresolved, err := columnReferences(ctx, client, dataset)
+			if err != nil {
+				return err
+			}
+			for _, u := range unresolved {
+				fmt.Fprintf(os.Stderr, "⚠️  Could not read references from %s\n", u)
+			}
+			// A column used only by an unreadable resource looks unreferenced,
+			// so nothing is hidden when references are incomplete.
+			hide := cmd.Bool("hide-stale") && len(unresolved) == 0
+
+			report := columnAuditReport{Dataset: dataset, StaleAfter: cmd.String("stale-after"), Columns: []columnAuditEntry{}, Unresolved: unresolved}
+			byName := map[string]api.Column{}
+			for _, col := range columns {
+				byName[col.KeyName] = col
+				entry := columnAuditEntry{
+					KeyName:     col.KeyName,
+					ID:          col.ID,
+					Status:      columnStatus(col, cutoff),
+					LastWritten: col.LastWritten,
+					Hidden:      col.Hidden != nil && *col.Hidden,
+					References:  refs.list(col.KeyName),
+				}
+				report.Columns = append(report.Columns, entry)
+			}
+			sort.Slice(report.Columns, func(i, j int) bool { return report.Columns[i].KeyName < report.Columns[j].KeyName })
+
+			var failed int
+			for i := range report.Columns {
+				entry := &report.Columns[i]
+				if !hide || entry.Status != columnStale || entry.Hidden || len(entry.References) > 0 {
+					continue
+				}
+				col := byName[entry.KeyName]
+				hidden := true
+				if _, err := client.UpdateColumn(ctx, dataset, col.ID, &api.Column{
+					KeyName:     col.KeyName,
+					Type:        col.Type,
+					Description: col.Description,
+					Hidden:      &hidden,
+				}); err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  Could not hide column %s: %v\n", col.KeyName, err)
+					failed++
+					continue
+				}
+				entry.Hidden = true
+				report.NewlyHidden = append(report.NewlyHidden, entry.KeyName)
+			}
+
+			for _, entry := range report.Columns {
+				switch entry.Status {
+				case columnActive:
+					report.Summary.Active++
+				case columnStale:
+					report.Summary.Stale++
+				case columnNeverWritten:
+					report.Summary.NeverWritten++
+				case columnUnknown:
+					report.Summary.Unknown++
+				}
+				if len(entry.References) > 0 {
+					report.Summary.Referenced++
+				} else {
+					report.Summary.Unreferenced++
+				}
+				if entry.Hidden {
+					report.Summary.Hidden++
+				}
+			}
+
+			if err := printJSON(report); err != nil {
+				return err
+			}
+			if cmd.Bool("hide-stale") && len(unresolved) > 0 {
+				return fmt.Errorf("hid no columns: references of %d resource(s) could not be read", len(unresolved))
+			}
+			if failed > 0 {
+				return fmt.Errorf("failed to hide %d column(s)", failed)
+			}
+			return nil
+		},
+	}
+}
+
+func columnStatus(col api.Column, cutoff time.Time) string {
+	if col.LastWritten == "" {
+		return columnNeverWritten
+	}
+	t, err := time.Parse(time.RFC3339, col.LastWritten)
+	if err != nil {
+		return columnUnknown
+	}
+	if t.Before(cutoff) {
+		return columnStale
+	}
+	return columnActive
+}
+
+// columnRefs maps column names to the resources that use them.
+type columnRefs map[string]map[string]bool
+
+func (r columnRefs) add(column, ref string) {
+	if r[column] == nil {
+		r[column] = map[string]bool{}
+	}
+	r[column][ref] = true
+}
+
+func (r columnRefs) list(column string) []string {
+	out := []string{}
+	for ref := range r[column] {
+		out = append(out, ref)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// columnReferences collects the columns used by derived columns, SLOs,
+// boards and query annotations of a dataset. It also describes every
+// resource whose columns could not be read.
+func columnReferences(ctx context.Context, client *api.Client, dataset string) (columnRefs, []string, error) {
+	refs := columnRefs{}
+	var unresolved []string
+
+	derived, err := client.ListDerivedColumns(ctx, dataset)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing derived columns: %w", err)
+	}
+	if dataset != environmentDataset {
+		if envDerived, err := client.ListDerivedColumns(ctx, environmentDataset); err == nil {
+			derived = append(derived, envDerived...)
+		} else {
+			unresolved = append(unresolved, fmt.Sprintf("environment-wide derived columns: %v", err))
+		}
+	}
+	derivedColumns := map[string][]string{}
+	for _, dc := range derived {
+		cols := expressionColumns(dc.Expression)
+		derivedColumns[dc.Alias] = cols
+		for _, c := range cols {
+			refs.add(c, "derived_column:"+dc.Alias)
+		}
+	}
+
+	slos, err := client.ListSLOs(ctx, dataset)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing SLOs: %w", err)
+	}
+	for _, slo := range slos {
+		for _, c := range derivedColumns[slo.SLI.Alias] {
+			refs.add(c, "slo:"+slo.Name)
+		}
+	}
+
+	// Queries are looked up in the audited dataset; board panels querying
+	// other datasets are not found there and are skipped.
+	queryRefs := map[string][]string{}
+	annotations, err := client.ListQueryAnnotations(ctx, dataset)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing query annotations: %w", err)
+	}
+	for _, a := range annotations {
+		queryRefs[a.QueryID] = append(queryRefs[a.QueryID], "query_annotation:"+a.Name)
+	}
+	boards, err := client.ListBoards(ctx)
+	if err != nil {
+		return nil, nil, fmt.Errorf("listing boards: %w", err)
+	}
+	for _, b := range boards {
+		for _, pf := range b.PresetFilters {
+			refs.add(pf.Column, "board:"+b.Name)
+		}
+		for _, p := range b.Panels {
+			if p.QueryPanel != nil && p.QueryPanel.QueryID != "" {
+				queryRefs[p.QueryPanel.QueryID] = append(queryRefs[p.QueryPanel.QueryID], "board:"+b.Name)
+			}
+		}
+	}
+
+	ids := make([]string, 0, len(queryRefs))
+	for id := range queryRefs {
+		ids = append(ids, id)
+	}
+	queries := make([]*api.Query, len(ids))
+	errs := make([]error, len(ids))
+	parallel(len(ids), 8, func(i int) {
+		queries[i], errs[i] = client.GetQuery(ctx, dataset, ids[i])
+	})
+	for i, q := range queries {
+		if errs[i] != nil {
+			if !api.IsNotFound(errs[i]) {
+				unresolved = append(unresolved, fmt.Sprintf("query %s (%s): %v", ids[i], strings.Join(queryRefs[ids[i]], ", "), errs[i]))
+			}
+			continue
+		}
+		for _, c := range queryColumns(q) {
+			for _, ref := range queryRefs[ids[i]] {
+				refs.add(c, ref)
+			}
+			for _, dc := range derivedColumns[c] {
+				for _, ref := range queryRefs[ids[i]] {
+					refs.add(dc, ref)
+				}
+			}
+		}
+	}
+	sort.Strings(unresolved)
+	return refs, unresolved, nil
+}
+
+var expressionColumnRef = regexp.MustCompile(`\$("(?:[^"\\]|\\.)*"|[A-Za-z_][A-Za-z0-9_.]*)`)
+
+// expressionColumns returns the columns referenced in a derived column
+// expression, as $name or $"quoted name".
+func expressionColumns(expr string) []string {
+	var cols []string
+	seen := map[string]bool{}
+	for _, m := range expressionColumnRef.FindAllStringSubmatch(expr, -1) {
+		name := m[1]
+		if unquoted, err := strconv.Unquote(name); err == nil {
+			name = unquoted
+		}
+		if !seen[name] {
+			seen[name] = true
+			cols = append(cols, name)
+		}
+	}
+	return cols
+}
+
+// queryColumns returns the columns a query breaks down, calculates, filters,
+// orders or has havings on.
+func queryColumns(q *api.Query) []string {
+	var cols []string
+	cols = append(cols, q.Breakdowns...)
+	for _, c := range q.Calculations {
+		cols = append(cols, c.Column)
+	}
+	for _, f := range q.Filters {
+		cols = append(cols, f.Column)
+	}
+	for _, o := range q.Orders {
+		cols = append(cols, o.Column)
+	}
+	for _, h := range q.Havings {
+		cols = append(cols, h.Column)
+	}
+	out := cols[:0]
+	for _, c := range cols {
+		if c != "" {
+			out = append(out, c)
+		}
+	}
+	return out
+}
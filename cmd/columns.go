@@ -20,7 +20,10 @@ func ListColumnsCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			cols, err := client.ListColumns(ctx, cmd.String("dataset"))
 			if err != nil {
@@ -50,7 +53,10 @@ func GetColumnCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			col, err := client.GetColumn(ctx, cmd.String("dataset"), cmd.String("id"))
 			if err != nil {
@@ -93,7 +99,10 @@ func CreateColumnCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			hidden := cmd.Bool("hidden")
 			col := &api.Column{
@@ -143,23 +152,38 @@ func UpdateColumnCmd() *cli.Command {
 				Name:  "hidden",
 				Usage: "Hide column from autocomplete",
 			},
+			ShowDiffFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			if err := requireAnySet(cmd, "type", "description", "hidden"); err != nil {
+				return err
+			}
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
-			col := &api.Column{}
-			if v := cmd.String("type"); v != "" {
-				col.Type = v
+			cur, err := client.GetColumn(ctx, cmd.String("dataset"), cmd.String("id"))
+			if err != nil {
+				return err
 			}
-			if v := cmd.String("description"); v != "" {
-				col.Description = v
+			current := api.Column{KeyName: cur.KeyName, Type: cur.Type, Description: cur.Description, Hidden: cur.Hidden}
+			col := current
+			if cmd.IsSet("type") {
+				col.Type = cmd.String("type")
+			}
+			if cmd.IsSet("description") {
+				col.Description = cmd.String("description")
 			}
 			if cmd.IsSet("hidden") {
 				hidden := cmd.Bool("hidden")
 				col.Hidden = &hidden
 			}
+			if err := showUpdateDiff(cmd, current, col); err != nil {
+				return err
+			}
 
-			updated, err := client.UpdateColumn(ctx, cmd.String("dataset"), cmd.String("id"), col)
+			updated, err := client.UpdateColumn(ctx, cmd.String("dataset"), cmd.String("id"), &col)
 			if err != nil {
 				return err
 			}
@@ -185,9 +209,20 @@ func DeleteColumnCmd() *cli.Command {
 				Usage:    "Column ID",
 				Required: true,
 			},
+			YesFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			col, err := client.GetColumn(ctx, cmd.String("dataset"), cmd.String("id"))
+			if err != nil {
+				return err
+			}
+			if err := confirmDelete(cmd, "column", col.KeyName, col); err != nil {
+				return err
+			}
 			return client.DeleteColumn(ctx, cmd.String("dataset"), cmd.String("id"))
 		},
 	}
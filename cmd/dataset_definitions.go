@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/urfave/cli/v3"
+)
+
+// datasetDefinitionFields lists the flags of update-dataset-definitions
+// and the definition each one sets.
+var datasetDefinitionFields = []struct {
+	flag  string
+	usage string
+	field func(*api.DatasetDefinitions) **api.DatasetDefinition
+}{
+	{"annotation-type", "Column holding the annotation type of span events and links", func(d *api.DatasetDefinitions) **api.DatasetDefinition { return &d.AnnotationType }},
+	{"duration-ms", "Column holding the span duration in milliseconds", func(d *api.DatasetDefinitions) **api.DatasetDefinition { return &d.DurationMs }},
+	{"error", "Column that marks a span as an error", func(d *api.DatasetDefinitions) **api.DatasetDefinition { return &d.Error }},
+	{"link-span-id", "Column holding the span ID of a link", func(d *api.DatasetDefinitions) **api.DatasetDefinition { return &d.LinkSpanID }},
+	{"link-trace-id", "Column holding the trace ID of a link", func(d *api.DatasetDefinitions) **api.DatasetDefinition { return &d.LinkTraceID }},
+	{"name", "Column holding the span name", func(d *api.DatasetDefinitions) **api.DatasetDefinition { return &d.Name }},
+	{"parent-id", "Column holding the parent span ID", func(d *api.DatasetDefinitions) **api.DatasetDefinition { return &d.ParentID }},
+	{"route", "Column holding the HTTP route", func(d *api.DatasetDefinitions) **api.DatasetDefinition { return &d.Route }},
+	{"service-name", "Column holding the service name", func(d *api.DatasetDefinitions) **api.DatasetDefinition { return &d.ServiceName }},
+	{"span-id", "Column holding the span ID", func(d *api.DatasetDefinitions) **api.DatasetDefinition { return &d.SpanID }},
+	{"span-kind", "Column holding the span kind", func(d *api.DatasetDefinitions) **api.DatasetDefinition { return &d.SpanKind }},
+	{"status", "Column holding the status code", func(d *api.DatasetDefinitions) **api.DatasetDefinition { return &d.Status }},
+	{"trace-id", "Column holding the trace ID", func(d *api.DatasetDefinitions) **api.DatasetDefinition { return &d.TraceID }},
+	{"user", "Column holding the user", func(d *api.DatasetDefinitions) **api.DatasetDefinition { return &d.User }},
+}
+
+func GetDatasetDefinitionsCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "dataset-definitions",
+		Category: "Datasets",
+		Usage:    "Show which columns play the trace ID, duration and other roles in a dataset",
+		Flags: []cli.Flag{
+			DatasetFlag(),
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			defs, err := client.GetDatasetDefinitions(ctx, cmd.String("dataset"))
+			if err != nil {
+				return err
+			}
+
+			return printJSON(defs)
+		},
+	}
+}
+
+func UpdateDatasetDefinitionsCmd() *cli.Command {
+	flags := []cli.Flag{DatasetFlag()}
+	for _, f := range datasetDefinitionFields {
+		flags = append(flags, &cli.StringFlag{
+			Name:  f.flag,
+			Usage: f.usage + " (empty to clear)",
+		})
+	}
+
+	return &cli.Command{
+		Name:     "update-dataset-definitions",
+		Category: "Datasets",
+		Usage:    "Set which columns play the trace ID, duration and other roles in a dataset",
+		Description: `Only the definitions given as flags are changed; an empty value clears
+a definition.
+
+Example:
+
+  hccli update-dataset-definitions --dataset api --trace-id traceId --parent-id parentId`,
+		Flags: flags,
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			defs := &api.DatasetDefinitions{}
+			var set int
+			for _, f := range datasetDefinitionFields {
+				if cmd.IsSet(f.flag) {
+					*f.field(defs) = &api.DatasetDefinition{Name: cmd.String(f.flag)}
+					set++
+				}
+			}
+			if set == 0 {
+				return fmt.Errorf("at least one definition flag is required")
+			}
+
+			updated, err := client.UpdateDatasetDefinitions(ctx, cmd.String("dataset"), defs)
+			if err != nil {
+				return err
+			}
+
+			return printJSON(updated)
+		},
+	}
+}
+
+// traceColumns are the columns that trace commands read.
+type traceColumns struct {
+	TraceID     string `json:"trace_id"`
+	SpanID      string `json:"span_id"`
+	ParentID    string `json:"parent_id"`
+	Name        string `json:"name"`
+	ServiceName string `json:"service_name"`
+	DurationMs  string `json:"duration_ms"`
+	Error       string `json:"error"`
+}
+
+// defaultTraceColumns are the column names of OpenTelemetry data in
+// Honeycomb, used for roles without a dataset definition.
+var defaultTraceColumns = traceColumns{
+	TraceID:     "trace.trace_id",
+	SpanID:      "trace.span_id",
+	ParentID:    "trace.parent_id",
+	Name:        "name",
+	ServiceName: "service.name",
+	DurationMs:  "duration_ms",
+	Error:       "error",
+}
+
+// resolveTraceColumns returns the trace columns of a dataset from its
+// definitions, falling back to the defaults for unset roles or when the
+// definitions cannot be read.
+func resolveTraceColumns(ctx context.Context, client *api.Client, dataset string) traceColumns {
+	cols := defaultTraceColumns
+	defs, err := client.GetDatasetDefinitions(ctx, dataset)
+	if err != nil {
+		warnSkipped("dataset definitions, using default trace columns", err)
+		return cols
+	}
+	for _, d := range []struct {
+		def *api.DatasetDefinition
+		col *string
+	}{
+		{defs.TraceID, &cols.TraceID},
+		{defs.SpanID, &cols.SpanID},
+		{defs.ParentID, &cols.ParentID},
+		{defs.Name, &cols.Name},
+		{defs.ServiceName, &cols.ServiceName},
+		{defs.DurationMs, &cols.DurationMs},
+		{defs.Error, &cols.Error},
+	} {
+		if d.def != nil && d.def.Name != "" {
+			*d.col = d.def.Name
+		}
+	}
+	return cols
+}
@@ -0,0 +1,313 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/timefmt"
+	"github.com/urfave/cli/v3"
+)
+
+type datasetInventoryRow struct {
+	Slug                string   `json:"slug"`
+	Name                string   `json:"name"`
+	LastWrittenAt       *string  `json:"last_written_at"`
+	DaysSinceWrite      *int     `json:"days_since_write"`
+	RegularColumnsCount *int     `json:"regular_columns_count"`
+	DerivedColumns      *int     `json:"derived_columns"`
+	SLOs                *int     `json:"slos"`
+	MarkersLastWeek     *int     `json:"markers_last_week"`
+	EventCount          *int64   `json:"event_count,omitempty"`
+	Inactive            bool     `json:"inactive"`
+	Errors              []string `json:"errors,omitempty"`
+}
+
+func DatasetInventoryCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "dataset-inventory",
+		Category: "Datasets",
+		Usage:    "List datasets with activity, size and usage metadata",
+		Description: `For every dataset, report when it was last written, its number of
+columns, derived columns and SLOs, the markers created in the last week
+and the number of events in --window (a COUNT query; skip it with
+--skip-volume). Datasets without writes for --inactive-days days are
+flagged as inactive, candidates for deletion.
+
+Datasets are inspected --concurrency at a time and API calls are limited
+to --requests-per-second; a failed lookup is reported on its dataset
+without stopping the others.
+
+Example:
+
+  hccli dataset-inventory --window "7 days" --inactive-days 60 --output text`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "window",
+				Usage: `Time range of the event count (e.g. "7 days")`,
+				Value: "last day",
+			},
+			&cli.IntFlag{
+				Name:  "inactive-days",
+				Usage: "Flag datasets without writes for this many days",
+				Value: 30,
+			},
+			&cli.BoolFlag{
+				Name:  "skip-volume",
+				Usage: "Do not run the event count queries",
+			},
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Usage: "Maximum number of datasets inspected at once",
+				Value: 4,
+			},
+			&cli.FloatFlag{
+				Name:  "requests-per-second",
+				Usage: "Maximum rate of API calls",
+				Value: 5,
+			},
+			&cli.IntFlag{
+				Name:  "query-timeout",
+				Usage: "Seconds to wait for each event count query",
+				Value: 60,
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "Output format: json or text",
+				Value: "json",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			window, err := timefmt.ParseTimeRange(cmd.String("window"))
+			if err != nil {
+				return fmt.Errorf("invalid window: %w", err)
+			}
+			if cmd.Float("requests-per-second") <= 0 {
+				return fmt.Errorf("--requests-per-second must be positive")
+			}
+			limiter := newRateLimiter(cmd.Float("requests-per-second"))
+			defer limiter.stop()
+
+			if err := limiter.wait(ctx); err != nil {
+				return err
+			}
+			datasets, err := client.ListDatasets(ctx)
+			if err != nil {
+				return fmt.Errorf("listing datasets: %w", err)
+			}
+
+			inv := datasetInventory{
+				client:       client,
+				limiter:      limiter,
+				now:          time.Now(),
+				window:       window,
+				inactiveDays: int(cmd.Int("inactive-days")),
+				volume:       !cmd.Bool("skip-volume"),
+				queryTimeout: time.Duration(cmd.Int("query-timeout")) * time.Second,
+			}
+			rows := make([]datasetInventoryRow, len(datasets))
+			parallel(len(datasets), int(cmd.Int("concurrency")), func(i int) {
+				rows[i] = inv.inspect(ctx, datasets[i])
+			})
+			sort.Slice(rows, func(i, j int) bool { return rows[i].Slug < rows[j].Slug })
+
+			if cmd.String("output") == "text" {
+				printDatasetInventoryText(rows, int(cmd.Int("inactive-days")))
+				return nil
+			}
+			return printJSON(rows)
+		},
+	}
+}
+
+type datasetInventory struct {
+	client       *api.Client
+	limiter      *rateLimiter
+	now          time.Time
+	window       int
+	inactiveDays int
+	volume       bool
+	queryTimeout time.Duration
+}
+
+func (inv datasetInventory) inspect(ctx context.Context, ds api.Dataset) datasetInventoryRow {
+	row := datasetInventoryRow{
+		Slug:                ds.Slug,
+		Name:                ds.Name,
+		LastWrittenAt:       ds.LastWrittenAt,
+		RegularColumnsCount: ds.RegularColumnsCount,
+		Inactive:            true,
+	}
+	if ds.LastWrittenAt != nil {
+		if t, err := time.Parse(time.RFC3339, *ds.LastWrittenAt); err == nil {
+			days := int(inv.now.Sub(t).Hours() / 24)
+			row.DaysSinceWrite = &days
+			row.Inactive = days >= inv.inactiveDays
+		}
+	}
+
+	var mu sync.Mutex
+	fail := func(what string, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		row.Errors = append(row.Errors, fmt.Sprintf("%s: %v", what, err))
+		warnSkipped(what+" of "+ds.Slug, err)
+	}
+
+	lookups := []func(){
+		func() {
+			derived, err := limited(ctx, inv.limiter, func() ([]api.DerivedColumn, error) { return inv.client.ListDerivedColumns(ctx, ds.Slug) })
+			if err != nil {
+				fail("derived columns", err)
+				return
+			}
+			n := len(derived)
+			row.DerivedColumns = &n
+		},
+		func() {
+			slos, err := limited(ctx, inv.limiter, func() ([]api.SLO, error) { return inv.client.ListSLOs(ctx, ds.Slug) })
+			if err != nil {
+				fail("SLOs", err)
+				return
+			}
+			n := len(slos)
+			row.SLOs = &n
+		},
+		func() {
+			markers, err := limited(ctx, inv.limiter, func() ([]api.Marker, error) { return inv.client.ListMarkers(ctx, ds.Slug) })
+			if err != nil {
+				fail("markers", err)
+				return
+			}
+			since := inv.now.Add(-7 * 24 * time.Hour).Unix()
+			n := 0
+			for _, m := range markers {
+				if m.StartTime != nil && *m.StartTime >= since {
+					n++
+				}
+			}
+			row.MarkersLastWeek = &n
+		},
+	}
+	if inv.volume {
+		lookups = append(lookups, func() {
+			count, err := inv.eventCount(ctx, ds.Slug)
+			if err != nil {
+				fail("event count", err)
+				return
+			}
+			row.EventCount = &count
+		})
+	}
+	parallel(len(lookups), len(lookups), func(i int) { lookups[i]() })
+
+	sort.Strings(row.Errors)
+	return row
+}
+
+// eventCount runs a COUNT query over the inventory window.
+func (inv datasetInventory) eventCount(ctx context.Context, dataset string) (int64, error) {
+	q, err := limited(ctx, inv.limiter, func() (*api.Query, error) {
+		return inv.client.CreateQuery(ctx, dataset, &api.Query{
+			Calculations: []api.Calculation{{Op: "COUNT"}},
+			TimeRange:    inv.window,
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+	result, err := pollQueryResultLimited(ctx, inv.client, inv.limiter, dataset, q.ID, time.Second, inv.queryTimeout)
+	if err != nil {
+		return 0, err
+	}
+	var total int64
+	for _, row := range result.Data.Results {
+		data, _ := row["data"].(map[string]any)
+		count, _ := data["COUNT"].(float64)
+		total += int64(count)
+	}
+	return total, nil
+}
+
+// rateLimiter spaces out calls evenly, without bursts.
+type rateLimiter struct {
+	ticker *time.Ticker
+}
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	return &rateLimiter{ticker: time.NewTicker(time.Duration(float64(time.Second) / perSecond))}
+}
+
+// wait blocks until the next call is allowed. A nil limiter allows every
+// call.
+func (r *rateLimiter) wait(ctx context.Context) error {
+	if r == nil {
+		return ctx.Err()
+	}
+	select {
+	case <-r.ticker.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *rateLimiter) stop() {
+	r.ticker.Stop()
+}
+
+// limited waits for the rate limiter and then calls fn.
+func limited[T any](ctx context.Context, r *rateLimiter, fn func() (T, error)) (T, error) {
+	if err := r.wait(ctx); err != nil {
+		var zero T
+		return zero, err
+	}
+	return fn()
+}
+
+func printDatasetInventoryText(rows []datasetInventoryRow, inactiveDays int) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATASET\tLAST WRITE\tCOLUMNS\tDERIVED\tSLOS\tMARKERS (7D)\tEVENTS\tSTATUS")
+	var inactive int
+	for _, r := range rows {
+		lastWrite := "never"
+		if r.DaysSinceWrite != nil {
+			lastWrite = strconv.Itoa(*r.DaysSinceWrite) + "d ago"
+		}
+		events := "-"
+		if r.EventCount != nil {
+			events = strconv.FormatInt(*r.EventCount, 10)
+		}
+		status := "active"
+		if r.Inactive {
+			status = "INACTIVE"
+			inactive++
+		}
+		if len(r.Errors) > 0 {
+			status += fmt.Sprintf(" (%d lookup(s) failed)", len(r.Errors))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", r.Slug, lastWrite,
+			optionalInt(r.RegularColumnsCount), optionalInt(r.DerivedColumns),
+			optionalInt(r.SLOs), optionalInt(r.MarkersLastWeek), events, status)
+	}
+	_ = w.Flush()
+	fmt.Printf("\n%d of %d dataset(s) without writes in %d days.\n", inactive, len(rows), inactiveDays)
+}
+
+func optionalInt(v *int) string {
+	if v == nil {
+		return "-"
+	}
+	return strconv.Itoa(*v)
+}
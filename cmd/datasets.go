@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/LarsEckart/hccli/api"
 	"github.com/urfave/cli/v3"
@@ -13,7 +14,10 @@ func ListDatasetsCmd() *cli.Command {
 		Category: "Datasets",
 		Usage:    "List all datasets",
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			datasets, err := client.ListDatasets(ctx)
 			if err != nil {
@@ -38,7 +42,10 @@ func GetDatasetCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			ds, err := client.GetDataset(ctx, cmd.String("slug"))
 			if err != nil {
@@ -71,7 +78,10 @@ func CreateDatasetCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			ds := &api.Dataset{
 				Name: cmd.String("name"),
@@ -99,6 +109,12 @@ func UpdateDatasetCmd() *cli.Command {
 		Name:     "update-dataset",
 		Category: "Datasets",
 		Usage:    "Update a dataset by slug",
+		Description: `Only the fields given as flags change; the others keep their current
+value.
+
+Example:
+
+  hccli update-dataset --slug api --expand-json-depth 3 --show-diff`,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:     "slug",
@@ -106,27 +122,44 @@ func UpdateDatasetCmd() *cli.Command {
 				Required: true,
 			},
 			&cli.StringFlag{
-				Name:     "description",
-				Usage:    "Dataset description",
-				Required: true,
+				Name:  "description",
+				Usage: "Dataset description",
 			},
 			&cli.IntFlag{
-				Name:     "expand-json-depth",
-				Usage:    "Maximum unpacking depth of nested JSON fields (0-10)",
-				Required: true,
+				Name:  "expand-json-depth",
+				Usage: "Maximum unpacking depth of nested JSON fields (0-10)",
 			},
 			&cli.BoolFlag{
 				Name:  "delete-protected",
 				Usage: "Enable deletion protection",
 			},
+			ShowDiffFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			if err := requireAnySet(cmd, "description", "expand-json-depth", "delete-protected"); err != nil {
+				return err
+			}
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
-			depth := int(cmd.Int("expand-json-depth"))
-			ds := &api.Dataset{
-				Description:     cmd.String("description"),
-				ExpandJSONDepth: &depth,
+			cur, err := client.GetDataset(ctx, cmd.String("slug"))
+			if err != nil {
+				return err
+			}
+			current := api.Dataset{
+				Description:     cur.Description,
+				ExpandJSONDepth: cur.ExpandJSONDepth,
+				Settings:        cur.Settings,
+			}
+			ds := current
+			if cmd.IsSet("description") {
+				ds.Description = cmd.String("description")
+			}
+			if cmd.IsSet("expand-json-depth") {
+				depth := int(cmd.Int("expand-json-depth"))
+				ds.ExpandJSONDepth = &depth
 			}
 			if cmd.IsSet("delete-protected") {
 				dp := cmd.Bool("delete-protected")
@@ -134,8 +167,11 @@ func UpdateDatasetCmd() *cli.Command {
 					DeleteProtected: &dp,
 				}
 			}
+			if err := showUpdateDiff(cmd, current, ds); err != nil {
+				return err
+			}
 
-			updated, err := client.UpdateDataset(ctx, cmd.String("slug"), ds)
+			updated, err := client.UpdateDataset(ctx, cmd.String("slug"), &ds)
 			if err != nil {
 				return err
 			}
@@ -150,15 +186,32 @@ func DeleteDatasetCmd() *cli.Command {
 		Name:     "delete-dataset",
 		Category: "Datasets",
 		Usage:    "Delete a dataset by slug",
+		Description: `The dataset must be typed back to confirm, or --yes given. Datasets
+with delete protection are never deleted; turn it off first with
+update-dataset --delete-protected=false.`,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:     "slug",
 				Usage:    "Dataset slug",
 				Required: true,
 			},
+			YesFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			ds, err := client.GetDataset(ctx, cmd.String("slug"))
+			if err != nil {
+				return err
+			}
+			if ds.Settings != nil && boolValue(ds.Settings.DeleteProtected) {
+				return fmt.Errorf("dataset %s is delete protected (turn it off with update-dataset --delete-protected=false)", ds.Slug)
+			}
+			if err := confirmDelete(cmd, "dataset", ds.Slug, ds); err != nil {
+				return err
+			}
 			return client.DeleteDataset(ctx, cmd.String("slug"))
 		},
 	}
@@ -0,0 +1,254 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/LarsEckart/hccli/expr"
+	"github.com/urfave/cli/v3"
+)
+
+// evalResult is the value of an expression for one event.
+type evalResult struct {
+	Line     int            `json:"line"`
+	Input    map[string]any `json:"input"`
+	Value    any            `json:"value"`
+	Expected any            `json:"expected,omitempty"`
+	Mismatch bool           `json:"mismatch,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+func EvalDerivedColumnCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "eval-derived-column",
+		Category: "Derived Columns",
+		Usage:    "Evaluate a derived column expression locally against sample events",
+		Description: `Compute an expression for every event of a newline-delimited JSON file
+(- for stdin) and print the columns it reads with the computed value.
+Nothing is sent to Honeycomb.
+
+Nested objects are flattened into dotted column names and arrays become
+JSON strings, as with unpacked nested JSON. EVENT_TIMESTAMP() reads the
+"timestamp" field (RFC 3339 or Unix seconds); INGEST_TIMESTAMP() is
+null.
+
+With --expect-field, each event carries its expected value in that field
+and the command exits with code 2 when any value differs, so SLI
+expressions can be tested in CI with fixture events:
+
+  {"status_code": 200, "duration_ms": 120, "expected": true}
+  {"status_code": 503, "duration_ms": 80, "expected": false}
+
+Example:
+
+  hccli eval-derived-column --events fixtures/sli.ndjson --expect-field expected \
+    --expression 'AND(LT($status_code, 500), LT($duration_ms, 300))'`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "expression",
+				Usage:    "Expression to evaluate",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:     "events",
+				Usage:    "Newline-delimited JSON file of events (- for stdin)",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "expect-field",
+				Usage: "Event field holding the expected value",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "Output format: json or text",
+				Value: "json",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			n, err := expr.Parse(cmd.String("expression"))
+			if err != nil {
+				return fmt.Errorf("parsing expression: %w", err)
+			}
+			if problems := expr.Check(n, nil); len(problems) > 0 {
+				return fmt.Errorf("checking expression: %s", problems[0])
+			}
+
+			events, err := readEvents(cmd.String("events"))
+			if err != nil {
+				return err
+			}
+
+			columns := expr.Columns(n)
+			expectField := cmd.String("expect-field")
+			results := make([]evalResult, 0, len(events))
+			var failed, mismatched int
+			for _, ev := range events {
+				res := evalResult{Line: ev.line, Input: map[string]any{}}
+				for _, c := range columns {
+					res.Input[c] = ev.Fields[c]
+				}
+				if res.Value, err = expr.Eval(n, ev.Event); err != nil {
+					res.Error = err.Error()
+					failed++
+				}
+				if expectField != "" {
+					expected, ok := ev.Fields[expectField]
+					if !ok {
+						return fmt.Errorf("line %d: no %q field", ev.line, expectField)
+					}
+					res.Expected = expected
+					if res.Error == "" && !expr.Equal(res.Value, expected) {
+						res.Mismatch = true
+						mismatched++
+					}
+				}
+				results = append(results, res)
+			}
+
+			if cmd.String("output") == "text" {
+				printEvalText(columns, expectField != "", results)
+			} else if err := printJSON(results); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return fmt.Errorf("failed to evaluate %d of %d events", failed, len(results))
+			}
+			if mismatched > 0 {
+				return cli.Exit(fmt.Sprintf("%d of %d events did not produce the expected value", mismatched, len(results)), exitCodeFindings)
+			}
+			return nil
+		},
+	}
+}
+
+func printEvalText(columns []string, expectations bool, results []evalResult) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := append([]string{"LINE"}, columns...)
+	header = append(header, "VALUE")
+	if expectations {
+		header = append(header, "EXPECTED")
+	}
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, r := range results {
+		row := []string{strconv.Itoa(r.Line)}
+		for _, c := range columns {
+			row = append(row, formatEvalValue(r.Input[c]))
+		}
+		if r.Error != "" {
+			row = append(row, "error: "+r.Error)
+		} else {
+			row = append(row, formatEvalValue(r.Value))
+		}
+		if expectations {
+			expected := formatEvalValue(r.Expected)
+			if r.Mismatch {
+				expected += "  MISMATCH"
+			}
+			row = append(row, expected)
+		}
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	_ = w.Flush()
+}
+
+// formatEvalValue quotes strings so that they can be told apart from
+// numbers, booleans and null.
+func formatEvalValue(v any) string {
+	if s, ok := v.(string); ok {
+		return strconv.Quote(s)
+	}
+	return expr.String(v)
+}
+
+type sampleEvent struct {
+	expr.Event
+	line int
+}
+
+// readEvents reads newline-delimited JSON events for local evaluation.
+func readEvents(path string) ([]sampleEvent, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, fmt.Errorf("reading events: %w", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	var events []sampleEvent
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+		dec := json.NewDecoder(bytes.NewReader(text))
+		dec.UseNumber()
+		var raw map[string]any
+		if err := dec.Decode(&raw); err != nil {
+			return nil, fmt.Errorf("reading events: line %d: %w", line, err)
+		}
+		fields := map[string]any{}
+		flattenEvent("", raw, fields)
+		events = append(events, sampleEvent{
+			Event: expr.Event{Fields: fields, Timestamp: eventTimestamp(fields["timestamp"])},
+			line:  line,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading events: %w", err)
+	}
+	return events, nil
+}
+
+// flattenEvent copies JSON values into fields, joining nested object keys
+// with dots and converting numbers to int64 or float64.
+func flattenEvent(prefix string, raw map[string]any, fields map[string]any) {
+	for k, v := range raw {
+		name := prefix + k
+		switch v := v.(type) {
+		case map[string]any:
+			flattenEvent(name+".", v, fields)
+		case []any:
+			b, _ := json.Marshal(v)
+			fields[name] = string(b)
+		case json.Number:
+			if n, err := v.Int64(); err == nil {
+				fields[name] = n
+			} else {
+				f, _ := v.Float64()
+				fields[name] = f
+			}
+		default:
+			fields[name] = v
+		}
+	}
+}
+
+// eventTimestamp reads an RFC 3339 timestamp or Unix seconds, or returns
+// the zero time.
+func eventTimestamp(v any) time.Time {
+	switch v := v.(type) {
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t
+		}
+	case int64:
+		return time.Unix(v, 0).UTC()
+	case float64:
+		return time.Unix(0, int64(v*1e9)).UTC()
+	}
+	return time.Time{}
+}
@@ -0,0 +1,348 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/expr"
+	"github.com/urfave/cli/v3"
+)
+
+// Node kinds of a derived column graph.
+const (
+	graphColumn        = "column"
+	graphDerivedColumn = "derived_column"
+	graphSLO           = "slo"
+)
+
+type graphNode struct {
+	ID         string `json:"id"`
+	Kind       string `json:"kind"`
+	Name       string `json:"name"`
+	Dataset    string `json:"dataset,omitempty"`
+	ResourceID string `json:"resource_id,omitempty"`
+}
+
+type graphEdge struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type graphParseError struct {
+	Alias string `json:"alias"`
+	Error string `json:"error"`
+}
+
+// derivedColumnGraph links derived columns to the columns and derived
+// columns their expressions reference, and SLOs to their SLI.
+type derivedColumnGraph struct {
+	Dataset     string            `json:"dataset"`
+	Nodes       []graphNode       `json:"nodes"`
+	Edges       []graphEdge       `json:"edges"`
+	Cycles      [][]string        `json:"cycles"`
+	ParseErrors []graphParseError `json:"parse_errors,omitempty"`
+}
+
+func DerivedColumnGraphCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "derived-column-graph",
+		Category: "Derived Columns",
+		Usage:    "Show which derived columns and SLOs depend on which columns",
+		Description: `Parse every derived column expression of a dataset (and the
+environment-wide derived columns it can use) and print the dependency
+graph: derived column -> column or derived column, and SLO -> SLI derived
+column. Cycles between derived columns are listed and exit with code 2.
+
+Expressions that do not parse are listed under parse_errors; their
+column references are still found where possible.
+
+Examples:
+
+  hccli derived-column-graph --dataset api
+  hccli derived-column-graph --dataset api --output dot | dot -Tsvg > graph.svg`,
+		Flags: []cli.Flag{
+			DatasetFlag(),
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "Output format: json or dot",
+				Value: "json",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			g, err := fetchDerivedColumnGraph(ctx, client, cmd.String("dataset"))
+			if err != nil {
+				return err
+			}
+
+			switch cmd.String("output") {
+			case "dot":
+				writeGraphDOT(os.Stdout, g)
+			case "json":
+				if err := printJSON(g); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("unknown output format %q (use json or dot)", cmd.String("output"))
+			}
+			if len(g.Cycles) > 0 {
+				return cli.Exit(fmt.Sprintf("%d derived column cycle(s) found", len(g.Cycles)), exitCodeFindings)
+			}
+			return nil
+		},
+	}
+}
+
+// fetchDerivedColumnGraph builds the graph of a dataset. Environment-wide
+// derived columns are included because dataset expressions can use them;
+// SLOs are not listed for the environment-wide dataset itself.
+func fetchDerivedColumnGraph(ctx context.Context, client *api.Client, dataset string) (*derivedColumnGraph, error) {
+	derived, err := client.ListDerivedColumns(ctx, dataset)
+	if err != nil {
+		return nil, fmt.Errorf("listing derived columns: %w", err)
+	}
+	var envDerived []api.DerivedColumn
+	var slos []api.SLO
+	if dataset != environmentDataset {
+		if envDerived, err = client.ListDerivedColumns(ctx, environmentDataset); err != nil {
+			warnSkipped("environment-wide derived columns", err)
+		}
+		if slos, err = client.ListSLOs(ctx, dataset); err != nil {
+			return nil, fmt.Errorf("listing SLOs: %w", err)
+		}
+	}
+	return buildDerivedColumnGraph(dataset, derived, envDerived, slos), nil
+}
+
+func buildDerivedColumnGraph(dataset string, derived, envDerived []api.DerivedColumn, slos []api.SLO) *derivedColumnGraph {
+	g := &derivedColumnGraph{Dataset: dataset, Nodes: []graphNode{}, Edges: []graphEdge{}, Cycles: [][]string{}}
+	nodes := map[string]bool{}
+	addNode := func(n graphNode) {
+		if !nodes[n.ID] {
+			nodes[n.ID] = true
+			g.Nodes = append(g.Nodes, n)
+		}
+	}
+
+	// Dataset derived columns shadow environment-wide ones with the same alias.
+	columns := map[string]api.DerivedColumn{}
+	var all []api.DerivedColumn
+	for _, dc := range derived {
+		columns[dc.Alias] = dc
+		all = append(all, dc)
+		addNode(graphNode{ID: graphDerivedColumn + ":" + dc.Alias, Kind: graphDerivedColumn, Name: dc.Alias, ResourceID: dc.ID})
+	}
+	for _, dc := range envDerived {
+		if _, ok := columns[dc.Alias]; ok {
+			continue
+		}
+		columns[dc.Alias] = dc
+		all = append(all, dc)
+		addNode(graphNode{ID: graphDerivedColumn + ":" + dc.Alias, Kind: graphDerivedColumn, Name: dc.Alias, Dataset: environmentDataset, ResourceID: dc.ID})
+	}
+
+	reference := func(name string) string {
+		if _, ok := columns[name]; ok {
+			return graphDerivedColumn + ":" + name
+		}
+		addNode(graphNode{ID: graphColumn + ":" + name, Kind: graphColumn, Name: name})
+		return graphColumn + ":" + name
+	}
+
+	adjacency := map[string][]string{}
+	for _, dc := range all {
+		var refs []string
+		if n, err := expr.Parse(dc.Expression); err == nil {
+			refs = expr.Columns(n)
+		} else {
+			g.ParseErrors = append(g.ParseErrors, graphParseError{Alias: dc.Alias, Error: err.Error()})
+			refs = expressionColumns(dc.Expression)
+		}
+		from := graphDerivedColumn + ":" + dc.Alias
+		for _, ref := range refs {
+			to := reference(ref)
+			g.Edges = append(g.Edges, graphEdge{From: from, To: to})
+			adjacency[from] = append(adjacency[from], to)
+		}
+	}
+	for _, slo := range slos {
+		from := graphSLO + ":" + slo.Name
+		addNode(graphNode{ID: from, Kind: graphSLO, Name: slo.Name, ResourceID: slo.ID})
+		if slo.SLI.Alias != "" {
+			g.Edges = append(g.Edges, graphEdge{From: from, To: reference(slo.SLI.Alias)})
+		}
+	}
+
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	sort.Slice(g.Edges, func(i, j int) bool {
+		if g.Edges[i].From != g.Edges[j].From {
+			return g.Edges[i].From < g.Edges[j].From
+		}
+		return g.Edges[i].To < g.Edges[j].To
+	})
+	g.Cycles = findCycles(g.Nodes, adjacency)
+	return g
+}
+
+// findCycles returns the strongly connected components of the graph that
+// contain a cycle, using Tarjan's algorithm. Each cycle is sorted, and
+// cycles are sorted by their first node.
+func findCycles(nodes []graphNode, adjacency map[string][]string) [][]string {
+	index := map[string]int{}
+	lowlink := map[string]int{}
+	onStack := map[string]bool{}
+	var stack []string
+	cycles := [][]string{}
+
+	var visit func(v string)
+	visit = func(v string) {
+		index[v] = len(index)
+		lowlink[v] = index[v]
+		stack = append(stack, v)
+		onStack[v] = true
+
+		selfLoop := false
+		for _, w := range adjacency[v] {
+			if w == v {
+				selfLoop = true
+			}
+			if _, seen := index[w]; !seen {
+				visit(w)
+				lowlink[v] = min(lowlink[v], lowlink[w])
+			} else if onStack[w] {
+				lowlink[v] = min(lowlink[v], index[w])
+			}
+		}
+
+		if lowlink[v] != index[v] {
+			return
+		}
+		var component []string
+		for {
+			w := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[w] = false
+			component = append(component, w)
+			if w == v {
+				break
+			}
+		}
+		if len(component) > 1 || selfLoop {
+			sort.Strings(component)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, n := range nodes {
+		if _, seen := index[n.ID]; !seen {
+			visit(n.ID)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return cycles[i][0] < cycles[j][0] })
+	return cycles
+}
+
+// dependents returns the nodes with an edge to id.
+func (g *derivedColumnGraph) dependents(id string) []string {
+	var out []string
+	for _, e := range g.Edges {
+		if e.To == id && e.From != id {
+			out = append(out, e.From)
+		}
+	}
+	return out
+}
+
+// derivedColumnDependents returns the derived columns and SLOs that use the
+// derived column alias of dataset. An environment-wide derived column can be
+// used from any dataset, so for those every dataset is scanned too, except
+// datasets that shadow it with a derived column of the same alias. Those
+// dependents are prefixed with their dataset.
+func derivedColumnDependents(ctx context.Context, client *api.Client, dataset, alias string) ([]string, error) {
+	id := graphDerivedColumn + ":" + alias
+	g, err := fetchDerivedColumnGraph(ctx, client, dataset)
+	if err != nil {
+		return nil, err
+	}
+	deps := g.dependents(id)
+	if dataset != environmentDataset {
+		return deps, nil
+	}
+
+	envDerived, err := client.ListDerivedColumns(ctx, environmentDataset)
+	if err != nil {
+		return nil, fmt.Errorf("listing derived columns: %w", err)
+	}
+	datasets, err := client.ListDatasets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing datasets: %w", err)
+	}
+	for _, ds := range datasets {
+		derived, err := client.ListDerivedColumns(ctx, ds.Slug)
+		if err != nil {
+			return nil, fmt.Errorf("listing derived columns for %s: %w", ds.Slug, err)
+		}
+		if slices.ContainsFunc(derived, func(dc api.DerivedColumn) bool { return dc.Alias == alias }) {
+			continue
+		}
+		slos, err := client.ListSLOs(ctx, ds.Slug)
+		if err != nil {
+			return nil, fmt.Errorf("listing SLOs for %s: %w", ds.Slug, err)
+		}
+		dg := buildDerivedColumnGraph(ds.Slug, derived, envDerived, slos)
+		for _, d := range dg.dependents(id) {
+			// Environment-wide dependents were found above.
+			if slices.ContainsFunc(dg.Nodes, func(n graphNode) bool { return n.ID == d && n.Dataset == environmentDataset }) {
+				continue
+			}
+			deps = append(deps, ds.Slug+"/"+d)
+		}
+	}
+	return deps, nil
+}
+
+func writeGraphDOT(w io.Writer, g *derivedColumnGraph) {
+	inCycle := map[string]bool{}
+	for _, c := range g.Cycles {
+		for _, id := range c {
+			inCycle[id] = true
+		}
+	}
+	shapes := map[string]string{graphColumn: "ellipse", graphDerivedColumn: "box", graphSLO: "doubleoctagon"}
+
+	fmt.Fprintf(w, "digraph %s {\n", dotQuote(g.Dataset))
+	fmt.Fprintln(w, "  rankdir=LR;")
+	for _, n := range g.Nodes {
+		label := n.Name
+		if n.Dataset != "" {
+			label += "\\n(" + n.Dataset + ")"
+		}
+		attrs := []string{"label=" + dotQuote(label), "shape=" + shapes[n.Kind]}
+		if inCycle[n.ID] {
+			attrs = append(attrs, "color=red")
+		}
+		fmt.Fprintf(w, "  %s [%s];\n", dotQuote(n.ID), strings.Join(attrs, ", "))
+	}
+	for _, e := range g.Edges {
+		attr := ""
+		if inCycle[e.From] && inCycle[e.To] {
+			attr = " [color=red]"
+		}
+		fmt.Fprintf(w, "  %s -> %s%s;\n", dotQuote(e.From), dotQuote(e.To), attr)
+	}
+	fmt.Fprintln(w, "}")
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
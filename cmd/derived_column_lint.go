@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/expr"
+	"github.com/urfave/cli/v3"
+)
+
+type lintReport struct {
+	Expression string         `json:"expression"`
+	Valid      bool           `json:"valid"`
+	Problems   []expr.Problem `json:"problems"`
+}
+
+func LintDerivedColumnCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "lint-derived-column",
+		Category: "Derived Columns",
+		Usage:    "Check a derived column expression without sending it",
+		Description: `Parse an expression and report syntax errors with their line and column,
+unknown functions and calls with the wrong number of arguments. With
+--dataset, references to columns that are neither columns nor derived
+columns of the dataset are reported as well; without it no API call is
+made. Column references are not checked for the environment-wide
+dataset __all__.
+
+create-derived-column and update-derived-column run the same checks
+before sending unless --no-lint is given.
+
+Exits with code 2 when problems are found.
+
+Example:
+
+  hccli lint-derived-column --dataset api --expression 'IF(LT($status_code, 500), 1, 0)'`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "expression",
+				Usage:    "Expression to check",
+				Required: true,
+			},
+			&cli.StringFlag{
+				Name:  "dataset",
+				Usage: "Dataset slug whose columns references are checked against",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "Output format: json or text",
+				Value: "json",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			expression := cmd.String("expression")
+
+			var known func(string) bool
+			if dataset := cmd.String("dataset"); dataset != "" {
+				client, err := newClient(cmd)
+				if err != nil {
+					return err
+				}
+				if known, err = knownColumns(ctx, client, dataset); err != nil {
+					return err
+				}
+			}
+
+			problems := expr.Lint(expression, known)
+			if cmd.String("output") == "text" {
+				for _, p := range problems {
+					fmt.Println(p)
+				}
+				if len(problems) == 0 {
+					fmt.Println("No problems found.")
+				}
+			} else if err := printJSON(lintReport{Expression: expression, Valid: len(problems) == 0, Problems: problems}); err != nil {
+				return err
+			}
+			if len(problems) > 0 {
+				return cli.Exit(fmt.Sprintf("%d problem(s) found", len(problems)), exitCodeFindings)
+			}
+			return nil
+		},
+	}
+}
+
+// knownColumns returns a lookup of the columns and derived column aliases
+// an expression in dataset may reference, or nil for the environment-wide
+// dataset, whose columns cannot be listed.
+func knownColumns(ctx context.Context, client *api.Client, dataset string) (func(string) bool, error) {
+	if dataset == environmentDataset {
+		return nil, nil
+	}
+	columns, err := client.ListColumns(ctx, dataset)
+	if err != nil {
+		return nil, fmt.Errorf("listing columns: %w", err)
+	}
+	derived, err := client.ListDerivedColumns(ctx, dataset)
+	if err != nil {
+		return nil, fmt.Errorf("listing derived columns: %w", err)
+	}
+	if envDerived, err := client.ListDerivedColumns(ctx, environmentDataset); err == nil {
+		derived = append(derived, envDerived...)
+	} else {
+		warnSkipped("environment-wide derived columns", err)
+	}
+
+	known := map[string]bool{}
+	for _, c := range columns {
+		known[c.KeyName] = true
+	}
+	for _, dc := range derived {
+		known[dc.Alias] = true
+	}
+	return func(name string) bool { return known[name] }, nil
+}
+
+// lintBeforeSend checks an expression about to be created or updated and
+// returns an error listing its problems.
+func lintBeforeSend(ctx context.Context, client *api.Client, dataset, expression string) error {
+	known, err := knownColumns(ctx, client, dataset)
+	if err != nil {
+		return err
+	}
+	problems := expr.Lint(expression, known)
+	if len(problems) == 0 {
+		return nil
+	}
+	lines := make([]string, len(problems))
+	for i, p := range problems {
+		lines[i] = "  " + p.String()
+	}
+	return fmt.Errorf("expression has %d problem(s) (use --no-lint to send it anyway):\n%s", len(problems), strings.Join(lines, "\n"))
+}
@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
 	"github.com/LarsEckart/hccli/api"
 	"github.com/urfave/cli/v3"
@@ -20,7 +22,10 @@ func ListDerivedColumnsCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			cols, err := client.ListDerivedColumns(ctx, cmd.String("dataset"))
 			if err != nil {
@@ -50,7 +55,10 @@ func GetDerivedColumnCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			col, err := client.GetDerivedColumn(ctx, cmd.String("dataset"), cmd.String("id"))
 			if err != nil {
@@ -87,9 +95,22 @@ func CreateDerivedColumnCmd() *cli.Command {
 				Name:  "description",
 				Usage: "Human-readable description",
 			},
+			&cli.BoolFlag{
+				Name:  "no-lint",
+				Usage: "Send the expression without checking it first",
+			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			if !cmd.Bool("no-lint") {
+				if err := lintBeforeSend(ctx, client, cmd.String("dataset"), cmd.String("expression")); err != nil {
+					return err
+				}
+			}
 
 			col := &api.DerivedColumn{
 				Alias:      cmd.String("alias"),
@@ -126,32 +147,58 @@ func UpdateDerivedColumnCmd() *cli.Command {
 				Required: true,
 			},
 			&cli.StringFlag{
-				Name:     "alias",
-				Usage:    "Human-readable name for the calculated field",
-				Required: true,
+				Name:  "alias",
+				Usage: "Human-readable name for the calculated field",
 			},
 			&cli.StringFlag{
-				Name:     "expression",
-				Usage:    "Expression to evaluate",
-				Required: true,
+				Name:  "expression",
+				Usage: "Expression to evaluate",
 			},
 			&cli.StringFlag{
 				Name:  "description",
 				Usage: "Human-readable description",
 			},
+			&cli.BoolFlag{
+				Name:  "no-lint",
+				Usage: "Send the expression without checking it first",
+			},
+			ShowDiffFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			if err := requireAnySet(cmd, "alias", "expression", "description"); err != nil {
+				return err
+			}
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
-			col := &api.DerivedColumn{
-				Alias:      cmd.String("alias"),
-				Expression: cmd.String("expression"),
+			if cmd.IsSet("expression") && !cmd.Bool("no-lint") {
+				if err := lintBeforeSend(ctx, client, cmd.String("dataset"), cmd.String("expression")); err != nil {
+					return err
+				}
 			}
-			if v := cmd.String("description"); v != "" {
-				col.Description = v
+
+			cur, err := client.GetDerivedColumn(ctx, cmd.String("dataset"), cmd.String("id"))
+			if err != nil {
+				return err
+			}
+			current := api.DerivedColumn{Alias: cur.Alias, Expression: cur.Expression, Description: cur.Description}
+			col := current
+			if cmd.IsSet("alias") {
+				col.Alias = cmd.String("alias")
+			}
+			if cmd.IsSet("expression") {
+				col.Expression = cmd.String("expression")
+			}
+			if cmd.IsSet("description") {
+				col.Description = cmd.String("description")
+			}
+			if err := showUpdateDiff(cmd, current, col); err != nil {
+				return err
 			}
 
-			updated, err := client.UpdateDerivedColumn(ctx, cmd.String("dataset"), cmd.String("id"), col)
+			updated, err := client.UpdateDerivedColumn(ctx, cmd.String("dataset"), cmd.String("id"), &col)
 			if err != nil {
 				return err
 			}
@@ -177,10 +224,36 @@ func DeleteDerivedColumnCmd() *cli.Command {
 				Usage:    "Derived column ID",
 				Required: true,
 			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Delete even if other derived columns or SLOs use it",
+			},
+			YesFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
-			return client.DeleteDerivedColumn(ctx, cmd.String("dataset"), cmd.String("id"))
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			dataset := cmd.String("dataset")
+
+			col, err := client.GetDerivedColumn(ctx, dataset, cmd.String("id"))
+			if err != nil {
+				return err
+			}
+			if !cmd.Bool("force") {
+				deps, err := derivedColumnDependents(ctx, client, dataset, col.Alias)
+				if err != nil {
+					return fmt.Errorf("checking dependents: %w", err)
+				}
+				if len(deps) > 0 {
+					return fmt.Errorf("derived column %s is used by %s (use --force to delete it anyway)", col.Alias, strings.Join(deps, ", "))
+				}
+			}
+			if err := confirmDelete(cmd, "derived column", col.Alias, col); err != nil {
+				return err
+			}
+			return client.DeleteDerivedColumn(ctx, dataset, cmd.String("id"))
 		},
 	}
 }
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/LarsEckart/hccli/manifest"
+	"github.com/urfave/cli/v3"
+)
+
+// driftEntry is one drifted resource in a drift report.
+type driftEntry struct {
+	Status  string                 `json:"status"`
+	Kind    string                 `json:"kind"`
+	Dataset string                 `json:"dataset,omitempty"`
+	Key     string                 `json:"key"`
+	ID      string                 `json:"id,omitempty"`
+	Fields  []manifest.FieldChange `json:"fields,omitempty"`
+}
+
+type driftReport struct {
+	Drift     bool         `json:"drift"`
+	Checked   int          `json:"checked"`
+	Resources []driftEntry `json:"resources"`
+}
+
+func DriftCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "drift",
+		Category: "Declarative",
+		Usage:    "Report live resources that differ from a directory of resource manifests",
+		Description: `Compare live resources with the manifests in --dir without changing
+anything, and print a JSON report of every drifted resource with the old
+(live) and new (manifest) value of each changed field.
+
+Statuses:
+  changed    the resource exists but fields differ from the manifest
+  missing    the resource is in the manifest but not in Honeycomb
+  unmanaged  the resource exists but is not in the manifest
+             (only with --include-unmanaged)
+
+Exit codes: 0 when there is no drift, 2 when drift is found, and 1 on
+errors, so the command can gate a scheduled CI job.
+
+` + manifestFormatHelp,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "dir",
+				Usage:    "Directory of resource manifests",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "include-unmanaged",
+				Usage: "Also report live resources of the managed kinds that are not in the manifests",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			changes, _, err := planManifests(ctx, client, cmd.String("dir"), cmd.Bool("include-unmanaged"))
+			if err != nil {
+				return err
+			}
+
+			report := driftReport{Resources: []driftEntry{}}
+			for _, c := range changes {
+				if c.Action != manifest.ActionDelete {
+					report.Checked++
+				}
+				var status string
+				switch c.Action {
+				case manifest.ActionUpdate:
+					status = "changed"
+				case manifest.ActionCreate:
+					status = "missing"
+				case manifest.ActionDelete:
+					status = "unmanaged"
+				default:
+					continue
+				}
+				report.Resources = append(report.Resources, driftEntry{
+					Status:  status,
+					Kind:    c.Kind,
+					Dataset: c.Dataset,
+					Key:     c.Key,
+					ID:      c.ID,
+					Fields:  c.Fields,
+				})
+			}
+			report.Drift = len(report.Resources) > 0
+
+			if err := printJSON(report); err != nil {
+				return err
+			}
+			if report.Drift {
+				return cli.Exit(fmt.Sprintf("drift detected in %d resource(s)", len(report.Resources)), exitCodeFindings)
+			}
+			return nil
+		},
+	}
+}
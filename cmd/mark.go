@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/urfave/cli/v3"
+)
+
+func MarkCmd() *cli.Command {
+	return &cli.Command{
+		Name:      "mark",
+		Category:  "Markers",
+		Usage:     "Run a command bracketed by a marker spanning its execution",
+		ArgsUsage: "-- <command> [args...]",
+		Description: `Create a marker when the command starts, run it, and update the marker
+with its end time and outcome when it finishes. The message gets a
+suffix such as "(succeeded in 2m5s)" or "(failed with exit code 1 after
+10s)", and hccli exits with the command's exit code.
+
+Without --message and --url, values are taken from the CI environment:
+
+  GitHub Actions  GITHUB_SHA, GITHUB_SERVER_URL, GITHUB_REPOSITORY, GITHUB_RUN_ID
+  GitLab CI       CI_COMMIT_SHORT_SHA, CI_PIPELINE_URL
+
+The command's output is passed through unchanged; hccli reports the
+marker on stderr. A failure to create the marker is reported but does not
+prevent the command from running.
+
+Example:
+
+  hccli mark --dataset api -- ./deploy.sh production`,
+		Flags: []cli.Flag{
+			DatasetFlag(),
+			&cli.StringFlag{
+				Name:  "type",
+				Usage: "Marker type",
+				Value: "deploy",
+			},
+			&cli.StringFlag{
+				Name:  "message",
+				Usage: "Marker message (default: commit from CI environment, or the command)",
+			},
+			&cli.StringFlag{
+				Name:  "url",
+				Usage: "URL target for the marker (default: CI run URL)",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			args := cmd.Args().Slice()
+			if len(args) == 0 {
+				return fmt.Errorf("a command to run is required, e.g. hccli mark --dataset api -- ./deploy.sh")
+			}
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			dataset := cmd.String("dataset")
+
+			message := cmd.String("message")
+			if message == "" {
+				message = ciMarkerMessage(args)
+			}
+			url := cmd.String("url")
+			if url == "" {
+				url = ciRunURL()
+			}
+
+			start := time.Now()
+			startUnix := start.Unix()
+			marker := &api.Marker{
+				Type:      cmd.String("type"),
+				Message:   message + " (running)",
+				URL:       url,
+				StartTime: &startUnix,
+			}
+			created, err := client.CreateMarker(ctx, dataset, marker)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  Could not create marker: %v\n", err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Created marker %s\n", created.ID)
+			}
+
+			code, runErr := runWrapped(ctx, args)
+			duration := time.Since(start).Round(time.Second)
+
+			if created != nil {
+				endUnix := time.Now().Unix()
+				marker.EndTime = &endUnix
+				if code == 0 {
+					marker.Message = fmt.Sprintf("%s (succeeded in %s)", message, duration)
+				} else {
+					marker.Message = fmt.Sprintf("%s (failed with exit code %d after %s)", message, code, duration)
+				}
+				if _, err := client.UpdateMarker(ctx, dataset, created.ID, marker); err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  Could not update marker %s: %v\n", created.ID, err)
+				} else {
+					fmt.Fprintf(os.Stderr, "Updated marker %s: %s\n", created.ID, marker.Message)
+				}
+			}
+
+			if runErr != nil {
+				return runErr
+			}
+			if code != 0 {
+				return cli.Exit(fmt.Sprintf("%s exited with code %d", args[0], code), code)
+			}
+			return nil
+		},
+	}
+}
+
+// runWrapped runs args with the standard streams of hccli, forwarding
+// interrupts so the marker can still be closed, and returns its exit code.
+func runWrapped(ctx context.Context, args []string) (int, error) {
+	c := exec.CommandContext(ctx, args[0], args[1:]...)
+	c.Stdin = os.Stdin
+	c.Stdout = os.Stdout
+	c.Stderr = os.Stderr
+	if err := c.Start(); err != nil {
+		return 127, fmt.Errorf("starting %s: %w", args[0], err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		for {
+			select {
+			case sig := <-signals:
+				_ = c.Process.Signal(sig)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	err := c.Wait()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return 0, nil
+	case errors.As(err, &exitErr):
+		if code := exitErr.ExitCode(); code > 0 {
+			return code, nil
+		}
+		return 1, nil
+	default:
+		return 1, err
+	}
+}
+
+// ciMarkerMessage describes the deploy from CI environment variables,
+// falling back to the command line.
+func ciMarkerMessage(args []string) string {
+	if sha := os.Getenv("GITHUB_SHA"); sha != "" {
+		return "Deploy " + shortSHA(sha)
+	}
+	if sha := os.Getenv("CI_COMMIT_SHORT_SHA"); sha != "" {
+		return "Deploy " + sha
+	}
+	return strings.Join(args, " ")
+}
+
+// ciRunURL returns the URL of the current CI run, if any.
+func ciRunURL() string {
+	if id := os.Getenv("GITHUB_RUN_ID"); id != "" && os.Getenv("GITHUB_REPOSITORY") != "" {
+		server := os.Getenv("GITHUB_SERVER_URL")
+		if server == "" {
+			server = "https://github.com"
+		}
+		return fmt.Sprintf("%s/%s/actions/runs/%s", server, os.Getenv("GITHUB_REPOSITORY"), id)
+	}
+	return os.Getenv("CI_PIPELINE_URL")
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
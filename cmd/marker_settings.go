@@ -2,6 +2,8 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"slices"
 
 	"github.com/LarsEckart/hccli/api"
 	"github.com/urfave/cli/v3"
@@ -20,7 +22,10 @@ func ListMarkerSettingsCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			settings, err := client.ListMarkerSettings(ctx, cmd.String("dataset"))
 			if err != nil {
@@ -55,7 +60,10 @@ func CreateMarkerSettingCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			ms := &api.MarkerSetting{
 				Type:  cmd.String("type"),
@@ -89,25 +97,41 @@ func UpdateMarkerSettingCmd() *cli.Command {
 				Required: true,
 			},
 			&cli.StringFlag{
-				Name:     "type",
-				Usage:    "Marker type (e.g. deploy)",
-				Required: true,
+				Name:  "type",
+				Usage: "Marker type (e.g. deploy)",
 			},
 			&cli.StringFlag{
-				Name:     "color",
-				Usage:    "Color as hexadecimal RGB (e.g. #FF0000)",
-				Required: true,
+				Name:  "color",
+				Usage: "Color as hexadecimal RGB (e.g. #FF0000)",
 			},
+			ShowDiffFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			if err := requireAnySet(cmd, "type", "color"); err != nil {
+				return err
+			}
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
-			ms := &api.MarkerSetting{
-				Type:  cmd.String("type"),
-				Color: cmd.String("color"),
+			cur, err := findMarkerSetting(ctx, client, cmd.String("dataset"), cmd.String("id"))
+			if err != nil {
+				return err
+			}
+			current := api.MarkerSetting{Type: cur.Type, Color: cur.Color}
+			ms := current
+			if cmd.IsSet("type") {
+				ms.Type = cmd.String("type")
+			}
+			if cmd.IsSet("color") {
+				ms.Color = cmd.String("color")
+			}
+			if err := showUpdateDiff(cmd, current, ms); err != nil {
+				return err
 			}
 
-			updated, err := client.UpdateMarkerSetting(ctx, cmd.String("dataset"), cmd.String("id"), ms)
+			updated, err := client.UpdateMarkerSetting(ctx, cmd.String("dataset"), cmd.String("id"), &ms)
 			if err != nil {
 				return err
 			}
@@ -133,10 +157,35 @@ func DeleteMarkerSettingCmd() *cli.Command {
 				Usage:    "Marker setting ID",
 				Required: true,
 			},
+			YesFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			ms, err := findMarkerSetting(ctx, client, cmd.String("dataset"), cmd.String("id"))
+			if err != nil {
+				return err
+			}
+			if err := confirmDelete(cmd, "marker setting", ms.Type, ms); err != nil {
+				return err
+			}
 			return client.DeleteMarkerSetting(ctx, cmd.String("dataset"), cmd.String("id"))
 		},
 	}
 }
+
+// findMarkerSetting looks up a marker setting by ID; the API has no
+// endpoint for a single one.
+func findMarkerSetting(ctx context.Context, client *api.Client, dataset, id string) (*api.MarkerSetting, error) {
+	settings, err := client.ListMarkerSettings(ctx, dataset)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(settings, func(s api.MarkerSetting) bool { return s.ID == id })
+	if i < 0 {
+		return nil, fmt.Errorf("marker setting %s not found in %s", id, dataset)
+	}
+	return &settings[i], nil
+}
@@ -0,0 +1,204 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/datafile"
+	"github.com/urfave/cli/v3"
+)
+
+// markerSettingChange reports a marker setting created or updated in a target dataset.
+type markerSettingChange struct {
+	Dataset  string `json:"dataset"`
+	Type     string `json:"type"`
+	Action   string `json:"action"`
+	OldColor string `json:"old_color,omitempty"`
+	Color    string `json:"color"`
+	Error    string `json:"error,omitempty"`
+}
+
+func SyncMarkerSettingsCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "sync-marker-settings",
+		Category: "Marker Settings",
+		Usage:    "Copy marker type colors from one dataset or a palette file to other datasets",
+		Description: `Create or update marker settings in the target datasets so that every
+marker type of the source has the same color. Types that exist only in a
+target are left alone. Only changes are reported.
+
+The source is a dataset (--from) or a palette file (--file), either a
+mapping of type to color or a list of marker settings as written by
+export-all:
+
+  deploy: "#1f77b4"
+  incident: "#d62728"
+
+Examples:
+
+  hccli sync-marker-settings --from api --to web,worker
+  hccli sync-marker-settings --file palette.yaml --all --dry-run`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "Source dataset slug",
+			},
+			&cli.StringFlag{
+				Name:  "file",
+				Usage: "Source palette file (YAML or JSON)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "to",
+				Usage: "Target dataset slugs, comma-separated or repeated",
+			},
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Target every dataset except the source",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Report the changes without making them",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			from := cmd.String("from")
+
+			var palette map[string]string
+			switch {
+			case from != "" && cmd.String("file") != "":
+				return fmt.Errorf("--from and --file are mutually exclusive")
+			case from != "":
+				settings, err := client.ListMarkerSettings(ctx, from)
+				if err != nil {
+					return fmt.Errorf("listing marker settings of %s: %w", from, err)
+				}
+				palette = map[string]string{}
+				for _, ms := range settings {
+					palette[ms.Type] = ms.Color
+				}
+			case cmd.String("file") != "":
+				var err error
+				if palette, err = readPalette(cmd.String("file")); err != nil {
+					return err
+				}
+			default:
+				return fmt.Errorf("either --from or --file is required")
+			}
+
+			targets := cmd.StringSlice("to")
+			switch {
+			case len(targets) > 0 && cmd.Bool("all"):
+				return fmt.Errorf("--to and --all are mutually exclusive")
+			case cmd.Bool("all"):
+				datasets, err := client.ListDatasets(ctx)
+				if err != nil {
+					return fmt.Errorf("listing datasets: %w", err)
+				}
+				for _, ds := range datasets {
+					if ds.Slug != from {
+						targets = append(targets, ds.Slug)
+					}
+				}
+			case len(targets) == 0:
+				return fmt.Errorf("either --to or --all is required")
+			}
+
+			types := make([]string, 0, len(palette))
+			for typ := range palette {
+				types = append(types, typ)
+			}
+			sort.Strings(types)
+
+			changes := []markerSettingChange{}
+			var failed int
+			for _, ds := range targets {
+				existing, err := client.ListMarkerSettings(ctx, ds)
+				if err != nil {
+					changes = append(changes, markerSettingChange{Dataset: ds, Action: "error", Error: err.Error()})
+					failed++
+					continue
+				}
+				byType := map[string]api.MarkerSetting{}
+				for _, ms := range existing {
+					byType[ms.Type] = ms
+				}
+
+				for _, typ := range types {
+					color := palette[typ]
+					live, ok := byType[typ]
+					if ok && strings.EqualFold(live.Color, color) {
+						continue
+					}
+
+					change := markerSettingChange{Dataset: ds, Type: typ, Color: color, Action: "created"}
+					dryRunAction := "would-create"
+					if ok {
+						change.Action, dryRunAction = "updated", "would-update"
+						change.OldColor = live.Color
+					}
+					if dryRun(cmd) {
+						change.Action = dryRunAction
+						changes = append(changes, change)
+						continue
+					}
+
+					ms := &api.MarkerSetting{Type: typ, Color: color}
+					var err error
+					if ok {
+						_, err = client.UpdateMarkerSetting(ctx, ds, live.ID, ms)
+					} else {
+						_, err = client.CreateMarkerSetting(ctx, ds, ms)
+					}
+					if err != nil {
+						change.Error = err.Error()
+						failed++
+					}
+					changes = append(changes, change)
+				}
+			}
+
+			if err := printJSON(changes); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d marker setting change(s) failed", failed)
+			}
+			return nil
+		},
+	}
+}
+
+// readPalette reads a mapping of marker type to color, or a list of marker
+// settings, from a YAML or JSON file.
+func readPalette(path string) (map[string]string, error) {
+	var raw json.RawMessage
+	if err := datafile.Read(path, &raw); err != nil {
+		return nil, fmt.Errorf("reading palette: %w", err)
+	}
+
+	var settings []api.MarkerSetting
+	if err := json.Unmarshal(raw, &settings); err == nil {
+		palette := map[string]string{}
+		for _, ms := range settings {
+			if ms.Type == "" || ms.Color == "" {
+				return nil, fmt.Errorf("reading palette: every marker setting needs a type and a color")
+			}
+			palette[ms.Type] = ms.Color
+		}
+		return palette, nil
+	}
+
+	var palette map[string]string
+	if err := json.Unmarshal(raw, &palette); err != nil {
+		return nil, fmt.Errorf("reading palette: expected a mapping of marker type to color or a list of marker settings")
+	}
+	return palette, nil
+}
@@ -2,8 +2,14 @@ package cmd
 
 import (
 	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/timefmt"
 	"github.com/urfave/cli/v3"
 )
 
@@ -11,23 +17,40 @@ func ListMarkersCmd() *cli.Command {
 	return &cli.Command{
 		Name:     "markers",
 		Category: "Markers",
-		Usage:    "List all markers for a dataset",
-		Flags: []cli.Flag{
+		Usage:    "List markers for a dataset",
+		Description: `List the markers of a dataset, optionally filtered by start time, type
+and message.
+
+--since and --until take a timestamp (Unix, ISO 8601, "2006-01-02 15:04"
+or a date) or a duration relative to now such as "2 hours" or "last week".
+
+Example:
+
+  hccli markers --dataset api --since "last week" --type deploy --message-contains rollback`,
+		Flags: append([]cli.Flag{
 			&cli.StringFlag{
 				Name:     "dataset",
 				Usage:    "Dataset slug (use __all__ for environment-wide)",
 				Required: true,
 			},
-		},
+		}, markerFilterFlags()...),
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			filter, err := parseMarkerFilter(cmd, time.Now())
+			if err != nil {
+				return err
+			}
 
 			markers, err := client.ListMarkers(ctx, cmd.String("dataset"))
 			if err != nil {
 				return err
 			}
 
-			return printJSON(markers)
+			return printJSON(filter.apply(markers))
 		},
 	}
 }
@@ -55,30 +78,28 @@ func CreateMarkerCmd() *cli.Command {
 				Name:  "url",
 				Usage: "URL target for the marker",
 			},
-			&cli.IntFlag{
+			&cli.StringFlag{
 				Name:  "start-time",
-				Usage: "Start time as Unix timestamp",
+				Usage: `Start time (Unix timestamp, ISO 8601, "2006-01-02 15:04" or date)`,
 			},
-			&cli.IntFlag{
+			&cli.StringFlag{
 				Name:  "end-time",
-				Usage: "End time as Unix timestamp",
+				Usage: `End time (Unix timestamp, ISO 8601, "2006-01-02 15:04" or date)`,
+			},
+			&cli.StringFlag{
+				Name:  "timezone",
+				Usage: `Timezone for parsing times without an offset (e.g. "Europe/Berlin", default UTC)`,
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
-
-			m := &api.Marker{
-				Message: cmd.String("message"),
-				Type:    cmd.String("type"),
-				URL:     cmd.String("url"),
-			}
-			if cmd.IsSet("start-time") {
-				v := int64(cmd.Int("start-time"))
-				m.StartTime = &v
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
 			}
-			if cmd.IsSet("end-time") {
-				v := int64(cmd.Int("end-time"))
-				m.EndTime = &v
+
+			m, err := buildMarker(cmd)
+			if err != nil {
+				return err
 			}
 
 			created, err := client.CreateMarker(ctx, cmd.String("dataset"), m)
@@ -96,6 +117,8 @@ func UpdateMarkerCmd() *cli.Command {
 		Name:     "update-marker",
 		Category: "Markers",
 		Usage:    "Update a marker by ID",
+		Description: `Only the fields given as flags change; the others keep their current
+value.`,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:     "dataset",
@@ -119,33 +142,59 @@ func UpdateMarkerCmd() *cli.Command {
 				Name:  "url",
 				Usage: "URL target for the marker",
 			},
-			&cli.IntFlag{
+			&cli.StringFlag{
 				Name:  "start-time",
-				Usage: "Start time as Unix timestamp",
+				Usage: `Start time (Unix timestamp, ISO 8601, "2006-01-02 15:04" or date)`,
 			},
-			&cli.IntFlag{
+			&cli.StringFlag{
 				Name:  "end-time",
-				Usage: "End time as Unix timestamp",
+				Usage: `End time (Unix timestamp, ISO 8601, "2006-01-02 15:04" or date)`,
+			},
+			&cli.StringFlag{
+				Name:  "timezone",
+				Usage: `Timezone for parsing times without an offset (e.g. "Europe/Berlin", default UTC)`,
 			},
+			ShowDiffFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			if err := requireAnySet(cmd, "message", "type", "url", "start-time", "end-time"); err != nil {
+				return err
+			}
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
-			m := &api.Marker{
-				Message: cmd.String("message"),
-				Type:    cmd.String("type"),
-				URL:     cmd.String("url"),
+			given, err := buildMarker(cmd)
+			if err != nil {
+				return err
+			}
+			cur, err := findMarker(ctx, client, cmd.String("dataset"), cmd.String("id"))
+			if err != nil {
+				return err
+			}
+			current := api.Marker{StartTime: cur.StartTime, EndTime: cur.EndTime, Message: cur.Message, Type: cur.Type, URL: cur.URL}
+			m := current
+			if cmd.IsSet("message") {
+				m.Message = given.Message
+			}
+			if cmd.IsSet("type") {
+				m.Type = given.Type
+			}
+			if cmd.IsSet("url") {
+				m.URL = given.URL
 			}
 			if cmd.IsSet("start-time") {
-				v := int64(cmd.Int("start-time"))
-				m.StartTime = &v
+				m.StartTime = given.StartTime
 			}
 			if cmd.IsSet("end-time") {
-				v := int64(cmd.Int("end-time"))
-				m.EndTime = &v
+				m.EndTime = given.EndTime
+			}
+			if err := showUpdateDiff(cmd, current, m); err != nil {
+				return err
 			}
 
-			updated, err := client.UpdateMarker(ctx, cmd.String("dataset"), cmd.String("id"), m)
+			updated, err := client.UpdateMarker(ctx, cmd.String("dataset"), cmd.String("id"), &m)
 			if err != nil {
 				return err
 			}
@@ -171,10 +220,242 @@ func DeleteMarkerCmd() *cli.Command {
 				Usage:    "Marker ID",
 				Required: true,
 			},
+			YesFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			m, err := findMarker(ctx, client, cmd.String("dataset"), cmd.String("id"))
+			if err != nil {
+				return err
+			}
+			if err := confirmDelete(cmd, "marker", m.ID, m); err != nil {
+				return err
+			}
 			return client.DeleteMarker(ctx, cmd.String("dataset"), cmd.String("id"))
 		},
 	}
 }
+
+func DeleteMarkersCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "delete-markers",
+		Category: "Markers",
+		Usage:    "Delete all markers matching filters",
+		Description: `Delete every marker of a dataset that matches the same filters as
+"markers". At least one filter is required. The matching markers are
+listed on stderr and deletion must be confirmed unless --yes is given.
+
+Example:
+
+  hccli delete-markers --dataset api --type test-deploy --until "2024-01-01" --yes`,
+		Flags: append([]cli.Flag{
+			&cli.StringFlag{
+				Name:     "dataset",
+				Usage:    "Dataset slug (use __all__ for environment-wide)",
+				Required: true,
+			},
+			YesFlag(),
+		}, markerFilterFlags()...),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			dataset := cmd.String("dataset")
+
+			filter, err := parseMarkerFilter(cmd, time.Now())
+			if err != nil {
+				return err
+			}
+			if filter.empty() {
+				return fmt.Errorf("at least one filter is required (--since, --until, --type or --message-contains)")
+			}
+
+			markers, err := client.ListMarkers(ctx, dataset)
+			if err != nil {
+				return err
+			}
+			matched := filter.apply(markers)
+			if len(matched) == 0 {
+				fmt.Fprintln(os.Stderr, "No markers match the filters")
+				return printJSON(matched)
+			}
+
+			for _, m := range matched {
+				fmt.Fprintf(os.Stderr, "  %s  %s  %s  %s\n", m.ID, formatMarkerTime(m.StartTime), m.Type, m.Message)
+			}
+			if !cmd.Bool("yes") && !dryRun(cmd) {
+				if !isTerminal(os.Stdin) {
+					return fmt.Errorf("refusing to delete %d marker(s) without a terminal to confirm on (use --yes)", len(matched))
+				}
+				if !confirm(fmt.Sprintf("Delete %d marker(s) from %s?", len(matched), dataset)) {
+					return fmt.Errorf("aborted")
+				}
+			}
+
+			deleted := make([]api.Marker, 0, len(matched))
+			var failed int
+			for _, m := range matched {
+				if err := client.DeleteMarker(ctx, dataset, m.ID); err != nil {
+					fmt.Fprintf(os.Stderr, "⚠️  Could not delete marker %s: %v\n", m.ID, err)
+					failed++
+					continue
+				}
+				deleted = append(deleted, m)
+			}
+			if err := printJSON(deleted); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return fmt.Errorf("failed to delete %d of %d markers", failed, len(matched))
+			}
+			return nil
+		},
+	}
+}
+
+// findMarker looks up a marker by ID; the API has no endpoint for a
+// single one.
+func findMarker(ctx context.Context, client *api.Client, dataset, id string) (*api.Marker, error) {
+	markers, err := client.ListMarkers(ctx, dataset)
+	if err != nil {
+		return nil, err
+	}
+	i := slices.IndexFunc(markers, func(m api.Marker) bool { return m.ID == id })
+	if i < 0 {
+		return nil, fmt.Errorf("marker %s not found in %s", id, dataset)
+	}
+	return &markers[i], nil
+}
+
+// buildMarker builds a marker from the message, type, url and time flags.
+func buildMarker(cmd *cli.Command) (*api.Marker, error) {
+	loc, err := loadLocation(cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &api.Marker{
+		Message: cmd.String("message"),
+		Type:    cmd.String("type"),
+		URL:     cmd.String("url"),
+	}
+	if v := cmd.String("start-time"); v != "" {
+		ts, err := timefmt.ParseTimestamp(v, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid start time %q: %w", v, err)
+		}
+		m.StartTime = &ts
+	}
+	if v := cmd.String("end-time"); v != "" {
+		ts, err := timefmt.ParseTimestamp(v, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end time %q: %w", v, err)
+		}
+		m.EndTime = &ts
+	}
+	return m, nil
+}
+
+func markerFilterFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "since",
+			Usage: `Only markers starting at or after this time (timestamp or duration ago, e.g. "2 days")`,
+		},
+		&cli.StringFlag{
+			Name:  "until",
+			Usage: `Only markers starting at or before this time (timestamp or duration ago)`,
+		},
+		&cli.StringFlag{
+			Name:  "type",
+			Usage: "Only markers of this type",
+		},
+		&cli.StringFlag{
+			Name:  "message-contains",
+			Usage: "Only markers whose message contains this text (case-insensitive)",
+		},
+		&cli.StringFlag{
+			Name:  "timezone",
+			Usage: `Timezone for parsing times without an offset (e.g. "Europe/Berlin", default UTC)`,
+		},
+	}
+}
+
+// markerFilter selects markers by start time, type and message.
+type markerFilter struct {
+	since, until *int64
+	typ          string
+	contains     string
+}
+
+func parseMarkerFilter(cmd *cli.Command, now time.Time) (markerFilter, error) {
+	loc, err := loadLocation(cmd)
+	if err != nil {
+		return markerFilter{}, err
+	}
+
+	f := markerFilter{typ: cmd.String("type"), contains: strings.ToLower(cmd.String("message-contains"))}
+	for _, bound := range []struct {
+		flag string
+		dst  **int64
+	}{{"since", &f.since}, {"until", &f.until}} {
+		v := cmd.String(bound.flag)
+		if v == "" {
+			continue
+		}
+		ts, err := parseRelativeTimestamp(v, loc, now)
+		if err != nil {
+			return markerFilter{}, fmt.Errorf("invalid %s %q: %w", bound.flag, v, err)
+		}
+		*bound.dst = &ts
+	}
+	return f, nil
+}
+
+func (f markerFilter) empty() bool {
+	return f.since == nil && f.until == nil && f.typ == "" && f.contains == ""
+}
+
+func (f markerFilter) apply(markers []api.Marker) []api.Marker {
+	out := []api.Marker{}
+	for _, m := range markers {
+		if f.since != nil && (m.StartTime == nil || *m.StartTime < *f.since) {
+			continue
+		}
+		if f.until != nil && (m.StartTime == nil || *m.StartTime > *f.until) {
+			continue
+		}
+		if f.typ != "" && m.Type != f.typ {
+			continue
+		}
+		if f.contains != "" && !strings.Contains(strings.ToLower(m.Message), f.contains) {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// parseRelativeTimestamp parses an absolute timestamp, or a time range such
+// as "2 hours" meaning that long before now.
+func parseRelativeTimestamp(s string, loc *time.Location, now time.Time) (int64, error) {
+	if ts, err := timefmt.ParseTimestamp(s, loc); err == nil {
+		return ts, nil
+	}
+	secs, err := timefmt.ParseTimeRange(s)
+	if err != nil {
+		return 0, fmt.Errorf("expected a timestamp or a duration such as \"2 hours\"")
+	}
+	return now.Unix() - int64(secs), nil
+}
+
+func formatMarkerTime(ts *int64) string {
+	if ts == nil {
+		return "-"
+	}
+	return time.Unix(*ts, 0).UTC().Format(time.RFC3339)
+}
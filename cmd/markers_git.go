@@ -0,0 +1,275 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/urfave/cli/v3"
+)
+
+// gitRef is a tag or commit that becomes a marker. Its fields are available
+// to --message-template and --url-template.
+type gitRef struct {
+	Tag      string
+	SHA      string
+	ShortSHA string
+	Subject  string
+	Time     int64
+}
+
+// gitMarkerResult reports what happened to the marker for one git ref.
+type gitMarkerResult struct {
+	Action    string `json:"action"`
+	Ref       string `json:"ref"`
+	SHA       string `json:"sha"`
+	StartTime int64  `json:"start_time"`
+	Message   string `json:"message"`
+	URL       string `json:"url,omitempty"`
+	ID        string `json:"id,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+func MarkersFromGitCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "markers-from-git",
+		Category: "Markers",
+		Usage:    "Create markers from tags or commits of a local git repository",
+		Description: `Backfill markers from git history. Select tags with --tags (a glob such
+as 'v*') or commits with --rev-range (anything git log accepts, such as
+'v1.0..main'). Each marker starts at the commit timestamp.
+
+Messages and URLs are Go templates with the fields .Tag, .SHA, .ShortSHA
+and .Subject (the commit subject). Markers that already exist with the
+same message and start time are skipped, so the command can be re-run.
+
+Examples:
+
+  hccli markers-from-git --repo . --dataset api --tags 'v*' \
+    --url-template 'https://github.com/acme/api/releases/tag/{{.Tag}}'
+  hccli markers-from-git --dataset api --rev-range 'main~20..main' \
+    --message-template '{{.ShortSHA}} {{.Subject}}' --dry-run`,
+		Flags: []cli.Flag{
+			DatasetFlag(),
+			&cli.StringFlag{
+				Name:  "repo",
+				Usage: "Path to the git repository",
+				Value: ".",
+			},
+			&cli.StringFlag{
+				Name:  "tags",
+				Usage: "Glob of tags to create markers for (e.g. 'v*')",
+			},
+			&cli.StringFlag{
+				Name:  "rev-range",
+				Usage: "Revision range of commits to create markers for (e.g. 'v1.0..main')",
+			},
+			&cli.StringFlag{
+				Name:  "type",
+				Usage: "Marker type",
+				Value: "deploy",
+			},
+			&cli.StringFlag{
+				Name:  "message-template",
+				Usage: `Template for marker messages (default "{{.Tag}}" for tags, "{{.Subject}}" for commits)`,
+			},
+			&cli.StringFlag{
+				Name:  "url-template",
+				Usage: "Template for marker URLs",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Print the markers that would be created without creating them",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			dataset := cmd.String("dataset")
+
+			var (
+				refs            []gitRef
+				err             error
+				defaultTemplate string
+			)
+			switch {
+			case cmd.String("tags") != "" && cmd.String("rev-range") != "":
+				return fmt.Errorf("--tags and --rev-range are mutually exclusive")
+			case cmd.String("tags") != "":
+				refs, err = gitTags(ctx, cmd.String("repo"), cmd.String("tags"))
+				defaultTemplate = "{{.Tag}}"
+			case cmd.String("rev-range") != "":
+				refs, err = gitCommits(ctx, cmd.String("repo"), cmd.String("rev-range"))
+				defaultTemplate = "{{.Subject}}"
+			default:
+				return fmt.Errorf("either --tags or --rev-range is required")
+			}
+			if err != nil {
+				return err
+			}
+
+			messageTmpl := cmd.String("message-template")
+			if messageTmpl == "" {
+				messageTmpl = defaultTemplate
+			}
+			message, err := template.New("message").Option("missingkey=error").Parse(messageTmpl)
+			if err != nil {
+				return fmt.Errorf("parsing message-template: %w", err)
+			}
+			url, err := template.New("url").Option("missingkey=error").Parse(cmd.String("url-template"))
+			if err != nil {
+				return fmt.Errorf("parsing url-template: %w", err)
+			}
+
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			existing, err := client.ListMarkers(ctx, dataset)
+			if err != nil {
+				return fmt.Errorf("listing markers: %w", err)
+			}
+			seen := map[string]bool{}
+			for _, m := range existing {
+				if m.StartTime != nil {
+					seen[markerKey(m.Message, *m.StartTime)] = true
+				}
+			}
+
+			results := make([]gitMarkerResult, 0, len(refs))
+			var failed int
+			for _, ref := range refs {
+				res := gitMarkerResult{Ref: ref.Tag, SHA: ref.SHA, StartTime: ref.Time}
+				if res.Ref == "" {
+					res.Ref = ref.ShortSHA
+				}
+				if res.Message, err = executeTemplate(message, ref); err != nil {
+					return err
+				}
+				if res.URL, err = executeTemplate(url, ref); err != nil {
+					return err
+				}
+
+				key := markerKey(res.Message, ref.Time)
+				switch {
+				case seen[key]:
+					res.Action = "skipped"
+				case dryRun(cmd):
+					res.Action = "would-create"
+				default:
+					res.Action = "created"
+					start := ref.Time
+					created, err := client.CreateMarker(ctx, dataset, &api.Marker{
+						Type:      cmd.String("type"),
+						Message:   res.Message,
+						URL:       res.URL,
+						StartTime: &start,
+					})
+					if err != nil {
+						res.Error = err.Error()
+						failed++
+					} else {
+						res.ID = created.ID
+					}
+				}
+				seen[key] = true
+				results = append(results, res)
+			}
+
+			if err := printJSON(results); err != nil {
+				return err
+			}
+			if failed > 0 {
+				return fmt.Errorf("failed to create %d of %d markers", failed, len(results))
+			}
+			return nil
+		},
+	}
+}
+
+func markerKey(message string, startTime int64) string {
+	return strconv.FormatInt(startTime, 10) + "\x00" + message
+}
+
+func executeTemplate(t *template.Template, ref gitRef) (string, error) {
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, ref); err != nil {
+		return "", fmt.Errorf("executing %s template for %s: %w", t.Name(), ref.SHA, err)
+	}
+	return buf.String(), nil
+}
+
+// git runs a git command in repo and returns its standard output.
+func git(ctx context.Context, repo string, args ...string) (string, error) {
+	c := exec.CommandContext(ctx, "git", append([]string{"-C", repo}, args...)...)
+	var stderr bytes.Buffer
+	c.Stderr = &stderr
+	out, err := c.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return "", fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return string(out), nil
+}
+
+// gitTags returns the tags matching pattern, oldest first, with the commit
+// each one points to. Annotated tags are peeled to their commit.
+func gitTags(ctx context.Context, repo, pattern string) ([]gitRef, error) {
+	out, err := git(ctx, repo, "for-each-ref", "--sort=creatordate",
+		"--format=%(refname:short)%00%(objectname)%00%(*objectname)%00%(committerdate:unix)%00%(*committerdate:unix)%00%(subject)%00%(*subject)",
+		"refs/tags/"+pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []gitRef
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		f := strings.Split(line, "\x00")
+		if len(f) != 7 {
+			return nil, fmt.Errorf("unexpected git for-each-ref output: %q", line)
+		}
+		sha, date, subject := f[1], f[3], f[5]
+		if f[2] != "" {
+			sha, date, subject = f[2], f[4], f[6]
+		}
+		ts, err := strconv.ParseInt(date, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("tag %s does not point to a commit", f[0])
+		}
+		refs = append(refs, gitRef{Tag: f[0], SHA: sha, ShortSHA: shortSHA(sha), Subject: subject, Time: ts})
+	}
+	return refs, nil
+}
+
+// gitCommits returns the commits in revRange, oldest first.
+func gitCommits(ctx context.Context, repo, revRange string) ([]gitRef, error) {
+	out, err := git(ctx, repo, "log", "--reverse", "--format=%H%x00%ct%x00%s", revRange, "--")
+	if err != nil {
+		return nil, err
+	}
+
+	var refs []gitRef
+	for _, line := range strings.Split(strings.TrimRight(out, "\n"), "\n") {
+		if line == "" {
+			continue
+		}
+		f := strings.SplitN(line, "\x00", 3)
+		if len(f) != 3 {
+			return nil, fmt.Errorf("unexpected git log output: %q", line)
+		}
+		ts, err := strconv.ParseInt(f[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected git log timestamp %q", f[1])
+		}
+		refs = append(refs, gitRef{SHA: f[0], ShortSHA: shortSHA(f[0]), Subject: f[2], Time: ts})
+	}
+	return refs, nil
+}
@@ -0,0 +1,414 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/manifest"
+	"github.com/urfave/cli/v3"
+)
+
+const manifestFormatHelp = `Manifest files (.yaml, .yml or .json) may be spread over any number of
+files in the directory tree. Each file declares resources for one dataset:
+
+  dataset: api
+  derived_columns:
+    - alias: sli_ok
+      expression: LT($status_code, 500)
+  slos:
+    - name: API availability
+      sli: {alias: sli_ok}
+      time_period_days: 30
+      target_per_million: 999000
+  burn_alerts:
+    - slo_name: API availability
+      alert_type: exhaustion_time
+      exhaustion_minutes: 60
+      description: page on-call
+      recipients: [{id: abc123}]
+  marker_settings:
+    - type: deploy
+      color: "#00ff00"
+  boards:
+    - name: API overview
+      type: flexible
+
+Resources are matched by stable keys rather than IDs: derived columns by
+alias, SLOs and boards by name, marker settings by type, and burn alerts
+by SLO name, alert type and description. Only fields set in the manifest
+are compared.`
+
+func PlanCmd() *cli.Command {
+	return &cli.Command{
+		Name:        "plan",
+		Category:    "Declarative",
+		Usage:       "Show changes needed to match a directory of resource manifests",
+		Description: manifestFormatHelp,
+		Flags:       planFlags(),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			changes, _, err := planManifests(ctx, client, cmd.String("dir"), cmd.Bool("prune"))
+			if err != nil {
+				return err
+			}
+
+			if cmd.String("output") == "text" {
+				fmt.Print(manifest.FormatText(changes))
+				return nil
+			}
+			return printJSON(map[string]any{
+				"summary": manifest.Summary(changes),
+				"changes": changes,
+			})
+		},
+	}
+}
+
+func ApplyCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "apply",
+		Category: "Declarative",
+		Usage:    "Apply a directory of resource manifests",
+		Description: `Compute the plan (see "hccli plan --help") and apply it in dependency
+order: derived columns, SLOs, burn alerts, marker settings and boards are
+created or updated in that order, then pruned resources are deleted in
+reverse order. Applying stops at the first failure.
+
+` + manifestFormatHelp,
+		Flags: planFlags(),
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			changes, sloIDs, err := planManifests(ctx, client, cmd.String("dir"), cmd.Bool("prune"))
+			if err != nil {
+				return err
+			}
+
+			text := cmd.String("output") == "text"
+			if text {
+				fmt.Fprint(os.Stderr, manifest.FormatText(changes))
+			}
+
+			a := &applier{client: client, sloIDs: sloIDs}
+
+			var applied []manifest.Change
+			for _, c := range changes {
+				if c.Action == manifest.ActionNoop {
+					continue
+				}
+				id, err := a.apply(ctx, c)
+				if err != nil {
+					if text {
+						fmt.Fprintf(os.Stderr, "✗ %s %s: %v\n", c.Action, c.Ref(), err)
+					} else {
+						_ = printJSON(applied)
+					}
+					return fmt.Errorf("%s %s: %w", c.Action, c.Ref(), err)
+				}
+				c.ID = id
+				applied = append(applied, c)
+				if text {
+					fmt.Fprintf(os.Stderr, "✓ %s %s\n", c.Action, c.Ref())
+				}
+			}
+
+			if text {
+				return nil
+			}
+			return printJSON(applied)
+		},
+	}
+}
+
+func planFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:     "dir",
+			Usage:    "Directory of resource manifests",
+			Required: true,
+		},
+		&cli.BoolFlag{
+			Name:  "prune",
+			Usage: "Delete live resources of the managed kinds that are not in the manifests",
+		},
+		&cli.StringFlag{
+			Name:  "output",
+			Usage: "Output format: json or text",
+			Value: "json",
+		},
+	}
+}
+
+// planManifests loads the manifests in dir, fetches the live resources they
+// cover and returns the plan along with the IDs of the live SLOs, keyed by
+// "dataset/name".
+func planManifests(ctx context.Context, client *api.Client, dir string, prune bool) ([]manifest.Change, map[string]string, error) {
+	desired, err := manifest.Load(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	live, sloIDs, err := fetchLiveResources(ctx, client, desired)
+	if err != nil {
+		return nil, nil, err
+	}
+	return manifest.Plan(desired, live, prune), sloIDs, nil
+}
+
+// fetchLiveResources fetches the live resources of every kind and dataset
+// that appears in desired. It also returns the IDs of the live SLOs of
+// every dataset with SLOs or burn alerts, keyed by "dataset/name", so that
+// burn alerts can reference SLOs the manifests do not declare.
+func fetchLiveResources(ctx context.Context, client *api.Client, desired []manifest.Resource) ([]manifest.Resource, map[string]string, error) {
+	scopes := map[string]map[string]bool{}
+	boards := false
+	for _, r := range desired {
+		if r.Kind == manifest.KindBoard {
+			boards = true
+			continue
+		}
+		if scopes[r.Dataset] == nil {
+			scopes[r.Dataset] = map[string]bool{}
+		}
+		scopes[r.Dataset][r.Kind] = true
+	}
+
+	var live []manifest.Resource
+	sloIDs := map[string]string{}
+	add := func(kind, dataset, key, id string, v any) error {
+		fields, err := manifest.ToFields(v)
+		if err != nil {
+			return err
+		}
+		live = append(live, manifest.Resource{Kind: kind, Dataset: dataset, Key: key, ID: id, Fields: fields})
+		return nil
+	}
+
+	for dataset, kinds := range scopes {
+		if kinds[manifest.KindDerivedColumn] {
+			dcs, err := client.ListDerivedColumns(ctx, dataset)
+			if err != nil {
+				return nil, nil, fmt.Errorf("listing derived columns for %s: %w", dataset, err)
+			}
+			for _, dc := range dcs {
+				if err := add(manifest.KindDerivedColumn, dataset, dc.Alias, dc.ID, dc); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+
+		if kinds[manifest.KindSLO] || kinds[manifest.KindBurnAlert] {
+			slos, err := client.ListSLOs(ctx, dataset)
+			if err != nil {
+				return nil, nil, fmt.Errorf("listing SLOs for %s: %w", dataset, err)
+			}
+			for _, slo := range slos {
+				sloIDs[dataset+"/"+slo.Name] = slo.ID
+				if kinds[manifest.KindSLO] {
+					if err := add(manifest.KindSLO, dataset, slo.Name, slo.ID, slo); err != nil {
+						return nil, nil, err
+					}
+				}
+				if !kinds[manifest.KindBurnAlert] {
+					continue
+				}
+				alerts, err := client.ListBurnAlerts(ctx, dataset, slo.ID)
+				if err != nil {
+					return nil, nil, fmt.Errorf("listing burn alerts for SLO %s: %w", slo.ID, err)
+				}
+				for _, ba := range alerts {
+					key := manifest.BurnAlertKey(slo.Name, ba.AlertType, ba.Description)
+					if err := add(manifest.KindBurnAlert, dataset, key, ba.ID, manifest.BurnAlert{SLOName: slo.Name, BurnAlert: ba}); err != nil {
+						return nil, nil, err
+					}
+				}
+			}
+		}
+
+		if kinds[manifest.KindMarkerSetting] {
+			settings, err := client.ListMarkerSettings(ctx, dataset)
+			if err != nil {
+				return nil, nil, fmt.Errorf("listing marker settings for %s: %w", dataset, err)
+			}
+			for _, ms := range settings {
+				if err := add(manifest.KindMarkerSetting, dataset, ms.Type, ms.ID, ms); err != nil {
+					return nil, nil, err
+				}
+			}
+		}
+	}
+
+	if boards {
+		list, err := client.ListBoards(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("listing boards: %w", err)
+		}
+		for _, b := range list {
+			full, err := client.GetBoard(ctx, b.ID)
+			if err != nil {
+				return nil, nil, fmt.Errorf("fetching board %s: %w", b.ID, err)
+			}
+			if err := add(manifest.KindBoard, "", full.Name, full.ID, full); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	return live, sloIDs, nil
+}
+
+// applier carries out plan changes. sloIDs maps "dataset/name" to SLO IDs so
+// that burn alerts can reference SLOs created earlier in the same run.
+type applier struct {
+	client *api.Client
+	sloIDs map[string]string
+}
+
+// fields returns the body to send for a change: the manifest fields for a
+// create, or the live resource with the manifest fields applied for an update.
+func (a *applier) fields(c manifest.Change) map[string]any {
+	out := map[string]any{}
+	if c.Live != nil {
+		for k, v := range c.Live.Fields {
+			out[k] = v
+		}
+	}
+	for k, v := range c.Desired.Fields {
+		out[k] = v
+	}
+	return out
+}
+
+// apply performs a single change and returns the ID of the affected resource.
+func (a *applier) apply(ctx context.Context, c manifest.Change) (string, error) {
+	if c.Action == manifest.ActionDelete {
+		return c.ID, a.delete(ctx, c)
+	}
+	create := c.Action == manifest.ActionCreate
+	fields := a.fields(c)
+
+	switch c.Kind {
+	case manifest.KindDerivedColumn:
+		var dc api.DerivedColumn
+		if err := manifest.Decode(fields, &dc); err != nil {
+			return "", err
+		}
+		var res *api.DerivedColumn
+		var err error
+		if create {
+			res, err = a.client.CreateDerivedColumn(ctx, c.Dataset, &dc)
+		} else {
+			res, err = a.client.UpdateDerivedColumn(ctx, c.Dataset, c.ID, &dc)
+		}
+		if err != nil {
+			return "", err
+		}
+		return res.ID, nil
+
+	case manifest.KindSLO:
+		var slo api.SLO
+		if err := manifest.Decode(fields, &slo); err != nil {
+			return "", err
+		}
+		var res *api.SLO
+		var err error
+		if create {
+			res, err = a.client.CreateSLO(ctx, c.Dataset, &slo)
+		} else {
+			res, err = a.client.UpdateSLO(ctx, c.Dataset, c.ID, &slo)
+		}
+		if err != nil {
+			return "", err
+		}
+		a.sloIDs[c.Dataset+"/"+c.Key] = res.ID
+		return res.ID, nil
+
+	case manifest.KindBurnAlert:
+		var mba manifest.BurnAlert
+		if err := manifest.Decode(fields, &mba); err != nil {
+			return "", err
+		}
+		ba := mba.BurnAlert
+		if ba.AlertType == "" {
+			ba.AlertType = "exhaustion_time"
+		}
+		var res *api.BurnAlert
+		var err error
+		if create {
+			sloID, ok := a.sloIDs[c.Dataset+"/"+mba.SLOName]
+			if !ok {
+				return "", fmt.Errorf("SLO %q not found in dataset %s", mba.SLOName, c.Dataset)
+			}
+			ba.SLO = &api.BurnAlertSLO{ID: sloID}
+			res, err = a.client.CreateBurnAlert(ctx, c.Dataset, &ba)
+		} else {
+			res, err = a.client.UpdateBurnAlert(ctx, c.Dataset, c.ID, &ba)
+		}
+		if err != nil {
+			return "", err
+		}
+		return res.ID, nil
+
+	case manifest.KindMarkerSetting:
+		var ms api.MarkerSetting
+		if err := manifest.Decode(fields, &ms); err != nil {
+			return "", err
+		}
+		var res *api.MarkerSetting
+		var err error
+		if create {
+			res, err = a.client.CreateMarkerSetting(ctx, c.Dataset, &ms)
+		} else {
+			res, err = a.client.UpdateMarkerSetting(ctx, c.Dataset, c.ID, &ms)
+		}
+		if err != nil {
+			return "", err
+		}
+		return res.ID, nil
+
+	case manifest.KindBoard:
+		var b api.Board
+		if err := manifest.Decode(fields, &b); err != nil {
+			return "", err
+		}
+		if b.Type == "" {
+			b.Type = "flexible"
+		}
+		var res *api.Board
+		var err error
+		if create {
+			res, err = a.client.CreateBoard(ctx, &b)
+		} else {
+			res, err = a.client.UpdateBoard(ctx, c.ID, &b)
+		}
+		if err != nil {
+			return "", err
+		}
+		return res.ID, nil
+	}
+	return "", fmt.Errorf("unknown resource kind %q", c.Kind)
+}
+
+func (a *applier) delete(ctx context.Context, c manifest.Change) error {
+	switch c.Kind {
+	case manifest.KindDerivedColumn:
+		return a.client.DeleteDerivedColumn(ctx, c.Dataset, c.ID)
+	case manifest.KindSLO:
+		return a.client.DeleteSLO(ctx, c.Dataset, c.ID)
+	case manifest.KindBurnAlert:
+		return a.client.DeleteBurnAlert(ctx, c.Dataset, c.ID)
+	case manifest.KindMarkerSetting:
+		return a.client.DeleteMarkerSetting(ctx, c.Dataset, c.ID)
+	case manifest.KindBoard:
+		return a.client.DeleteBoard(ctx, c.ID)
+	}
+	return fmt.Errorf("unknown resource kind %q", c.Kind)
+}
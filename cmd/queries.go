@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"strings"
-	"time"
 
 	"github.com/LarsEckart/hccli/api"
 	"github.com/LarsEckart/hccli/timefmt"
@@ -64,7 +63,10 @@ func GetQueryCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			query, err := client.GetQuery(ctx, cmd.String("dataset"), cmd.String("id"))
 			if err != nil {
@@ -126,7 +128,10 @@ func CreateQueryCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			ops := cmd.StringSlice("calculation-op")
 			cols := cmd.StringSlice("calculation-column")
@@ -164,13 +169,9 @@ func CreateQueryCmd() *cli.Command {
 				query.FilterCombination = v
 			}
 
-			loc := time.UTC
-			if tz := cmd.String("timezone"); tz != "" {
-				var err error
-				loc, err = time.LoadLocation(tz)
-				if err != nil {
-					return fmt.Errorf("invalid timezone %q: %w", tz, err)
-				}
+			loc, err := loadLocation(cmd)
+			if err != nil {
+				return err
 			}
 
 			if v := cmd.String("time-range"); v != "" {
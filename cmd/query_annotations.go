@@ -34,7 +34,10 @@ func CreateQueryAnnotationCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			annotation := &api.QueryAnnotation{
 				Name:    cmd.String("name"),
@@ -67,7 +70,10 @@ func ListQueryAnnotationsCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			annotations, err := client.ListQueryAnnotations(ctx, cmd.String("dataset"))
 			if err != nil {
@@ -97,7 +103,10 @@ func GetQueryAnnotationCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			annotation, err := client.GetQueryAnnotation(ctx, cmd.String("dataset"), cmd.String("id"))
 			if err != nil {
@@ -126,32 +135,48 @@ func UpdateQueryAnnotationCmd() *cli.Command {
 				Required: true,
 			},
 			&cli.StringFlag{
-				Name:     "name",
-				Usage:    "Annotation name",
-				Required: true,
+				Name:  "name",
+				Usage: "Annotation name",
 			},
 			&cli.StringFlag{
-				Name:     "query-id",
-				Usage:    "Query ID to annotate",
-				Required: true,
+				Name:  "query-id",
+				Usage: "Query ID to annotate",
 			},
 			&cli.StringFlag{
 				Name:  "description",
 				Usage: "Annotation description",
 			},
+			ShowDiffFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			if err := requireAnySet(cmd, "name", "query-id", "description"); err != nil {
+				return err
+			}
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
-			annotation := &api.QueryAnnotation{
-				Name:    cmd.String("name"),
-				QueryID: cmd.String("query-id"),
+			cur, err := client.GetQueryAnnotation(ctx, cmd.String("dataset"), cmd.String("id"))
+			if err != nil {
+				return err
 			}
-			if v := cmd.String("description"); v != "" {
-				annotation.Description = v
+			current := api.QueryAnnotation{Name: cur.Name, QueryID: cur.QueryID, Description: cur.Description}
+			annotation := current
+			if cmd.IsSet("name") {
+				annotation.Name = cmd.String("name")
+			}
+			if cmd.IsSet("query-id") {
+				annotation.QueryID = cmd.String("query-id")
+			}
+			if cmd.IsSet("description") {
+				annotation.Description = cmd.String("description")
+			}
+			if err := showUpdateDiff(cmd, current, annotation); err != nil {
+				return err
 			}
 
-			updated, err := client.UpdateQueryAnnotation(ctx, cmd.String("dataset"), cmd.String("id"), annotation)
+			updated, err := client.UpdateQueryAnnotation(ctx, cmd.String("dataset"), cmd.String("id"), &annotation)
 			if err != nil {
 				return err
 			}
@@ -177,9 +202,20 @@ func DeleteQueryAnnotationCmd() *cli.Command {
 				Usage:    "Query annotation ID",
 				Required: true,
 			},
+			YesFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			annotation, err := client.GetQueryAnnotation(ctx, cmd.String("dataset"), cmd.String("id"))
+			if err != nil {
+				return err
+			}
+			if err := confirmDelete(cmd, "query annotation", annotation.Name, annotation); err != nil {
+				return err
+			}
 			return client.DeleteQueryAnnotation(ctx, cmd.String("dataset"), cmd.String("id"))
 		},
 	}
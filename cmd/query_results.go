@@ -38,48 +38,67 @@ func CreateQueryResultCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 			dataset := cmd.String("dataset")
 			queryID := cmd.String("query-id")
 			pollInterval := time.Duration(cmd.Int("poll-interval")) * time.Second
 			timeout := time.Duration(cmd.Int("timeout")) * time.Second
 
-			if pollInterval < 1*time.Second {
-				pollInterval = 1 * time.Second
-			}
-
-			result, err := client.CreateQueryResult(ctx, dataset, queryID)
+			result, err := pollQueryResult(ctx, client, dataset, queryID, pollInterval, timeout)
 			if err != nil {
 				return err
 			}
 
-			if result.Complete {
-				warnIfEmptyResults(result, dataset)
-				return printJSON(result)
-			}
+			warnIfEmptyResults(result, dataset)
+			return printJSON(result)
+		},
+	}
+}
 
-			deadline := time.Now().Add(timeout)
-			for !result.Complete {
-				if time.Now().After(deadline) {
-					return fmt.Errorf("timed out waiting for query result %s after %s", result.ID, timeout)
-				}
+// pollQueryResult executes a query and polls until its result is complete or
+// the timeout elapses.
+func pollQueryResult(ctx context.Context, client *api.Client, dataset, queryID string, pollInterval, timeout time.Duration) (*api.QueryResult, error) {
+	return pollQueryResultLimited(ctx, client, nil, dataset, queryID, pollInterval, timeout)
+}
 
-				select {
-				case <-ctx.Done():
-					return ctx.Err()
-				case <-time.After(pollInterval):
-				}
+// pollQueryResultLimited is pollQueryResult with every request, including
+// each poll, waiting for limiter first. A nil limiter does not wait.
+func pollQueryResultLimited(ctx context.Context, client *api.Client, limiter *rateLimiter, dataset, queryID string, pollInterval, timeout time.Duration) (*api.QueryResult, error) {
+	if pollInterval < 1*time.Second {
+		pollInterval = 1 * time.Second
+	}
 
-				result, err = client.GetQueryResult(ctx, dataset, result.ID)
-				if err != nil {
-					return err
-				}
-			}
+	result, err := limited(ctx, limiter, func() (*api.QueryResult, error) {
+		return client.CreateQueryResult(ctx, dataset, queryID)
+	})
+	if err != nil {
+		return nil, err
+	}
 
-			warnIfEmptyResults(result, dataset)
-			return printJSON(result)
-		},
+	deadline := time.Now().Add(timeout)
+	for !result.Complete {
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for query result %s after %s", result.ID, timeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(pollInterval):
+		}
+
+		id := result.ID
+		result, err = limited(ctx, limiter, func() (*api.QueryResult, error) {
+			return client.GetQueryResult(ctx, dataset, id)
+		})
+		if err != nil {
+			return nil, err
+		}
 	}
+	return result, nil
 }
 
 func warnIfEmptyResults(result *api.QueryResult, dataset string) {
@@ -115,7 +134,10 @@ func GetQueryResultCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			result, err := client.GetQueryResult(ctx, cmd.String("dataset"), cmd.String("id"))
 			if err != nil {
@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/datafile"
+	"github.com/LarsEckart/hccli/schema"
+	"github.com/urfave/cli/v3"
+)
+
+type schemaDiffReport struct {
+	Base    string          `json:"base"`
+	Target  string          `json:"target"`
+	Changed bool            `json:"changed"`
+	Changes []schema.Change `json:"changes"`
+}
+
+func ExportSchemaCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "export-schema",
+		Category: "Columns",
+		Usage:    "Export the columns and derived columns of a dataset",
+		Description: `Write the columns (name, type, hidden, description) and derived columns
+of a dataset sorted by name, so that saved schemas can be committed and
+compared with diff-schema --snapshot. The format follows the file
+extension (.json, .yaml or .yml); without --file the schema is printed as
+JSON.
+
+Example:
+
+  hccli export-schema --dataset api --file schemas/api.yaml`,
+		Flags: []cli.Flag{
+			DatasetFlag(),
+			&cli.StringFlag{
+				Name:  "file",
+				Usage: "File to write the schema to",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			s, err := fetchSchema(ctx, client, cmd.String("dataset"))
+			if err != nil {
+				return err
+			}
+			path := cmd.String("file")
+			if path == "" {
+				return printJSON(s)
+			}
+			if _, err := datafile.Write(path, s); err != nil {
+				return fmt.Errorf("writing schema: %w", err)
+			}
+			return printJSON(map[string]any{
+				"dataset":         s.Dataset,
+				"path":            path,
+				"columns":         len(s.Columns),
+				"derived_columns": len(s.DerivedColumns),
+			})
+		},
+	}
+}
+
+func DiffSchemaCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "diff-schema",
+		Category: "Columns",
+		Usage:    "Compare the schema of a dataset with another dataset, environment or snapshot",
+		Description: `Report columns added, removed or with a changed type, and derived
+columns added, removed or with a changed expression, in --dataset
+compared with a baseline:
+
+  --snapshot FILE            a schema saved with export-schema
+  --other-dataset D          another dataset in the same environment
+  --other-api-key-env VAR    the same dataset (or --other-dataset) in the
+                             environment of the API key in $VAR
+
+Exits with code 2 when the schemas differ.
+
+Examples:
+
+  hccli diff-schema --dataset api --snapshot schemas/api.yaml --output text
+  hccli diff-schema --dataset api --other-api-key-env HONEYCOMB_STAGING_API_KEY`,
+		Flags: []cli.Flag{
+			DatasetFlag(),
+			&cli.StringFlag{
+				Name:  "snapshot",
+				Usage: "Schema file written by export-schema to compare against",
+			},
+			&cli.StringFlag{
+				Name:  "other-dataset",
+				Usage: "Dataset to compare against (default: --dataset with --other-api-key-env)",
+			},
+			&cli.StringFlag{
+				Name:  "other-api-key-env",
+				Usage: "Environment variable holding the API key of the environment to compare against",
+			},
+			&cli.StringFlag{
+				Name:  "other-api-url",
+				Usage: "API URL of the environment to compare against (default: --api-url)",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "Output format: json or text",
+				Value: "json",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			dataset := cmd.String("dataset")
+
+			var (
+				base     *schema.Schema
+				baseName string
+			)
+			switch {
+			case cmd.String("snapshot") != "":
+				if cmd.String("other-dataset") != "" || cmd.String("other-api-key-env") != "" {
+					return fmt.Errorf("--snapshot cannot be combined with --other-dataset or --other-api-key-env")
+				}
+				base = &schema.Schema{}
+				if err := datafile.Read(cmd.String("snapshot"), base); err != nil {
+					return fmt.Errorf("reading snapshot: %w", err)
+				}
+				base.Sort()
+				baseName = cmd.String("snapshot")
+			case cmd.String("other-dataset") != "" || cmd.String("other-api-key-env") != "":
+				otherClient := client
+				other := cmd.String("other-dataset")
+				if other == "" {
+					other = dataset
+				}
+				baseName = other
+				if env := cmd.String("other-api-key-env"); env != "" {
+					key := os.Getenv(env)
+					if key == "" {
+						return fmt.Errorf("environment variable %s is not set", env)
+					}
+					otherClient = api.NewClient(key, time.Duration(cmd.Int("timeout"))*time.Second)
+					otherClient.BaseURL = client.BaseURL
+					if url := cmd.String("other-api-url"); url != "" {
+						otherClient.BaseURL = url
+					}
+					baseName = other + " ($" + env + ")"
+				}
+				var err error
+				if base, err = fetchSchema(ctx, otherClient, other); err != nil {
+					return fmt.Errorf("fetching schema of %s: %w", baseName, err)
+				}
+			default:
+				return fmt.Errorf("one of --snapshot, --other-dataset or --other-api-key-env is required")
+			}
+
+			target, err := fetchSchema(ctx, client, dataset)
+			if err != nil {
+				return err
+			}
+
+			changes := schema.Diff(base, target)
+			if cmd.String("output") == "text" {
+				fmt.Print(schema.FormatText(baseName, dataset, changes))
+			} else if err := printJSON(schemaDiffReport{
+				Base:    baseName,
+				Target:  dataset,
+				Changed: len(changes) > 0,
+				Changes: changes,
+			}); err != nil {
+				return err
+			}
+			if len(changes) > 0 {
+				return cli.Exit(fmt.Sprintf("schema differs in %d place(s)", len(changes)), exitCodeFindings)
+			}
+			return nil
+		},
+	}
+}
+
+func fetchSchema(ctx context.Context, client *api.Client, dataset string) (*schema.Schema, error) {
+	columns, err := client.ListColumns(ctx, dataset)
+	if err != nil {
+		return nil, fmt.Errorf("listing columns: %w", err)
+	}
+	derived, err := client.ListDerivedColumns(ctx, dataset)
+	if err != nil {
+		return nil, fmt.Errorf("listing derived columns: %w", err)
+	}
+	return schema.New(dataset, columns, derived), nil
+}
@@ -1,19 +1,46 @@
 package cmd
 
 import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/LarsEckart/hccli/api"
 	"github.com/urfave/cli/v3"
 )
 
-func newClient(cmd *cli.Command) *api.Client {
+// exitCodeFindings is the exit code for commands that complete successfully
+// but report a condition a CI job should act on, such as drift or failing
+// SLOs. Errors exit with 1.
+const exitCodeFindings = 2
+
+// newClient returns a client for the API key and URL given to hccli. The
+// API key is only required here, so commands that work offline run without
+// one.
+func newClient(cmd *cli.Command) (*api.Client, error) {
+	key := cmd.String("api-key")
+	if key == "" {
+		return nil, fmt.Errorf("an API key is required: set --api-key or HONEYCOMB_API_KEY")
+	}
 	timeout := time.Duration(cmd.Int("timeout")) * time.Second
-	client := api.NewClient(cmd.String("api-key"), timeout)
+	client := api.NewClient(key, timeout)
 	if url := cmd.String("api-url"); url != "" {
 		client.BaseURL = url
 	}
-	return client
+	if dryRun(cmd) {
+		client.DryRun = os.Stderr
+	}
+	return client, nil
+}
+
+// dryRun reports whether --dry-run was given, either to hccli itself or
+// to a command with a --dry-run flag of its own.
+func dryRun(cmd *cli.Command) bool {
+	return cmd.Bool("dry-run") || cmd.Root().Bool("dry-run")
 }
 
 // IDFlag returns a standard ID flag.
@@ -33,3 +60,88 @@ func DatasetFlag() cli.Flag {
 		Required: true,
 	}
 }
+
+// YesFlag returns the flag that skips delete confirmations.
+func YesFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "yes",
+		Usage: "Delete without asking for confirmation",
+	}
+}
+
+// loadLocation returns the location named by the --timezone flag, or UTC when unset.
+func loadLocation(cmd *cli.Command) (*time.Location, error) {
+	tz := cmd.String("timezone")
+	if tz == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timezone %q: %w", tz, err)
+	}
+	return loc, nil
+}
+
+// parallel calls fn for every index in [0, n) with at most limit calls in flight.
+func parallel(n, limit int, fn func(i int)) {
+	if limit < 1 {
+		limit = 1
+	}
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			fn(i)
+		}()
+	}
+	wg.Wait()
+}
+
+// confirm asks a yes/no question on stderr and reads the answer from stdin.
+// Anything but "y" or "yes" declines.
+func confirm(question string) bool {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", question)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
+
+// isTerminal reports whether f is an interactive terminal: a character
+// device other than the null device.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	null, err := os.Stat(os.DevNull)
+	return err != nil || !os.SameFile(info, null)
+}
+
+// confirmDelete prints the resource about to be deleted on stderr and asks
+// for its name to be typed back. --yes and --dry-run skip the question;
+// otherwise the deletion is refused when stdin is not a terminal.
+func confirmDelete(cmd *cli.Command, kind, name string, resource any) error {
+	fmt.Fprintf(os.Stderr, "About to delete %s %s:\n", kind, name)
+	enc := json.NewEncoder(os.Stderr)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(resource); err != nil {
+		return err
+	}
+	if cmd.Bool("yes") || dryRun(cmd) {
+		return nil
+	}
+	if !isTerminal(os.Stdin) {
+		return fmt.Errorf("refusing to delete %s %s without a terminal to confirm on (use --yes)", kind, name)
+	}
+
+	fmt.Fprintf(os.Stderr, "Type %q to delete it: ", name)
+	answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	if strings.TrimSpace(answer) != name {
+		return fmt.Errorf("aborted: %s %s was not deleted", kind, name)
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/budget"
+	"github.com/urfave/cli/v3"
+)
+
+// Example thresholds explained when neither flags nor existing burn alerts
+// provide any: the fast and slow windows from the SRE workbook.
+var (
+	defaultExhaustionMinutes = []int{60, 240, 1440}
+	defaultBudgetRates       = []budgetRateExplanation{
+		{WindowMinutes: 60, DecreasePerMillion: 20000},
+		{WindowMinutes: 360, DecreasePerMillion: 50000},
+	}
+)
+
+type sloBudgetReport struct {
+	SLOID                  string  `json:"slo_id,omitempty"`
+	Name                   string  `json:"name,omitempty"`
+	TargetPerMillion       int     `json:"target_per_million"`
+	TargetPercent          float64 `json:"target_percent"`
+	TimePeriodDays         int     `json:"time_period_days"`
+	AllowedBadFraction     float64 `json:"allowed_bad_fraction"`
+	AllowedBadPerMillion   int     `json:"allowed_bad_per_million"`
+	AllowedDowntime        string  `json:"allowed_downtime"`
+	AllowedDowntimeSeconds int64   `json:"allowed_downtime_seconds"`
+	BurnRate               float64 `json:"burn_rate"`
+	BudgetConsumedPercent  float64 `json:"budget_consumed_percent"`
+	BudgetRemainingPercent float64 `json:"budget_remaining_percent"`
+	BudgetPerDayPercent    float64 `json:"budget_per_day_percent"`
+	TimeToExhaustion       string  `json:"time_to_exhaustion,omitempty"`
+	ProjectedExhaustion    string  `json:"projected_exhaustion,omitempty"`
+
+	ExhaustionTime []exhaustionExplanation `json:"exhaustion_time"`
+	BudgetRate     []budgetRateExplanation `json:"budget_rate"`
+}
+
+type exhaustionExplanation struct {
+	Description       string  `json:"description,omitempty"`
+	ExhaustionMinutes int     `json:"exhaustion_minutes"`
+	BurnRate          float64 `json:"burn_rate"`
+	Meaning           string  `json:"meaning"`
+}
+
+type budgetRateExplanation struct {
+	Description        string  `json:"description,omitempty"`
+	WindowMinutes      int     `json:"window_minutes"`
+	DecreasePerMillion int     `json:"decrease_per_million"`
+	BurnRate           float64 `json:"burn_rate"`
+	Meaning            string  `json:"meaning"`
+}
+
+func SLOBudgetCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "slo-budget",
+		Category: "SLOs",
+		Usage:    "Calculate the error budget of an SLO and explain burn alert thresholds",
+		Description: `Print the error budget implied by an SLO target and time period: the
+allowed fraction of bad events, the equivalent full-outage downtime, how
+much budget is consumed and remaining, and when it runs out at the
+current burn rate.
+
+Pass --target-per-million and --time-period-days to explore values before
+creating an SLO, or --dataset and --id to use an existing SLO with its
+live compliance data. Burn rates are multiples of the sustainable rate:
+1 spends exactly the whole budget over the time period.
+
+The report also translates burn alert thresholds into burn rates:
+
+  exhaustion_minutes  an exhaustion_time alert fires when the remaining
+                      budget is projected to run out within this many
+                      minutes at the recent burn rate
+  decrease_per_million
+                      a budget_rate alert fires when the budget drops by
+                      this many parts per million (10000 = 1%) within
+                      its window
+
+For an existing SLO its burn alerts are explained; --exhaustion-minutes and
+--budget-rate-window-minutes/--budget-rate-decrease-per-million add more.
+
+Examples:
+
+  hccli slo-budget --target-per-million 999000 --time-period-days 30
+  hccli slo-budget --dataset api --id slo-1 --output text`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "dataset",
+				Usage: "Dataset slug of an existing SLO",
+			},
+			&cli.StringFlag{
+				Name:  "id",
+				Usage: "ID of an existing SLO",
+			},
+			&cli.IntFlag{
+				Name:  "target-per-million",
+				Usage: "Target success rate per million (e.g. 999000 = 99.9%)",
+			},
+			&cli.IntFlag{
+				Name:  "time-period-days",
+				Usage: "Time period in days over which the SLO is evaluated",
+			},
+			&cli.FloatFlag{
+				Name:  "burn-rate",
+				Usage: "Burn rate to project with (default: the SLO's current burn rate, or 1)",
+			},
+			&cli.FloatFlag{
+				Name:  "budget-remaining",
+				Usage: "Percentage of budget remaining to project from (default: the SLO's current value, or 100)",
+			},
+			&cli.IntSliceFlag{
+				Name:  "exhaustion-minutes",
+				Usage: "Exhaustion minutes to explain; repeatable",
+			},
+			&cli.IntFlag{
+				Name:  "budget-rate-window-minutes",
+				Usage: "Budget rate window to explain",
+			},
+			&cli.IntFlag{
+				Name:  "budget-rate-decrease-per-million",
+				Usage: "Budget rate decrease threshold to explain",
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "Output format: json or text",
+				Value: "json",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			report := sloBudgetReport{BurnRate: 1, BudgetRemainingPercent: 100}
+			var alerts []api.BurnAlert
+
+			switch {
+			case cmd.IsSet("id"):
+				if cmd.String("dataset") == "" {
+					return fmt.Errorf("--dataset is required with --id")
+				}
+				client, err := newClient(cmd)
+				if err != nil {
+					return err
+				}
+				slo, err := client.GetSLODetailed(ctx, cmd.String("dataset"), cmd.String("id"))
+				if err != nil {
+					return err
+				}
+				report.SLOID = slo.ID
+				report.Name = slo.Name
+				report.TargetPerMillion = slo.TargetPerMillion
+				report.TimePeriodDays = slo.TimePeriodDays
+				if slo.BurnRate != nil {
+					report.BurnRate = *slo.BurnRate
+				}
+				if slo.BudgetRemaining != nil {
+					report.BudgetRemainingPercent = *slo.BudgetRemaining
+				}
+				alerts, err = client.ListBurnAlerts(ctx, cmd.String("dataset"), slo.ID)
+				if err != nil {
+					return fmt.Errorf("listing burn alerts: %w", err)
+				}
+			case cmd.IsSet("target-per-million") && cmd.IsSet("time-period-days"):
+				report.TargetPerMillion = int(cmd.Int("target-per-million"))
+				report.TimePeriodDays = int(cmd.Int("time-period-days"))
+			default:
+				return fmt.Errorf("either --dataset and --id, or --target-per-million and --time-period-days are required")
+			}
+			if err := validateBudgetThresholds(cmd); err != nil {
+				return err
+			}
+			if cmd.IsSet("burn-rate") {
+				report.BurnRate = cmd.Float("burn-rate")
+			}
+			if cmd.IsSet("budget-remaining") {
+				report.BudgetRemainingPercent = cmd.Float("budget-remaining")
+			}
+
+			slo := budget.SLO{TargetPerMillion: report.TargetPerMillion, TimePeriodDays: report.TimePeriodDays}
+			if err := slo.Validate(); err != nil {
+				return err
+			}
+			fillBudgetReport(&report, slo, alerts, cmd, time.Now())
+
+			if cmd.String("output") == "text" {
+				printSLOBudgetText(report)
+				return nil
+			}
+			return printJSON(report)
+		},
+	}
+}
+
+// validateBudgetThresholds rejects thresholds that no burn alert can have;
+// a zero window or zero minutes would mean an infinite burn rate.
+func validateBudgetThresholds(cmd *cli.Command) error {
+	for _, m := range cmd.IntSlice("exhaustion-minutes") {
+		if m <= 0 {
+			return fmt.Errorf("--exhaustion-minutes must be positive, got %d", m)
+		}
+	}
+	if cmd.IsSet("budget-rate-window-minutes") != cmd.IsSet("budget-rate-decrease-per-million") {
+		return fmt.Errorf("--budget-rate-window-minutes and --budget-rate-decrease-per-million must be given together")
+	}
+	if cmd.IsSet("budget-rate-window-minutes") && (cmd.Int("budget-rate-window-minutes") <= 0 || cmd.Int("budget-rate-decrease-per-million") <= 0) {
+		return fmt.Errorf("--budget-rate-window-minutes and --budget-rate-decrease-per-million must be positive")
+	}
+	return nil
+}
+
+func fillBudgetReport(report *sloBudgetReport, slo budget.SLO, alerts []api.BurnAlert, cmd *cli.Command, now time.Time) {
+	remaining := report.BudgetRemainingPercent / 100
+
+	report.TargetPercent = float64(slo.TargetPerMillion) / 10000
+	report.AllowedBadFraction = slo.AllowedBadFraction()
+	report.AllowedBadPerMillion = budget.PerMillion - slo.TargetPerMillion
+	downtime := slo.AllowedDowntime()
+	report.AllowedDowntime = budget.FormatDuration(downtime)
+	report.AllowedDowntimeSeconds = int64(downtime / time.Second)
+	report.BudgetConsumedPercent = 100 - report.BudgetRemainingPercent
+	report.BudgetPerDayPercent = report.BurnRate * 100 / float64(slo.TimePeriodDays)
+	if d, ok := slo.TimeToExhaustion(remaining, report.BurnRate); ok {
+		report.TimeToExhaustion = budget.FormatDuration(d)
+		report.ProjectedExhaustion = now.Add(d).UTC().Format(time.RFC3339)
+	}
+
+	explainExhaustion := func(description string, minutes int) {
+		e := exhaustionExplanation{Description: description, ExhaustionMinutes: minutes}
+		if remaining <= 0 {
+			// Any burn at all runs out a budget that is already gone.
+			e.Meaning = "budget exhausted; exhaustion_time alerts fire at any burn rate"
+		} else {
+			e.BurnRate = slo.BurnRateForExhaustion(remaining, minutes)
+			e.Meaning = fmt.Sprintf("fires when the remaining %.1f%% of budget would run out within %s, i.e. at a burn rate of %.1fx or more",
+				report.BudgetRemainingPercent, budget.FormatDuration(time.Duration(minutes)*time.Minute), e.BurnRate)
+		}
+		report.ExhaustionTime = append(report.ExhaustionTime, e)
+	}
+	explainBudgetRate := func(description string, window, perMillion int) {
+		rate := slo.BurnRateForDecrease(perMillion, time.Duration(window)*time.Minute)
+		report.BudgetRate = append(report.BudgetRate, budgetRateExplanation{
+			Description:        description,
+			WindowMinutes:      window,
+			DecreasePerMillion: perMillion,
+			BurnRate:           rate,
+			Meaning: fmt.Sprintf("fires when %.2f%% of the budget is spent within %s, i.e. at a burn rate of %.1fx or more",
+				float64(perMillion)/10000, budget.FormatDuration(time.Duration(window)*time.Minute), rate),
+		})
+	}
+
+	for _, a := range alerts {
+		switch {
+		case a.ExhaustionMinutes != nil && *a.ExhaustionMinutes > 0:
+			explainExhaustion(a.Description, *a.ExhaustionMinutes)
+		case a.BudgetRateWindowMinutes != nil && *a.BudgetRateWindowMinutes > 0 && a.BudgetRateDecreaseThresholdPerMillion != nil:
+			explainBudgetRate(a.Description, *a.BudgetRateWindowMinutes, *a.BudgetRateDecreaseThresholdPerMillion)
+		}
+	}
+	for _, m := range cmd.IntSlice("exhaustion-minutes") {
+		explainExhaustion("", int(m))
+	}
+	if cmd.IsSet("budget-rate-window-minutes") {
+		explainBudgetRate("", int(cmd.Int("budget-rate-window-minutes")), int(cmd.Int("budget-rate-decrease-per-million")))
+	}
+
+	if len(report.ExhaustionTime) == 0 && len(report.BudgetRate) == 0 {
+		for _, m := range defaultExhaustionMinutes {
+			explainExhaustion("example", m)
+		}
+		for _, br := range defaultBudgetRates {
+			explainBudgetRate("example", br.WindowMinutes, br.DecreasePerMillion)
+		}
+	}
+	if report.ExhaustionTime == nil {
+		report.ExhaustionTime = []exhaustionExplanation{}
+	}
+	if report.BudgetRate == nil {
+		report.BudgetRate = []budgetRateExplanation{}
+	}
+}
+
+func printSLOBudgetText(r sloBudgetReport) {
+	w := os.Stdout
+	if r.Name != "" {
+		fmt.Fprintf(w, "SLO %s (%s)\n", r.Name, r.SLOID)
+	}
+	fmt.Fprintf(w, "Target:            %.4g%% over %d days\n", r.TargetPercent, r.TimePeriodDays)
+	fmt.Fprintf(w, "Allowed bad:       %.4g%% of events (%d per million)\n", r.AllowedBadFraction*100, r.AllowedBadPerMillion)
+	fmt.Fprintf(w, "Allowed downtime:  %s\n", r.AllowedDowntime)
+	fmt.Fprintf(w, "Burn rate:         %.2fx (%.2f%% of budget per day)\n", r.BurnRate, r.BudgetPerDayPercent)
+	fmt.Fprintf(w, "Budget consumed:   %.2f%%\n", r.BudgetConsumedPercent)
+	fmt.Fprintf(w, "Budget remaining:  %.2f%%\n", r.BudgetRemainingPercent)
+	if r.ProjectedExhaustion != "" {
+		fmt.Fprintf(w, "Exhausted in:      %s (%s)\n", r.TimeToExhaustion, r.ProjectedExhaustion)
+	} else {
+		fmt.Fprintln(w, "Exhausted in:      never at this burn rate")
+	}
+
+	if len(r.ExhaustionTime) > 0 {
+		fmt.Fprintln(w, "\nexhaustion_time alerts:")
+		for _, e := range r.ExhaustionTime {
+			fmt.Fprintf(w, "  exhaustion_minutes=%d%s: %s\n", e.ExhaustionMinutes, labelSuffix(e.Description), e.Meaning)
+		}
+	}
+	if len(r.BudgetRate) > 0 {
+		fmt.Fprintln(w, "\nbudget_rate alerts:")
+		for _, b := range r.BudgetRate {
+			fmt.Fprintf(w, "  window=%dm decrease_per_million=%d%s: %s\n", b.WindowMinutes, b.DecreasePerMillion, labelSuffix(b.Description), b.Meaning)
+		}
+	}
+}
+
+func labelSuffix(description string) string {
+	if description == "" {
+		return ""
+	}
+	return " (" + description + ")"
+}
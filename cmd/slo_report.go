@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/urfave/cli/v3"
+)
+
+// sloReportRow is one SLO in an slo-report.
+type sloReportRow struct {
+	Dataset          string   `json:"dataset"`
+	ID               string   `json:"id"`
+	Name             string   `json:"name"`
+	TargetPerMillion int      `json:"target_per_million"`
+	TargetPercent    float64  `json:"target_percent"`
+	TimePeriodDays   int      `json:"time_period_days"`
+	Compliance       *float64 `json:"compliance"`
+	BudgetRemaining  *float64 `json:"budget_remaining"`
+	BurnRate         *float64 `json:"burn_rate"`
+	Status           string   `json:"status"`
+	Failing          bool     `json:"failing"`
+	Error            string   `json:"error,omitempty"`
+}
+
+func SLOReportCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "slo-report",
+		Category: "SLOs",
+		Usage:    "Report compliance and error budget for SLOs across datasets",
+		Description: `Fetch detailed reporting data for every SLO in one or more datasets
+(all datasets when --dataset is omitted) and print them sorted by risk:
+least budget remaining first, then highest burn rate.
+
+An SLO is failing when its status is "failing" or its budget is exhausted.
+The command exits with code 2 when any reported SLO is failing, so it can
+gate a CI job.
+
+Examples:
+
+  hccli slo-report --dataset api --dataset web
+  hccli slo-report --below-budget 20% --output text`,
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:  "dataset",
+				Usage: "Dataset slug; repeat for several (default: every dataset)",
+			},
+			&cli.StringFlag{
+				Name:  "below-budget",
+				Usage: `Only report SLOs with less budget remaining than this (e.g. "20%")`,
+			},
+			&cli.IntFlag{
+				Name:  "concurrency",
+				Usage: "Maximum number of SLOs fetched at once",
+				Value: 8,
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "Output format: json or text",
+				Value: "json",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+
+			var threshold *float64
+			if v := cmd.String("below-budget"); v != "" {
+				pct, err := parsePercent(v)
+				if err != nil {
+					return fmt.Errorf("invalid below-budget %q: %w", v, err)
+				}
+				threshold = &pct
+			}
+
+			datasets := cmd.StringSlice("dataset")
+			if len(datasets) == 0 {
+				all, err := client.ListDatasets(ctx)
+				if err != nil {
+					return fmt.Errorf("listing datasets: %w", err)
+				}
+				for _, ds := range all {
+					datasets = append(datasets, ds.Slug)
+				}
+			}
+
+			var rows []sloReportRow
+			for _, ds := range datasets {
+				slos, err := client.ListSLOs(ctx, ds)
+				if err != nil {
+					return fmt.Errorf("listing SLOs for %s: %w", ds, err)
+				}
+				for _, s := range slos {
+					rows = append(rows, sloReportRow{Dataset: ds, ID: s.ID, Name: s.Name})
+				}
+			}
+
+			var mu sync.Mutex
+			var failed int
+			parallel(len(rows), int(cmd.Int("concurrency")), func(i int) {
+				row := &rows[i]
+				slo, err := client.GetSLODetailed(ctx, row.Dataset, row.ID)
+				if err != nil {
+					row.Error = err.Error()
+					mu.Lock()
+					failed++
+					mu.Unlock()
+					return
+				}
+				row.TargetPerMillion = slo.TargetPerMillion
+				row.TargetPercent = float64(slo.TargetPerMillion) / 10000
+				row.TimePeriodDays = slo.TimePeriodDays
+				row.Compliance = slo.Compliance
+				row.BudgetRemaining = slo.BudgetRemaining
+				row.BurnRate = slo.BurnRate
+				row.Status = slo.Status
+				row.Failing = sloFailing(slo)
+			})
+
+			report := make([]sloReportRow, 0, len(rows))
+			anyFailing := false
+			for _, row := range rows {
+				if threshold != nil && row.Error == "" && (row.BudgetRemaining == nil || *row.BudgetRemaining >= *threshold) {
+					continue
+				}
+				anyFailing = anyFailing || row.Failing
+				report = append(report, row)
+			}
+			sortByRisk(report)
+
+			if cmd.String("output") == "text" {
+				printSLOReportText(report)
+			} else if err := printJSON(report); err != nil {
+				return err
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("failed to fetch %d SLO(s)", failed)
+			}
+			if anyFailing {
+				return cli.Exit("one or more SLOs are failing", exitCodeFindings)
+			}
+			return nil
+		},
+	}
+}
+
+// sloFailing reports whether a detailed SLO is failing or has exhausted its budget.
+func sloFailing(slo *api.SLO) bool {
+	if strings.EqualFold(slo.Status, "failing") {
+		return true
+	}
+	return slo.BudgetRemaining != nil && *slo.BudgetRemaining <= 0
+}
+
+// sortByRisk orders rows by budget remaining ascending, then burn rate
+// descending. Rows without reporting data sort last.
+func sortByRisk(rows []sloReportRow) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		bi, bj := rows[i].BudgetRemaining, rows[j].BudgetRemaining
+		if (bi == nil) != (bj == nil) {
+			return bi != nil
+		}
+		if bi != nil && *bi != *bj {
+			return *bi < *bj
+		}
+		ri, rj := rows[i].BurnRate, rows[j].BurnRate
+		if (ri == nil) != (rj == nil) {
+			return ri != nil
+		}
+		if ri != nil && *ri != *rj {
+			return *ri > *rj
+		}
+		return rows[i].Name < rows[j].Name
+	})
+}
+
+func printSLOReportText(rows []sloReportRow) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "DATASET\tNAME\tTARGET\tCOMPLIANCE\tBUDGET\tBURN RATE\tSTATUS")
+	for _, r := range rows {
+		status := r.Status
+		if r.Error != "" {
+			status = "error: " + r.Error
+		} else if r.Failing {
+			status = "FAILING"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%.4g%%\t%s\t%s\t%s\t%s\n",
+			r.Dataset, r.Name, r.TargetPercent,
+			optionalPercent(r.Compliance), optionalPercent(r.BudgetRemaining),
+			optionalFloat(r.BurnRate), status)
+	}
+	_ = w.Flush()
+}
+
+func optionalPercent(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%.2f%%", *v)
+}
+
+func optionalFloat(v *float64) string {
+	if v == nil {
+		return "-"
+	}
+	return strconv.FormatFloat(*v, 'f', 2, 64)
+}
+
+// parsePercent parses "20%" or "20" as 20.
+func parsePercent(s string) (float64, error) {
+	s = strings.TrimSuffix(strings.TrimSpace(s), "%")
+	v, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return 0, fmt.Errorf("expected a percentage such as 20%%")
+	}
+	return v, nil
+}
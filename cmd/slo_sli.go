@@ -0,0 +1,233 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/LarsEckart/hccli/api"
+)
+
+// sliVerification counts how the SLI derived column evaluated over the
+// verification window.
+type sliVerification struct {
+	Good              int64
+	Bad               int64
+	Ineligible        int64
+	CompliancePercent *float64
+}
+
+// filterFunctions maps query filter operators to derived column functions.
+var filterFunctions = map[string]string{
+	"=":                   "EQUALS",
+	">":                   "GT",
+	">=":                  "GTE",
+	"<":                   "LT",
+	"<=":                  "LTE",
+	"starts-with":         "STARTS_WITH",
+	"ends-with":           "ENDS_WITH",
+	"contains":            "CONTAINS",
+	"exists":              "EXISTS",
+	"in":                  "IN",
+	"!=":                  "!EQUALS",
+	"does-not-start-with": "!STARTS_WITH",
+	"does-not-end-with":   "!ENDS_WITH",
+	"does-not-contain":    "!CONTAINS",
+	"does-not-exist":      "!EXISTS",
+	"not-in":              "!IN",
+}
+
+var bareColumnName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.]*$`)
+
+// sliExpression builds a derived column expression that is true for good
+// events, false for bad events and null for events that are not eligible.
+// Conditions within good and eligible are combined with AND.
+func sliExpression(good, eligible []string) (string, error) {
+	goodExpr, err := conditionsExpression(good)
+	if err != nil {
+		return "", fmt.Errorf("sli-good: %w", err)
+	}
+	if len(eligible) == 0 {
+		return goodExpr, nil
+	}
+	eligibleExpr, err := conditionsExpression(eligible)
+	if err != nil {
+		return "", fmt.Errorf("sli-eligible: %w", err)
+	}
+	return fmt.Sprintf("IF(%s, %s)", eligibleExpr, goodExpr), nil
+}
+
+func conditionsExpression(conditions []string) (string, error) {
+	exprs := make([]string, 0, len(conditions))
+	for _, c := range conditions {
+		f, err := parseFilter(c)
+		if err != nil {
+			return "", err
+		}
+		expr, err := filterExpression(f)
+		if err != nil {
+			return "", err
+		}
+		exprs = append(exprs, expr)
+	}
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return "AND(" + strings.Join(exprs, ", ") + ")", nil
+}
+
+// filterExpression translates a query filter into a derived column expression.
+func filterExpression(f api.QueryFilter) (string, error) {
+	fn, ok := filterFunctions[f.Op]
+	if !ok {
+		return "", fmt.Errorf("unsupported operator %q", f.Op)
+	}
+	negate := strings.HasPrefix(fn, "!")
+	fn = strings.TrimPrefix(fn, "!")
+
+	args := []string{columnReference(f.Column)}
+	if !noValueOps[f.Op] {
+		value := fmt.Sprint(f.Value)
+		if fn == "IN" {
+			for _, v := range strings.Split(value, ",") {
+				args = append(args, literal(strings.TrimSpace(v)))
+			}
+		} else {
+			args = append(args, literal(value))
+		}
+	}
+
+	expr := fn + "(" + strings.Join(args, ", ") + ")"
+	if negate {
+		expr = "NOT(" + expr + ")"
+	}
+	return expr, nil
+}
+
+// columnReference returns the expression syntax for a column, quoting names
+// that are not plain identifiers.
+func columnReference(name string) string {
+	if bareColumnName.MatchString(name) {
+		return "$" + name
+	}
+	return "$" + strconv.Quote(name)
+}
+
+// literal returns value as a number or boolean literal when it is one, and
+// as a quoted string otherwise.
+func literal(value string) string {
+	if unquoted, err := strconv.Unquote(value); err == nil {
+		return strconv.Quote(unquoted)
+	}
+	if _, err := strconv.ParseFloat(value, 64); err == nil {
+		return value
+	}
+	if value == "true" || value == "false" {
+		return value
+	}
+	return strconv.Quote(value)
+}
+
+// sliAliasFor derives a derived column alias from an SLO name.
+func sliAliasFor(name string) string {
+	var b strings.Builder
+	b.WriteString("sli_")
+	underscore := true
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			underscore = false
+		} else if !underscore {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// verifySLI runs a query counting the values of the SLI column over the last
+// day.
+func verifySLI(ctx context.Context, client *api.Client, dataset, alias string, timeout time.Duration) (*sliVerification, error) {
+	q, err := client.CreateQuery(ctx, dataset, &api.Query{
+		Breakdowns:   []string{alias},
+		Calculations: []api.Calculation{{Op: "COUNT"}},
+		TimeRange:    86400,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating verification query: %w", err)
+	}
+	result, err := pollQueryResult(ctx, client, dataset, q.ID, time.Second, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("running verification query: %w", err)
+	}
+
+	v := &sliVerification{}
+	for _, row := range result.Data.Results {
+		data, _ := row["data"].(map[string]any)
+		count, _ := data["COUNT"].(float64)
+		switch data[alias] {
+		case true:
+			v.Good += int64(count)
+		case false:
+			v.Bad += int64(count)
+		default:
+			v.Ineligible += int64(count)
+		}
+	}
+	if eligible := v.Good + v.Bad; eligible > 0 {
+		pct := float64(v.Good) / float64(eligible) * 100
+		v.CompliancePercent = &pct
+	}
+	return v, nil
+}
+
+// scaffoldSLI creates the SLI derived column from --sli-good and
+// --sli-eligible, verifies it against the last day of events and reports the
+// measured compliance on stderr. The derived column is deleted again when
+// verification fails or no events are eligible; otherwise the returned
+// function deletes it, for when creating the SLO fails.
+func scaffoldSLI(ctx context.Context, client *api.Client, dataset, alias string, good, eligible []string, targetPerMillion int, timeout time.Duration) (func(), error) {
+	expr, err := sliExpression(good, eligible)
+	if err != nil {
+		return nil, err
+	}
+	col, err := client.CreateDerivedColumn(ctx, dataset, &api.DerivedColumn{
+		Alias:       alias,
+		Expression:  expr,
+		Description: "SLI generated by hccli create-slo",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("creating SLI derived column: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Created derived column %s (%s): %s\n", alias, col.ID, expr)
+	undo := func() {
+		// The context may be what failed, so cleanup gets its own.
+		if err := client.DeleteDerivedColumn(context.WithoutCancel(ctx), dataset, col.ID); err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Could not delete derived column %s: %v\n", alias, err)
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Deleted derived column %s\n", alias)
+	}
+
+	v, err := verifySLI(ctx, client, dataset, alias, timeout)
+	if err != nil {
+		undo()
+		return nil, err
+	}
+	fmt.Fprintf(os.Stderr, "Last day: %d good, %d bad, %d not eligible\n", v.Good, v.Bad, v.Ineligible)
+	if v.CompliancePercent == nil {
+		undo()
+		return nil, fmt.Errorf("no eligible events in the last day; check --sli-good and --sli-eligible")
+	}
+
+	target := float64(targetPerMillion) / 10000
+	fmt.Fprintf(os.Stderr, "Historical compliance: %.4f%% (target %.4g%%)\n", *v.CompliancePercent, target)
+	if *v.CompliancePercent < target {
+		fmt.Fprintln(os.Stderr, "⚠️  The last day would not have met the target; the SLO will start out burning budget")
+	}
+	return undo, nil
+}
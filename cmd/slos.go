@@ -4,8 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
 	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/budget"
 	"github.com/urfave/cli/v3"
 )
 
@@ -18,7 +20,10 @@ func ListSLOsCmd() *cli.Command {
 			DatasetFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 			slos, err := client.ListSLOs(ctx, cmd.String("dataset"))
 			if err != nil {
 				return err
@@ -42,11 +47,11 @@ func GetSLOCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
-			var (
-				slo *api.SLO
-				err error
-			)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			var slo *api.SLO
 			if cmd.Bool("detailed") {
 				slo, err = client.GetSLODetailed(ctx, cmd.String("dataset"), cmd.String("id"))
 			} else {
@@ -65,6 +70,17 @@ func CreateSLOCmd() *cli.Command {
 		Name:     "create-slo",
 		Category: "SLOs",
 		Usage:    "Create an SLO",
+		Description: `Create an SLO on an existing SLI derived column (--sli-alias), or let
+the command create the SLI from conditions:
+
+  hccli create-slo --dataset api --name "API availability" \
+    --sli-good "status_code < 500" --sli-eligible "service.name = api" \
+    --time-period-days 30 --target-per-million 999000
+
+This creates the derived column IF(EQUALS($service.name, "api"),
+LT($status_code, 500)), counts how it evaluated over the last day and
+reports the historical compliance before creating the SLO. The derived
+column is removed again when no events in the last day were eligible.`,
 		Flags: []cli.Flag{
 			DatasetFlag(),
 			&cli.StringFlag{
@@ -77,9 +93,21 @@ func CreateSLOCmd() *cli.Command {
 				Usage: "SLO description",
 			},
 			&cli.StringFlag{
-				Name:     "sli-alias",
-				Usage:    "Alias of the derived column to use as the SLI",
-				Required: true,
+				Name:  "sli-alias",
+				Usage: "Alias of the derived column to use as the SLI (generated from the name with --sli-good)",
+			},
+			&cli.StringSliceFlag{
+				Name:  "sli-good",
+				Usage: `Condition for good events in "column op [value]" form; repeat to require several (e.g. --sli-good "status_code < 500")`,
+			},
+			&cli.StringSliceFlag{
+				Name:  "sli-eligible",
+				Usage: `Condition for events the SLI applies to; repeat to require several (e.g. --sli-eligible "service.name = api")`,
+			},
+			&cli.IntFlag{
+				Name:  "query-timeout",
+				Usage: "Maximum seconds to wait for the SLI verification query",
+				Value: 60,
 			},
 			&cli.IntFlag{
 				Name:     "time-period-days",
@@ -97,25 +125,60 @@ func CreateSLOCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
-			slo := &api.SLO{
-				Name:             cmd.String("name"),
-				Description:      cmd.String("description"),
-				SLI:              api.SLOSLI{Alias: cmd.String("sli-alias")},
-				TimePeriodDays:   int(cmd.Int("time-period-days")),
-				TargetPerMillion: int(cmd.Int("target-per-million")),
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
 			}
 
+			target := budget.SLO{
+				TargetPerMillion: int(cmd.Int("target-per-million")),
+				TimePeriodDays:   int(cmd.Int("time-period-days")),
+			}
+			if err := target.Validate(); err != nil {
+				return err
+			}
+			var tags []api.Tag
 			if tj := cmd.String("tags-json"); tj != "" {
-				var tags []api.Tag
 				if err := json.Unmarshal([]byte(tj), &tags); err != nil {
 					return fmt.Errorf("parsing tags-json: %w", err)
 				}
-				slo.Tags = tags
+			}
+
+			alias := cmd.String("sli-alias")
+			good := cmd.StringSlice("sli-good")
+			// deleteSLI removes a derived column generated below if the SLO
+			// cannot be created.
+			deleteSLI := func() {}
+			switch {
+			case len(good) > 0:
+				if alias == "" {
+					alias = sliAliasFor(cmd.String("name"))
+				}
+				timeout := time.Duration(cmd.Int("query-timeout")) * time.Second
+				undo, err := scaffoldSLI(ctx, client, cmd.String("dataset"), alias, good, cmd.StringSlice("sli-eligible"),
+					target.TargetPerMillion, timeout)
+				if err != nil {
+					return err
+				}
+				deleteSLI = undo
+			case len(cmd.StringSlice("sli-eligible")) > 0:
+				return fmt.Errorf("--sli-eligible requires --sli-good")
+			case alias == "":
+				return fmt.Errorf("either --sli-alias or --sli-good is required")
+			}
+
+			slo := &api.SLO{
+				Name:             cmd.String("name"),
+				Description:      cmd.String("description"),
+				SLI:              api.SLOSLI{Alias: alias},
+				TimePeriodDays:   target.TimePeriodDays,
+				TargetPerMillion: target.TargetPerMillion,
+				Tags:             tags,
 			}
 
 			created, err := client.CreateSLO(ctx, cmd.String("dataset"), slo)
 			if err != nil {
+				deleteSLI()
 				return err
 			}
 			return printJSON(created)
@@ -128,57 +191,91 @@ func UpdateSLOCmd() *cli.Command {
 		Name:     "update-slo",
 		Category: "SLOs",
 		Usage:    "Update an SLO by ID",
+		Description: `Only the fields given as flags change; the others keep their current
+value.
+
+Example:
+
+  hccli update-slo --dataset api --id ID --target-per-million 995000 --show-diff`,
 		Flags: []cli.Flag{
 			DatasetFlag(),
 			IDFlag("id", "SLO ID"),
 			&cli.StringFlag{
-				Name:     "name",
-				Usage:    "SLO name",
-				Required: true,
+				Name:  "name",
+				Usage: "SLO name",
 			},
 			&cli.StringFlag{
 				Name:  "description",
 				Usage: "SLO description",
 			},
 			&cli.StringFlag{
-				Name:     "sli-alias",
-				Usage:    "Alias of the derived column to use as the SLI",
-				Required: true,
+				Name:  "sli-alias",
+				Usage: "Alias of the derived column to use as the SLI",
 			},
 			&cli.IntFlag{
-				Name:     "time-period-days",
-				Usage:    "Time period in days over which the SLO is evaluated",
-				Required: true,
+				Name:  "time-period-days",
+				Usage: "Time period in days over which the SLO is evaluated",
 			},
 			&cli.IntFlag{
-				Name:     "target-per-million",
-				Usage:    "Target success rate per million (e.g. 999000 = 99.9%)",
-				Required: true,
+				Name:  "target-per-million",
+				Usage: "Target success rate per million (e.g. 999000 = 99.9%)",
 			},
 			&cli.StringFlag{
 				Name:  "tags-json",
 				Usage: `JSON array of tags, e.g. '[{"key":"team","value":"blue"}]'`,
 			},
+			ShowDiffFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
-			slo := &api.SLO{
-				Name:             cmd.String("name"),
-				Description:      cmd.String("description"),
-				SLI:              api.SLOSLI{Alias: cmd.String("sli-alias")},
-				TimePeriodDays:   int(cmd.Int("time-period-days")),
-				TargetPerMillion: int(cmd.Int("target-per-million")),
+			if err := requireAnySet(cmd, "name", "description", "sli-alias", "time-period-days", "target-per-million", "tags-json"); err != nil {
+				return err
+			}
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
 			}
 
-			if tj := cmd.String("tags-json"); tj != "" {
+			cur, err := client.GetSLO(ctx, cmd.String("dataset"), cmd.String("id"))
+			if err != nil {
+				return err
+			}
+			current := api.SLO{
+				Name:             cur.Name,
+				Description:      cur.Description,
+				SLI:              cur.SLI,
+				TimePeriodDays:   cur.TimePeriodDays,
+				TargetPerMillion: cur.TargetPerMillion,
+				Tags:             cur.Tags,
+				DatasetSlugs:     cur.DatasetSlugs,
+			}
+			slo := current
+			if cmd.IsSet("name") {
+				slo.Name = cmd.String("name")
+			}
+			if cmd.IsSet("description") {
+				slo.Description = cmd.String("description")
+			}
+			if cmd.IsSet("sli-alias") {
+				slo.SLI = api.SLOSLI{Alias: cmd.String("sli-alias")}
+			}
+			if cmd.IsSet("time-period-days") {
+				slo.TimePeriodDays = int(cmd.Int("time-period-days"))
+			}
+			if cmd.IsSet("target-per-million") {
+				slo.TargetPerMillion = int(cmd.Int("target-per-million"))
+			}
+			if cmd.IsSet("tags-json") {
 				var tags []api.Tag
-				if err := json.Unmarshal([]byte(tj), &tags); err != nil {
+				if err := json.Unmarshal([]byte(cmd.String("tags-json")), &tags); err != nil {
 					return fmt.Errorf("parsing tags-json: %w", err)
 				}
 				slo.Tags = tags
 			}
+			if err := showUpdateDiff(cmd, current, slo); err != nil {
+				return err
+			}
 
-			updated, err := client.UpdateSLO(ctx, cmd.String("dataset"), cmd.String("id"), slo)
+			updated, err := client.UpdateSLO(ctx, cmd.String("dataset"), cmd.String("id"), &slo)
 			if err != nil {
 				return err
 			}
@@ -195,9 +292,20 @@ func DeleteSLOCmd() *cli.Command {
 		Flags: []cli.Flag{
 			DatasetFlag(),
 			IDFlag("id", "SLO ID"),
+			YesFlag(),
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			slo, err := client.GetSLO(ctx, cmd.String("dataset"), cmd.String("id"))
+			if err != nil {
+				return err
+			}
+			if err := confirmDelete(cmd, "SLO", slo.Name, slo); err != nil {
+				return err
+			}
 			return client.DeleteSLO(ctx, cmd.String("dataset"), cmd.String("id"))
 		},
 	}
@@ -0,0 +1,358 @@
+package cmd
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/timefmt"
+	"github.com/urfave/cli/v3"
+)
+
+// waterfallWidth is the number of characters of a waterfall bar for the
+// whole trace.
+const waterfallWidth = 40
+
+// traceQueryLimit is the number of result rows a trace query asks for. A
+// result with this many rows may be missing spans.
+const traceQueryLimit = 1000
+
+// traceSpan is a span of a trace with the spans it is the parent of.
+type traceSpan struct {
+	SpanID        string       `json:"span_id"`
+	ParentID      string       `json:"parent_id,omitempty"`
+	Service       string       `json:"service,omitempty"`
+	Name          string       `json:"name"`
+	DurationMs    float64      `json:"duration_ms"`
+	StartOffsetMs *float64     `json:"start_offset_ms,omitempty"`
+	Error         bool         `json:"error,omitempty"`
+	Children      []*traceSpan `json:"children"`
+
+	start *float64
+}
+
+// traceTree is the span tree of a trace. Spans whose parent was not found
+// are roots alongside the real root.
+type traceTree struct {
+	TraceID    string       `json:"trace_id"`
+	Dataset    string       `json:"dataset"`
+	SpanCount  int          `json:"span_count"`
+	DurationMs float64      `json:"duration_ms"`
+	Roots      []*traceSpan `json:"roots"`
+}
+
+func TraceCmd() *cli.Command {
+	return &cli.Command{
+		Name:     "trace",
+		Category: "Traces",
+		Usage:    "Show the spans of a trace as a waterfall",
+		Description: `Query the spans of a trace, rebuild the span tree from parent IDs and
+print a waterfall with the service, name and duration of every span and
+a bar at its offset from the start of the trace. Error spans are marked
+with ✗ and drawn in red on a terminal. The trace ID, span ID, parent ID,
+name, service, duration and error columns come from the dataset
+definitions (see dataset-definitions).
+
+The Query Data API returns aggregates rather than events, so spans are
+found by breaking down on the span columns. Start times are not a column;
+to place spans on the time axis, pass --start-column with a derived
+column holding the span start in Unix milliseconds:
+
+  hccli create-derived-column --dataset api --alias span_start_ms \
+    --expression 'MUL(EVENT_TIMESTAMP(), 1000)'
+
+Without it, spans are drawn from the start of their parent.
+
+Examples:
+
+  hccli trace --dataset api --trace-id 4bf92f3577b34da6a3ce929d0e0e4736
+  hccli trace --dataset api --trace-id 4bf92f3577b34da6a3ce929d0e0e4736 \
+    --start-column span_start_ms --output json`,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "trace-id",
+				Usage:    "Trace ID to show",
+				Required: true,
+			},
+			DatasetFlag(),
+			&cli.StringFlag{
+				Name:  "time-range",
+				Usage: `How far back to look for the trace (e.g. "4 hours")`,
+				Value: "last day",
+			},
+			&cli.StringFlag{
+				Name:  "start-column",
+				Usage: "Column or derived column holding the span start in Unix milliseconds",
+			},
+			&cli.IntFlag{
+				Name:  "query-timeout",
+				Usage: "Seconds to wait for the query result",
+				Value: 60,
+			},
+			&cli.StringFlag{
+				Name:  "output",
+				Usage: "Output format: text or json",
+				Value: "text",
+			},
+		},
+		Action: func(ctx context.Context, cmd *cli.Command) error {
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
+			dataset := cmd.String("dataset")
+			traceID := cmd.String("trace-id")
+
+			timeRange, err := timefmt.ParseTimeRange(cmd.String("time-range"))
+			if err != nil {
+				return fmt.Errorf("invalid time-range: %w", err)
+			}
+
+			cols := resolveTraceColumns(ctx, client, dataset)
+			startColumn := cmd.String("start-column")
+			q, err := client.CreateQuery(ctx, dataset, traceQuery(cols, traceID, startColumn, timeRange))
+			if err != nil {
+				return fmt.Errorf("creating query: %w", err)
+			}
+			result, err := pollQueryResult(ctx, client, dataset, q.ID, time.Second, time.Duration(cmd.Int("query-timeout"))*time.Second)
+			if err != nil {
+				return err
+			}
+
+			if len(result.Data.Results) >= traceQueryLimit {
+				fmt.Fprintf(os.Stderr, "⚠️  The query returned %d rows, its limit; spans of the trace may be missing\n", len(result.Data.Results))
+			}
+			spans := traceSpans(result, cols, startColumn)
+			if len(spans) == 0 {
+				return fmt.Errorf("no spans of trace %s found in %s (try a larger --time-range)", traceID, dataset)
+			}
+			tree := buildTraceTree(spans)
+			tree.TraceID = traceID
+			tree.Dataset = dataset
+
+			if cmd.String("output") == "json" {
+				return printJSON(tree)
+			}
+			printWaterfall(os.Stdout, tree, isTerminal(os.Stdout))
+			return nil
+		},
+	}
+}
+
+// traceQuery breaks down the events of a trace by span, so that every
+// result row is one span.
+func traceQuery(cols traceColumns, traceID, startColumn string, timeRange int) *api.Query {
+	q := &api.Query{
+		Breakdowns:   []string{cols.SpanID, cols.ParentID, cols.ServiceName, cols.Name, cols.Error},
+		Calculations: []api.Calculation{{Op: "MAX", Column: cols.DurationMs}},
+		Filters:      []api.QueryFilter{{Column: cols.TraceID, Op: "=", Value: traceID}},
+		TimeRange:    timeRange,
+		Limit:        traceQueryLimit,
+	}
+	if startColumn != "" {
+		q.Calculations = append(q.Calculations, api.Calculation{Op: "MIN", Column: startColumn})
+	}
+	return q
+}
+
+// traceSpans reads the spans from the rows of a trace query. A span whose
+// events differ in name, service or error is split over several rows; those
+// rows are merged into one span with the longest duration and the earliest
+// start.
+func traceSpans(result *api.QueryResult, cols traceColumns, startColumn string) []*traceSpan {
+	var spans []*traceSpan
+	byID := map[string]*traceSpan{}
+	for _, row := range result.Data.Results {
+		data, _ := row["data"].(map[string]any)
+		id := stringValue(data[cols.SpanID])
+		if id == "" {
+			continue
+		}
+		span := &traceSpan{
+			SpanID:   id,
+			ParentID: stringValue(data[cols.ParentID]),
+			Service:  stringValue(data[cols.ServiceName]),
+			Name:     stringValue(data[cols.Name]),
+			Error:    isTruthy(data[cols.Error]),
+			Children: []*traceSpan{},
+		}
+		span.DurationMs, _ = data["MAX("+cols.DurationMs+")"].(float64)
+		if startColumn != "" {
+			if start, ok := data["MIN("+startColumn+")"].(float64); ok {
+				span.start = &start
+			}
+		}
+		if prev, ok := byID[id]; ok {
+			mergeSpan(prev, span)
+			continue
+		}
+		byID[id] = span
+		spans = append(spans, span)
+	}
+	return spans
+}
+
+// mergeSpan folds another row of the same span into s.
+func mergeSpan(s, other *traceSpan) {
+	s.ParentID = cmp.Or(s.ParentID, other.ParentID)
+	s.Service = cmp.Or(s.Service, other.Service)
+	s.Name = cmp.Or(s.Name, other.Name)
+	s.Error = s.Error || other.Error
+	s.DurationMs = math.Max(s.DurationMs, other.DurationMs)
+	if other.start != nil && (s.start == nil || *other.start < *s.start) {
+		s.start = other.start
+	}
+}
+
+// buildTraceTree links spans to their parents and computes start offsets
+// from the earliest span start. Without start times, a span starts with
+// its parent.
+func buildTraceTree(spans []*traceSpan) *traceTree {
+	byID := map[string]*traceSpan{}
+	for _, s := range spans {
+		byID[s.SpanID] = s
+	}
+	tree := &traceTree{SpanCount: len(spans), Roots: []*traceSpan{}}
+	for _, s := range spans {
+		if p, ok := byID[s.ParentID]; ok && p != s {
+			p.Children = append(p.Children, s)
+		} else {
+			tree.Roots = append(tree.Roots, s)
+		}
+	}
+
+	first := math.Inf(1)
+	for _, s := range spans {
+		if s.start != nil {
+			first = math.Min(first, *s.start)
+		}
+	}
+	var place func(s *traceSpan, parentOffset float64)
+	place = func(s *traceSpan, parentOffset float64) {
+		if s.StartOffsetMs != nil {
+			return
+		}
+		offset := parentOffset
+		if s.start != nil {
+			offset = *s.start - first
+		}
+		s.StartOffsetMs = &offset
+		tree.DurationMs = math.Max(tree.DurationMs, offset+s.DurationMs)
+		sortSpans(s.Children)
+		for _, c := range s.Children {
+			place(c, offset)
+		}
+	}
+	sortSpans(tree.Roots)
+	for _, r := range tree.Roots {
+		place(r, 0)
+	}
+	// Spans in a parent cycle are not reachable from a root; the cycle is
+	// broken by detaching one of them from its parent.
+	for _, s := range spans {
+		if s.StartOffsetMs == nil {
+			p := byID[s.ParentID]
+			p.Children = slices.DeleteFunc(p.Children, func(c *traceSpan) bool { return c == s })
+			tree.Roots = append(tree.Roots, s)
+			place(s, 0)
+		}
+	}
+	return tree
+}
+
+// sortSpans orders sibling spans by start time, then by name.
+func sortSpans(spans []*traceSpan) {
+	sort.SliceStable(spans, func(i, j int) bool {
+		a, b := spans[i].start, spans[j].start
+		if a != nil && b != nil && *a != *b {
+			return *a < *b
+		}
+		return spans[i].Name < spans[j].Name
+	})
+}
+
+func printWaterfall(out io.Writer, tree *traceTree, color bool) {
+	fmt.Fprintf(out, "Trace %s: %d span(s), %s\n\n", tree.TraceID, tree.SpanCount, formatDurationMs(tree.DurationMs))
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SERVICE\tNAME\tDURATION\tWATERFALL")
+	var row func(s *traceSpan, prefix string, last, root bool)
+	row = func(s *traceSpan, prefix string, last, root bool) {
+		branch, childPrefix := "", ""
+		if !root {
+			branch, childPrefix = "├─ ", "│  "
+			if last {
+				branch, childPrefix = "└─ ", "   "
+			}
+		}
+		name := s.Name
+		bar := waterfallBar(*s.StartOffsetMs, s.DurationMs, tree.DurationMs)
+		if s.Error {
+			name = "✗ " + name
+			if color {
+				bar = "\x1b[31m" + bar + "\x1b[0m"
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s%s%s\t%s\t|%s|\n", s.Service, prefix, branch, name, formatDurationMs(s.DurationMs), bar)
+		for i, c := range s.Children {
+			row(c, prefix+childPrefix, i == len(s.Children)-1, false)
+		}
+	}
+	for _, r := range tree.Roots {
+		row(r, "", true, true)
+	}
+	_ = w.Flush()
+}
+
+// waterfallBar draws a span as a bar of at least one character, placed by
+// its offset within the trace.
+func waterfallBar(offset, duration, total float64) string {
+	if total <= 0 {
+		return strings.Repeat("█", waterfallWidth)
+	}
+	start := min(int(offset/total*waterfallWidth), waterfallWidth-1)
+	length := max(int(math.Round(duration/total*waterfallWidth)), 1)
+	length = min(length, waterfallWidth-start)
+	return strings.Repeat(" ", start) + strings.Repeat("█", length) + strings.Repeat(" ", waterfallWidth-start-length)
+}
+
+func formatDurationMs(ms float64) string {
+	if ms >= 1000 {
+		return strconv.FormatFloat(ms/1000, 'f', 2, 64) + "s"
+	}
+	return strconv.FormatFloat(ms, 'f', 1, 64) + "ms"
+}
+
+// stringValue renders a breakdown value, which is null for spans without
+// the column.
+func stringValue(v any) string {
+	if v == nil {
+		return ""
+	}
+	if s, ok := v.(string); ok {
+		return s
+	}
+	return fmt.Sprint(v)
+}
+
+// isTruthy reports whether an error column value marks an error.
+func isTruthy(v any) bool {
+	switch v := v.(type) {
+	case bool:
+		return v
+	case string:
+		return v != "" && v != "false"
+	case float64:
+		return v != 0
+	}
+	return false
+}
@@ -12,6 +12,8 @@ func GetTraceCmd() *cli.Command {
 		Name:     "get-trace",
 		Category: "Traces",
 		Usage:    "Get the Honeycomb UI URL for a trace",
+		Description: `Print the UI URL of a trace and the column holding trace IDs in the
+dataset, taken from its dataset definitions (see dataset-definitions).`,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
 				Name:     "trace-id",
@@ -25,7 +27,10 @@ func GetTraceCmd() *cli.Command {
 			},
 		},
 		Action: func(ctx context.Context, cmd *cli.Command) error {
-			client := newClient(cmd)
+			client, err := newClient(cmd)
+			if err != nil {
+				return err
+			}
 
 			auth, err := client.GetAuth(ctx)
 			if err != nil {
@@ -43,11 +48,12 @@ func GetTraceCmd() *cli.Command {
 			)
 
 			result := map[string]string{
-				"trace_id":    traceID,
-				"dataset":     dataset,
-				"team":        teamSlug,
-				"environment": envSlug,
-				"url":         traceURL,
+				"trace_id":        traceID,
+				"dataset":         dataset,
+				"team":            teamSlug,
+				"environment":     envSlug,
+				"url":             traceURL,
+				"trace_id_column": resolveTraceColumns(ctx, client, dataset).TraceID,
 			}
 
 			return printJSON(result)
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/LarsEckart/hccli/manifest"
+	"github.com/urfave/cli/v3"
+)
+
+// ShowDiffFlag returns the flag that prints the changes an update makes.
+func ShowDiffFlag() cli.Flag {
+	return &cli.BoolFlag{
+		Name:  "show-diff",
+		Usage: "Print the changed fields on stderr before sending the update",
+	}
+}
+
+// requireAnySet returns an error unless at least one of the named flags
+// was given.
+func requireAnySet(cmd *cli.Command, names ...string) error {
+	for _, n := range names {
+		if cmd.IsSet(n) {
+			return nil
+		}
+	}
+	return fmt.Errorf("nothing to update: set at least one of --%s", strings.Join(names, ", --"))
+}
+
+// showUpdateDiff prints the fields that differ between the current and the
+// updated resource on stderr when --show-diff is set.
+func showUpdateDiff(cmd *cli.Command, current, updated any) error {
+	if !cmd.Bool("show-diff") {
+		return nil
+	}
+	changes, err := fieldChanges(current, updated)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Fprintln(os.Stderr, "No changes")
+		return nil
+	}
+	for _, c := range changes {
+		fmt.Fprintf(os.Stderr, "~ %s: %s -> %s\n", c.Field, manifest.FormatValue(c.Old), manifest.FormatValue(c.New))
+	}
+	return nil
+}
+
+// fieldChanges compares the JSON encodings of two resources field by
+// field. Nested objects are compared per field with dotted names; lists
+// are compared as a whole.
+func fieldChanges(before, after any) ([]manifest.FieldChange, error) {
+	b, err := jsonFields(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := jsonFields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []manifest.FieldChange
+	for k, v := range a {
+		if old, ok := b[k]; !ok || !reflect.DeepEqual(old, v) {
+			out = append(out, manifest.FieldChange{Field: k, Old: b[k], New: v})
+		}
+	}
+	for k, v := range b {
+		if _, ok := a[k]; !ok {
+			out = append(out, manifest.FieldChange{Field: k, Old: v})
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Field < out[j].Field })
+	return out, nil
+}
+
+// jsonFields encodes v as JSON and flattens the objects of the result into
+// dotted field names.
+func jsonFields(v any) (map[string]any, error) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(buf, &raw); err != nil {
+		return nil, err
+	}
+	fields := map[string]any{}
+	var flatten func(prefix string, m map[string]any)
+	flatten = func(prefix string, m map[string]any) {
+		for k, v := range m {
+			if nested, ok := v.(map[string]any); ok {
+				flatten(prefix+k+".", nested)
+				continue
+			}
+			fields[prefix+k] = v
+		}
+	}
+	flatten("", raw)
+	return fields, nil
+}
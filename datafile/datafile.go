@@ -0,0 +1,84 @@
+// Package datafile reads and writes resource files in JSON or YAML.
+package datafile
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// IsYAML reports whether path has a YAML file extension.
+func IsYAML(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// Write encodes v as JSON or YAML depending on the file extension,
+// creating parent directories as needed, and returns the SHA-256 of the
+// written bytes. YAML output uses the same field names as the JSON tags.
+func Write(path string, v any) (string, error) {
+	buf, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	buf = append(buf, '\n')
+
+	if IsYAML(path) {
+		var generic any
+		if err := json.Unmarshal(buf, &generic); err != nil {
+			return "", err
+		}
+		if buf, err = yaml.Marshal(generic); err != nil {
+			return "", err
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(path, buf, 0o644); err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Read decodes a JSON or YAML file into v. YAML is converted to JSON
+// first so that the json struct tags apply to both formats.
+func Read(path string, v any) error {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if IsYAML(path) {
+		var generic any
+		if err := yaml.Unmarshal(buf, &generic); err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+		if buf, err = json.Marshal(generic); err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+	}
+
+	if err := json.Unmarshal(buf, v); err != nil {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+	return nil
+}
+
+// Checksum returns the hex-encoded SHA-256 of the file at path.
+func Checksum(path string) (string, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:]), nil
+}
@@ -0,0 +1,614 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Event is the input of an evaluation. Fields hold strings, int64, float64,
+// bool or nil; the timestamps are returned by EVENT_TIMESTAMP and
+// INGEST_TIMESTAMP and may be zero.
+type Event struct {
+	Fields          map[string]any
+	Timestamp       time.Time
+	IngestTimestamp time.Time
+}
+
+// Eval computes the value of n for an event, following the derived column
+// function library: missing columns are null, functions given arguments of
+// the wrong type return null, and only unknown functions, wrong argument
+// counts and unusable arguments such as invalid regular expressions are
+// errors.
+func Eval(n Node, ev Event) (any, error) {
+	switch n := n.(type) {
+	case *Literal:
+		return n.Value, nil
+	case *ColumnRef:
+		return ev.Fields[n.Name], nil
+	case *Call:
+		f, ok := LookupFunction(n.Name)
+		impl := builtins[f.Name]
+		if !ok || impl == nil {
+			return nil, fmt.Errorf("%s: unknown function %s", n.At, n.Name)
+		}
+		if msg := f.checkArity(len(n.Args)); msg != "" {
+			return nil, fmt.Errorf("%s: %s", n.At, msg)
+		}
+		args := make([]any, len(n.Args))
+		for i, a := range n.Args {
+			v, err := Eval(a, ev)
+			if err != nil {
+				return nil, err
+			}
+			args[i] = v
+		}
+		v, err := impl(args, ev)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", n.At, f.Name, err)
+		}
+		return v, nil
+	}
+	return nil, fmt.Errorf("unexpected node %T", n)
+}
+
+type builtin func(args []any, ev Event) (any, error)
+
+var builtins map[string]builtin
+
+func init() {
+	builtins = map[string]builtin{
+		"IF":       pure(evalIf),
+		"SWITCH":   pure(evalSwitch),
+		"COALESCE": pure(evalCoalesce),
+
+		"LT":     compare(func(c int) bool { return c < 0 }),
+		"LTE":    compare(func(c int) bool { return c <= 0 }),
+		"GT":     compare(func(c int) bool { return c > 0 }),
+		"GTE":    compare(func(c int) bool { return c >= 0 }),
+		"EQUALS": pure(func(a []any) any { return Equal(a[0], a[1]) }),
+		"IN": pure(func(a []any) any {
+			for _, v := range a[1:] {
+				if Equal(a[0], v) {
+					return true
+				}
+			}
+			return false
+		}),
+
+		"EXISTS": pure(func(a []any) any { return a[0] != nil }),
+		"NOT":    pure(func(a []any) any { return !Truthy(a[0]) }),
+		"AND": pure(func(a []any) any {
+			for _, v := range a {
+				if !Truthy(v) {
+					return false
+				}
+			}
+			return true
+		}),
+		"OR": pure(func(a []any) any {
+			for _, v := range a {
+				if Truthy(v) {
+					return true
+				}
+			}
+			return false
+		}),
+
+		"MIN": pure(func(a []any) any { return extreme(a, -1) }),
+		"MAX": pure(func(a []any) any { return extreme(a, 1) }),
+		"SUM": pure(func(a []any) any {
+			return arithmetic(a, func(x, y int64) int64 { return x + y }, func(x, y float64) float64 { return x + y })
+		}),
+		"SUB": pure(func(a []any) any {
+			return arithmetic(a, func(x, y int64) int64 { return x - y }, func(x, y float64) float64 { return x - y })
+		}),
+		"MUL": pure(func(a []any) any {
+			return arithmetic(a, func(x, y int64) int64 { return x * y }, func(x, y float64) float64 { return x * y })
+		}),
+		"DIV":    pure(evalDiv),
+		"MOD":    pure(evalMod),
+		"LOG10":  pure(evalLog10),
+		"BUCKET": pure(evalBucket),
+
+		"INT":    pure(func(a []any) any { return toInt(a[0]) }),
+		"FLOAT":  pure(func(a []any) any { return toFloat(a[0]) }),
+		"BOOL":   pure(func(a []any) any { return toBool(a[0]) }),
+		"STRING": pure(func(a []any) any { return toStringOrNil(a[0]) }),
+
+		"CONCAT": pure(func(a []any) any {
+			var b strings.Builder
+			for _, v := range a {
+				if v != nil {
+					b.WriteString(String(v))
+				}
+			}
+			return b.String()
+		}),
+		"STARTS_WITH": stringPredicate(strings.HasPrefix),
+		"ENDS_WITH":   stringPredicate(strings.HasSuffix),
+		"CONTAINS":    stringPredicate(strings.Contains),
+		"TO_LOWER": pure(func(a []any) any {
+			if s, ok := a[0].(string); ok {
+				return strings.ToLower(s)
+			}
+			return nil
+		}),
+		"LENGTH":    evalLength,
+		"REG_MATCH": evalRegMatch,
+		"REG_VALUE": evalRegValue,
+		"REG_COUNT": evalRegCount,
+
+		"UNIX_TIMESTAMP": pure(func(a []any) any { return unixSeconds(a[0]) }),
+		"FORMAT_TIME":    pure(evalFormatTime),
+		"EVENT_TIMESTAMP": func(_ []any, ev Event) (any, error) {
+			return timestampValue(ev.Timestamp), nil
+		},
+		"INGEST_TIMESTAMP": func(_ []any, ev Event) (any, error) {
+			return timestampValue(ev.IngestTimestamp), nil
+		},
+	}
+}
+
+func pure(f func(args []any) any) builtin {
+	return func(args []any, _ Event) (any, error) { return f(args), nil }
+}
+
+// Truthy reports whether v counts as true in a condition: null, false,
+// zero and the empty string are false.
+func Truthy(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return v
+	case int64:
+		return v != 0
+	case float64:
+		return v != 0
+	case string:
+		return v != ""
+	}
+	return true
+}
+
+// Equal reports whether two values are equal; integers and floats compare
+// by value.
+func Equal(a, b any) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+	return a == b
+}
+
+// String formats a value the way STRING does.
+func String(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return v
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(v)
+}
+
+func number(v any) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func evalIf(a []any) any {
+	for i := 0; i+1 < len(a); i += 2 {
+		if Truthy(a[i]) {
+			return a[i+1]
+		}
+	}
+	if len(a)%2 == 1 {
+		return a[len(a)-1]
+	}
+	return nil
+}
+
+func evalSwitch(a []any) any {
+	cases := a[1:]
+	for i := 0; i+1 < len(cases); i += 2 {
+		if Equal(a[0], cases[i]) {
+			return cases[i+1]
+		}
+	}
+	if len(cases)%2 == 1 {
+		return cases[len(cases)-1]
+	}
+	return nil
+}
+
+func evalCoalesce(a []any) any {
+	for _, v := range a {
+		if v != nil && v != "" {
+			return v
+		}
+	}
+	return nil
+}
+
+// compare returns a comparison of two numbers or two strings; other
+// combinations are false.
+func compare(ok func(c int) bool) builtin {
+	return pure(func(a []any) any {
+		if x, isNum := number(a[0]); isNum {
+			y, isNum := number(a[1])
+			if !isNum {
+				return false
+			}
+			switch {
+			case x < y:
+				return ok(-1)
+			case x > y:
+				return ok(1)
+			}
+			return ok(0)
+		}
+		x, isStr := a[0].(string)
+		y, isStr2 := a[1].(string)
+		if !isStr || !isStr2 {
+			return false
+		}
+		return ok(strings.Compare(x, y))
+	})
+}
+
+// extreme returns the smallest (sign -1) or largest (sign 1) number among
+// the arguments, ignoring anything that is not a number.
+func extreme(a []any, sign float64) any {
+	var best any
+	var bestNum float64
+	for _, v := range a {
+		x, ok := number(v)
+		if !ok {
+			continue
+		}
+		if best == nil || (x-bestNum)*sign > 0 {
+			best, bestNum = v, x
+		}
+	}
+	return best
+}
+
+// arithmetic folds the arguments with an integer operation while all are
+// integers and a float operation otherwise. Any non-number gives null.
+func arithmetic(a []any, ints func(x, y int64) int64, floats func(x, y float64) float64) any {
+	allInts := true
+	for _, v := range a {
+		switch v.(type) {
+		case int64:
+		case float64:
+			allInts = false
+		default:
+			return nil
+		}
+	}
+	if allInts {
+		acc := a[0].(int64)
+		for _, v := range a[1:] {
+			acc = ints(acc, v.(int64))
+		}
+		return acc
+	}
+	acc, _ := number(a[0])
+	for _, v := range a[1:] {
+		x, _ := number(v)
+		acc = floats(acc, x)
+	}
+	return acc
+}
+
+func evalDiv(a []any) any {
+	x, ok1 := number(a[0])
+	y, ok2 := number(a[1])
+	if !ok1 || !ok2 || y == 0 {
+		return nil
+	}
+	return x / y
+}
+
+func evalMod(a []any) any {
+	if x, ok := a[0].(int64); ok {
+		if y, ok := a[1].(int64); ok {
+			if y == 0 {
+				return nil
+			}
+			return x % y
+		}
+	}
+	x, ok1 := number(a[0])
+	y, ok2 := number(a[1])
+	if !ok1 || !ok2 || y == 0 {
+		return nil
+	}
+	return math.Mod(x, y)
+}
+
+func evalLog10(a []any) any {
+	x, ok := number(a[0])
+	if !ok || x <= 0 {
+		return nil
+	}
+	return math.Log10(x)
+}
+
+// evalBucket returns the label of the bucket of width size holding a
+// value, such as "100 - 200", counted from min (default 0). Values below
+// min or at or above max get "< min" and ">= max".
+func evalBucket(a []any) any {
+	x, ok := number(a[0])
+	size, ok2 := number(a[1])
+	if !ok || !ok2 || size <= 0 {
+		return nil
+	}
+	var lo float64
+	if len(a) > 2 {
+		lower, ok := number(a[2])
+		if !ok {
+			return nil
+		}
+		if x < lower {
+			return "< " + String(a[2])
+		}
+		lo = lower
+	}
+	if len(a) > 3 {
+		upper, ok := number(a[3])
+		if !ok {
+			return nil
+		}
+		if x >= upper {
+			return ">= " + String(a[3])
+		}
+	}
+	start := lo + math.Floor((x-lo)/size)*size
+	return formatNumber(start) + " - " + formatNumber(start+size)
+}
+
+func formatNumber(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+func toInt(v any) any {
+	switch v := v.(type) {
+	case int64:
+		return v
+	case float64:
+		return int64(v)
+	case bool:
+		if v {
+			return int64(1)
+		}
+		return int64(0)
+	case string:
+		s := strings.TrimSpace(v)
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return n
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return int64(f)
+		}
+	}
+	return nil
+}
+
+func toFloat(v any) any {
+	switch v := v.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	case bool:
+		if v {
+			return 1.0
+		}
+		return 0.0
+	case string:
+		if f, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+			return f
+		}
+	}
+	return nil
+}
+
+func toBool(v any) any {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		if b, err := strconv.ParseBool(strings.TrimSpace(v)); err == nil {
+			return b
+		}
+		return nil
+	}
+	return Truthy(v)
+}
+
+func toStringOrNil(v any) any {
+	if v == nil {
+		return nil
+	}
+	return String(v)
+}
+
+func stringPredicate(f func(s, sub string) bool) builtin {
+	return pure(func(a []any) any {
+		s, ok1 := a[0].(string)
+		sub, ok2 := a[1].(string)
+		return ok1 && ok2 && f(s, sub)
+	})
+}
+
+func evalLength(a []any, _ Event) (any, error) {
+	s, ok := a[0].(string)
+	if !ok {
+		return nil, nil
+	}
+	unit := "bytes"
+	if len(a) > 1 {
+		unit, _ = a[1].(string)
+	}
+	switch unit {
+	case "bytes":
+		return int64(len(s)), nil
+	case "chars":
+		return int64(utf8.RuneCountInString(s)), nil
+	}
+	return nil, fmt.Errorf(`unit must be "bytes" or "chars", got %s`, String(a[1]))
+}
+
+var (
+	regexpMu    sync.Mutex
+	regexpCache = map[string]*regexp.Regexp{}
+)
+
+func compileRegexp(v any) (*regexp.Regexp, error) {
+	pattern, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("pattern must be a string, got %s", String(v))
+	}
+	regexpMu.Lock()
+	defer regexpMu.Unlock()
+	if re, ok := regexpCache[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexpCache[pattern] = re
+	return re, nil
+}
+
+func evalRegMatch(a []any, _ Event) (any, error) {
+	re, err := compileRegexp(a[1])
+	if err != nil {
+		return nil, err
+	}
+	s, ok := a[0].(string)
+	return ok && re.MatchString(s), nil
+}
+
+// evalRegValue returns the first capture group of the first match, or the
+// whole match when the pattern has no groups.
+func evalRegValue(a []any, _ Event) (any, error) {
+	re, err := compileRegexp(a[1])
+	if err != nil {
+		return nil, err
+	}
+	s, ok := a[0].(string)
+	if !ok {
+		return nil, nil
+	}
+	m := re.FindStringSubmatch(s)
+	switch {
+	case m == nil:
+		return nil, nil
+	case len(m) > 1:
+		return m[1], nil
+	}
+	return m[0], nil
+}
+
+func evalRegCount(a []any, _ Event) (any, error) {
+	re, err := compileRegexp(a[1])
+	if err != nil {
+		return nil, err
+	}
+	s, ok := a[0].(string)
+	if !ok {
+		return int64(0), nil
+	}
+	return int64(len(re.FindAllStringIndex(s, -1))), nil
+}
+
+// unixSeconds converts a number of seconds or an RFC 3339 timestamp to
+// seconds since the epoch.
+func unixSeconds(v any) any {
+	if x, ok := number(v); ok {
+		return x
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, strings.TrimSpace(s))
+	if err != nil {
+		return nil
+	}
+	return timestampValue(t)
+}
+
+func timestampValue(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return float64(t.UnixNano()) / 1e9
+}
+
+// evalFormatTime formats a timestamp (seconds or RFC 3339) in UTC with a
+// strftime-style format.
+func evalFormatTime(a []any) any {
+	format, ok := a[0].(string)
+	secs, ok2 := unixSeconds(a[1]).(float64)
+	if !ok || !ok2 {
+		return nil
+	}
+	sec, frac := math.Modf(secs)
+	return strftime(format, time.Unix(int64(sec), int64(frac*1e9)).UTC())
+}
+
+var strftimeLayouts = map[byte]string{
+	'Y': "2006", 'y': "06", 'm': "01", 'd': "02", 'e': "_2",
+	'H': "15", 'I': "03", 'M': "04", 'S': "05", 'p': "PM",
+	'b': "Jan", 'B': "January", 'a': "Mon", 'A': "Monday",
+	'Z': "MST", 'z': "-0700", 'F': "2006-01-02", 'T': "15:04:05",
+}
+
+func strftime(format string, t time.Time) string {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' || i+1 == len(format) {
+			b.WriteByte(format[i])
+			continue
+		}
+		i++
+		switch c := format[i]; c {
+		case '%':
+			b.WriteByte('%')
+		case 'j':
+			fmt.Fprintf(&b, "%03d", t.YearDay())
+		case 's':
+			b.WriteString(strconv.FormatInt(t.Unix(), 10))
+		default:
+			if layout, ok := strftimeLayouts[c]; ok {
+				b.WriteString(t.Format(layout))
+			} else {
+				b.WriteByte('%')
+				b.WriteByte(c)
+			}
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,108 @@
+package expr_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LarsEckart/hccli/expr"
+)
+
+func TestEval(t *testing.T) {
+	ev := expr.Event{
+		Fields: map[string]any{
+			"status_code": int64(503),
+			"duration_ms": 250.5,
+			"http.route":  "/api/users/42",
+			"service":     "API",
+			"empty":       "",
+		},
+		Timestamp: time.Date(2026, 3, 1, 12, 30, 0, 0, time.UTC),
+	}
+	tests := []struct {
+		src  string
+		want any
+	}{
+		{`IF(GTE($status_code, 500), "error", "ok")`, "error"},
+		{`IF(LT($status_code, 400), 1, GTE($status_code, 500), 2, 3)`, int64(2)},
+		{`IF(LT($status_code, 400), 1)`, nil},
+		{`SWITCH($service, "WEB", 1, "API", 2, 0)`, int64(2)},
+		{`COALESCE($missing, $empty, "fallback")`, "fallback"},
+		{`EQUALS($status_code, 503.0)`, true},
+		{`IN($status_code, 500, 502, 503)`, true},
+		{`AND(EXISTS($service), NOT(EXISTS($missing)))`, true},
+		{`OR($missing, $empty, 0)`, false},
+		{`SUM($status_code, 1)`, int64(504)},
+		{`SUM($status_code, $duration_ms)`, 753.5},
+		{`DIV($status_code, 0)`, nil},
+		{`MOD($status_code, 100)`, int64(3)},
+		{`MAX(1, $duration_ms, $missing, 7)`, 250.5},
+		{`BUCKET($duration_ms, 100)`, "200 - 300"},
+		{`BUCKET($duration_ms, 100, 0, 200)`, ">= 200"},
+		{`INT($duration_ms)`, int64(250)},
+		{`INT("12")`, int64(12)},
+		{`FLOAT($status_code)`, 503.0},
+		{`BOOL("false")`, false},
+		{`STRING($status_code)`, "503"},
+		{`CONCAT(TO_LOWER($service), ":", $status_code, $missing)`, "api:503"},
+		{`STARTS_WITH($"http.route", "/api/")`, true},
+		{`CONTAINS($status_code, "5")`, false},
+		{`LENGTH("héllo", "chars")`, int64(5)},
+		{`LENGTH("héllo")`, int64(6)},
+		{"REG_VALUE($\"http.route\", `/users/(\\d+)`)", "42"},
+		{"REG_MATCH($service, `^[A-Z]+$`)", true},
+		{"REG_COUNT($\"http.route\", `/`)", int64(3)},
+		{`UNIX_TIMESTAMP("2026-03-01T00:00:00Z")`, 1772323200.0},
+		{`FORMAT_TIME("%Y-%m-%d %H:%M %A", EVENT_TIMESTAMP())`, "2026-03-01 12:30 Sunday"},
+		{`INGEST_TIMESTAMP()`, nil},
+	}
+	for _, tt := range tests {
+		n, err := expr.Parse(tt.src)
+		if err != nil {
+			t.Errorf("Parse(%s): %v", tt.src, err)
+			continue
+		}
+		got, err := expr.Eval(n, ev)
+		if err != nil {
+			t.Errorf("Eval(%s): %v", tt.src, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Eval(%s) = %#v, want %#v", tt.src, got, tt.want)
+		}
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	for src, want := range map[string]string{
+		"REG_MATCH($a, `(`)":   "1:1: REG_MATCH: error parsing regexp",
+		`IF(NOPE(1), 1)`:       "1:4: unknown function NOPE",
+		`LENGTH("a", "words")`: `unit must be "bytes" or "chars"`,
+	} {
+		n, err := expr.Parse(src)
+		if err != nil {
+			t.Fatalf("Parse(%s): %v", src, err)
+		}
+		_, err = expr.Eval(n, expr.Event{})
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Eval(%s) error = %v, want %q", src, err, want)
+		}
+	}
+}
+
+func TestEveryFunctionIsImplemented(t *testing.T) {
+	for _, name := range expr.FunctionNames() {
+		f, _ := expr.LookupFunction(name)
+		args := make([]string, f.MinArgs)
+		for i := range args {
+			args[i] = "1"
+		}
+		n, err := expr.Parse(name + "(" + strings.Join(args, ", ") + ")")
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		if _, err := expr.Eval(n, expr.Event{}); err != nil && strings.Contains(err.Error(), "unknown function") {
+			t.Errorf("%s has no implementation", name)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+// Package expr parses Honeycomb derived column expressions, checks them
+// against the function library and the columns of a dataset, and evaluates
+// them against events.
+package expr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Pos is a position in an expression. Line and Column are 1-based; Column
+// counts characters, not bytes.
+type Pos struct {
+	Offset int `json:"offset"`
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (p Pos) String() string {
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// Node is a parsed expression: a *Call, a *ColumnRef or a *Literal.
+type Node interface {
+	Pos() Pos
+}
+
+// Call is a function call such as IF($a, 1, 2).
+type Call struct {
+	Name string
+	Args []Node
+	At   Pos
+}
+
+// ColumnRef is a reference to a column or derived column, written $name or
+// $"quoted name".
+type ColumnRef struct {
+	Name string
+	At   Pos
+}
+
+// Literal is a string, number, boolean or null constant. Value is a
+// string, int64, float64, bool or nil.
+type Literal struct {
+	Value any
+	At    Pos
+}
+
+func (c *Call) Pos() Pos      { return c.At }
+func (c *ColumnRef) Pos() Pos { return c.At }
+func (l *Literal) Pos() Pos   { return l.At }
+
+// Columns returns the columns referenced by n in order of first use.
+func Columns(n Node) []string {
+	var cols []string
+	seen := map[string]bool{}
+	var walk func(Node)
+	walk = func(n Node) {
+		switch n := n.(type) {
+		case *ColumnRef:
+			if !seen[n.Name] {
+				seen[n.Name] = true
+				cols = append(cols, n.Name)
+			}
+		case *Call:
+			for _, a := range n.Args {
+				walk(a)
+			}
+		}
+	}
+	walk(n)
+	return cols
+}
+
+// Kinds of problems reported by Lint.
+const (
+	SyntaxError     = "syntax"
+	UnknownFunction = "unknown_function"
+	WrongArity      = "arity"
+	UnknownColumn   = "unknown_column"
+)
+
+// Problem is one issue found in an expression.
+type Problem struct {
+	Pos     Pos    `json:"pos"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return p.Pos.String() + ": " + p.Message
+}
+
+// Lint parses src and checks it. A syntax error is reported on its own;
+// otherwise every unknown function, wrong argument count and, when known is
+// not nil, every column for which known returns false is reported.
+func Lint(src string, known func(column string) bool) []Problem {
+	n, err := Parse(src)
+	if err != nil {
+		pe := err.(*ParseError)
+		return []Problem{{Pos: pe.Pos, Kind: SyntaxError, Message: pe.Msg}}
+	}
+	return Check(n, known)
+}
+
+// Check reports unknown functions, wrong argument counts and, when known is
+// not nil, unknown columns in a parsed expression.
+func Check(n Node, known func(column string) bool) []Problem {
+	problems := []Problem{}
+	var walk func(Node)
+	walk = func(n Node) {
+		switch n := n.(type) {
+		case *ColumnRef:
+			if known != nil && !known(n.Name) {
+				problems = append(problems, Problem{Pos: n.At, Kind: UnknownColumn, Message: fmt.Sprintf("unknown column %q", n.Name)})
+			}
+		case *Call:
+			if f, ok := LookupFunction(n.Name); !ok {
+				problems = append(problems, Problem{Pos: n.At, Kind: UnknownFunction, Message: fmt.Sprintf("unknown function %s", n.Name)})
+			} else if msg := f.checkArity(len(n.Args)); msg != "" {
+				problems = append(problems, Problem{Pos: n.At, Kind: WrongArity, Message: msg})
+			}
+			for _, a := range n.Args {
+				walk(a)
+			}
+		}
+	}
+	walk(n)
+	return problems
+}
+
+// Function describes a function of the derived column library.
+type Function struct {
+	Name    string
+	MinArgs int
+	// MaxArgs is -1 for functions taking any number of arguments.
+	MaxArgs int
+}
+
+func (f Function) checkArity(n int) string {
+	switch {
+	case f.MinArgs == f.MaxArgs && n != f.MinArgs:
+		return fmt.Sprintf("%s takes %d argument%s, got %d", f.Name, f.MinArgs, plural(f.MinArgs), n)
+	case n < f.MinArgs && f.MaxArgs < 0:
+		return fmt.Sprintf("%s takes at least %d argument%s, got %d", f.Name, f.MinArgs, plural(f.MinArgs), n)
+	case n < f.MinArgs || (f.MaxArgs >= 0 && n > f.MaxArgs):
+		return fmt.Sprintf("%s takes %d to %d arguments, got %d", f.Name, f.MinArgs, f.MaxArgs, n)
+	}
+	return ""
+}
+
+func plural(n int) string {
+	if n == 1 {
+		return ""
+	}
+	return "s"
+}
+
+var functions = map[string]Function{}
+
+func init() {
+	for _, f := range []Function{
+		// Conditionals
+		{"IF", 2, -1},
+		{"SWITCH", 3, -1},
+		{"COALESCE", 1, -1},
+		// Comparison
+		{"LT", 2, 2},
+		{"LTE", 2, 2},
+		{"GT", 2, 2},
+		{"GTE", 2, 2},
+		{"EQUALS", 2, 2},
+		{"IN", 2, -1},
+		// Boolean
+		{"EXISTS", 1, 1},
+		{"NOT", 1, 1},
+		{"AND", 1, -1},
+		{"OR", 1, -1},
+		// Math
+		{"MIN", 1, -1},
+		{"MAX", 1, -1},
+		{"SUM", 1, -1},
+		{"SUB", 2, 2},
+		{"MUL", 1, -1},
+		{"DIV", 2, 2},
+		{"MOD", 2, 2},
+		{"LOG10", 1, 1},
+		{"BUCKET", 2, 4},
+		// Casts
+		{"INT", 1, 1},
+		{"FLOAT", 1, 1},
+		{"BOOL", 1, 1},
+		{"STRING", 1, 1},
+		// Strings
+		{"CONCAT", 1, -1},
+		{"STARTS_WITH", 2, 2},
+		{"ENDS_WITH", 2, 2},
+		{"CONTAINS", 2, 2},
+		{"TO_LOWER", 1, 1},
+		{"LENGTH", 1, 2},
+		{"REG_MATCH", 2, 2},
+		{"REG_VALUE", 2, 2},
+		{"REG_COUNT", 2, 2},
+		// Time
+		{"UNIX_TIMESTAMP", 1, 1},
+		{"FORMAT_TIME", 2, 2},
+		{"EVENT_TIMESTAMP", 0, 0},
+		{"INGEST_TIMESTAMP", 0, 0},
+	} {
+		functions[f.Name] = f
+	}
+}
+
+// LookupFunction returns the library function called name, ignoring case.
+func LookupFunction(name string) (Function, bool) {
+	f, ok := functions[strings.ToUpper(name)]
+	return f, ok
+}
+
+// FunctionNames returns the names of all library functions, sorted.
+func FunctionNames() []string {
+	names := make([]string, 0, len(functions))
+	for name := range functions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,98 @@
+package expr_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/LarsEckart/hccli/expr"
+)
+
+func TestParse(t *testing.T) {
+	n, err := expr.Parse("IF(REG_MATCH($\"http.route\", `^/api/`), LT($duration_ms, 300.5), -1, null)")
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	call, ok := n.(*expr.Call)
+	if !ok || call.Name != "IF" || len(call.Args) != 4 {
+		t.Fatalf("unexpected root node: %#v", n)
+	}
+	match := call.Args[0].(*expr.Call)
+	if match.Args[0].(*expr.ColumnRef).Name != "http.route" {
+		t.Errorf("unexpected column: %#v", match.Args[0])
+	}
+	if match.Args[1].(*expr.Literal).Value != "^/api/" {
+		t.Errorf("unexpected raw string: %#v", match.Args[1])
+	}
+	if lt := call.Args[1].(*expr.Call); lt.Args[1].(*expr.Literal).Value != 300.5 {
+		t.Errorf("unexpected float: %#v", lt.Args[1])
+	}
+	if v := call.Args[2].(*expr.Literal).Value; v != int64(-1) {
+		t.Errorf("unexpected int: %#v", v)
+	}
+	if v := call.Args[3].(*expr.Literal).Value; v != nil {
+		t.Errorf("unexpected null: %#v", v)
+	}
+
+	if got := expr.Columns(n); !reflect.DeepEqual(got, []string{"http.route", "duration_ms"}) {
+		t.Errorf("Columns = %q", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		pos  string
+		want string
+	}{
+		{"", "1:1", "empty expression"},
+		{"INT(1", "1:6", "missing ) to close INT( opened at 1:1"},
+		{"LT($a 1)", "1:7", "expected , or ) in arguments of LT"},
+		{"EQUALS($a, \"x)", "1:12", "unterminated string"},
+		{"EQUALS(status, 1)", "1:8", "unexpected identifier status"},
+		{"INT(1) INT(2)", "1:8", "after end of expression"},
+		{"IF(\n  $a,\n  ,1)", "3:3", "expected an expression"},
+		{"$", "1:2", "expected a column name after $"},
+	}
+	for _, tt := range tests {
+		_, err := expr.Parse(tt.src)
+		if err == nil {
+			t.Errorf("Parse(%q) returned no error", tt.src)
+			continue
+		}
+		pe := err.(*expr.ParseError)
+		if pe.Pos.String() != tt.pos || !strings.Contains(pe.Msg, tt.want) {
+			t.Errorf("Parse(%q) = %v, want %s: ...%s...", tt.src, err, tt.pos, tt.want)
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	known := func(c string) bool { return c == "status_code" }
+	problems := expr.Lint("IF(LT($status_code), FOO($missing), INT(1, 2), COALESCE())", known)
+
+	var got []string
+	for _, p := range problems {
+		got = append(got, p.Kind+" "+p.String())
+	}
+	want := []string{
+		"arity 1:4: LT takes 2 arguments, got 1",
+		"unknown_function 1:22: unknown function FOO",
+		"unknown_column 1:26: unknown column \"missing\"",
+		"arity 1:37: INT takes 1 argument, got 2",
+		"arity 1:48: COALESCE takes at least 1 argument, got 0",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lint problems:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if problems := expr.Lint("bucket($status_code, 100, 0, 600)", known); len(problems) != 0 {
+		t.Errorf("expected lowercase function names to be accepted, got %v", problems)
+	}
+	if problems := expr.Lint("BUCKET($x, 1, 2, 3, 4)", nil); len(problems) != 1 || problems[0].Message != "BUCKET takes 2 to 4 arguments, got 5" {
+		t.Errorf("unexpected problems without column check: %v", problems)
+	}
+	if problems := expr.Lint("INT(", nil); len(problems) != 1 || problems[0].Kind != expr.SyntaxError {
+		t.Errorf("expected a single syntax error, got %v", problems)
+	}
+}
@@ -0,0 +1,261 @@
+package expr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseError is a syntax error at a position in the expression.
+type ParseError struct {
+	Pos Pos
+	Msg string
+}
+
+func (e *ParseError) Error() string {
+	return e.Pos.String() + ": " + e.Msg
+}
+
+// Parse parses a derived column expression. Errors are *ParseError.
+func Parse(src string) (Node, error) {
+	p := &parser{src: src, line: 1, col: 1}
+	p.skipSpace()
+	if p.eof() {
+		return nil, p.errorf("empty expression")
+	}
+	n, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !p.eof() {
+		return nil, p.errorf("unexpected %s after end of expression", p.describe())
+	}
+	return n, nil
+}
+
+type parser struct {
+	src       string
+	off       int
+	line, col int
+}
+
+func (p *parser) pos() Pos {
+	return Pos{Offset: p.off, Line: p.line, Column: p.col}
+}
+
+func (p *parser) eof() bool {
+	return p.off >= len(p.src)
+}
+
+func (p *parser) peek() rune {
+	r, _ := utf8.DecodeRuneInString(p.src[p.off:])
+	return r
+}
+
+func (p *parser) next() rune {
+	r, size := utf8.DecodeRuneInString(p.src[p.off:])
+	p.off += size
+	if r == '\n' {
+		p.line++
+		p.col = 1
+	} else {
+		p.col++
+	}
+	return r
+}
+
+func (p *parser) skipSpace() {
+	for !p.eof() && strings.ContainsRune(" \t\r\n", p.peek()) {
+		p.next()
+	}
+}
+
+func (p *parser) errorf(format string, args ...any) *ParseError {
+	return &ParseError{Pos: p.pos(), Msg: fmt.Sprintf(format, args...)}
+}
+
+// describe names the input at the current position for error messages.
+func (p *parser) describe() string {
+	if p.eof() {
+		return "end of expression"
+	}
+	return strconv.QuoteRune(p.peek())
+}
+
+func (p *parser) parseExpr() (Node, error) {
+	p.skipSpace()
+	start := p.pos()
+	switch r := p.peek(); {
+	case p.eof():
+		return nil, p.errorf("expected an expression, got end of expression")
+	case r == '$':
+		p.next()
+		name, err := p.parseColumnName()
+		if err != nil {
+			return nil, err
+		}
+		return &ColumnRef{Name: name, At: start}, nil
+	case r == '"' || r == '`':
+		s, err := p.parseString()
+		if err != nil {
+			return nil, err
+		}
+		return &Literal{Value: s, At: start}, nil
+	case r == '-' || r == '.' || isDigit(r):
+		return p.parseNumber()
+	case isIdentStart(r):
+		name := p.parseIdent()
+		p.skipSpace()
+		if p.peek() == '(' && !p.eof() {
+			return p.parseCall(name, start)
+		}
+		switch strings.ToLower(name) {
+		case "true":
+			return &Literal{Value: true, At: start}, nil
+		case "false":
+			return &Literal{Value: false, At: start}, nil
+		case "null":
+			return &Literal{Value: nil, At: start}, nil
+		}
+		return nil, &ParseError{Pos: start, Msg: fmt.Sprintf("unexpected identifier %s (column references start with $)", name)}
+	default:
+		return nil, p.errorf("expected an expression, got %s", p.describe())
+	}
+}
+
+func (p *parser) parseCall(name string, start Pos) (Node, error) {
+	p.next() // (
+	call := &Call{Name: name, At: start, Args: []Node{}}
+	p.skipSpace()
+	if p.peek() == ')' && !p.eof() {
+		p.next()
+		return call, nil
+	}
+	for {
+		arg, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		call.Args = append(call.Args, arg)
+		p.skipSpace()
+		switch {
+		case p.eof():
+			return nil, &ParseError{Pos: p.pos(), Msg: fmt.Sprintf("missing ) to close %s( opened at %s", name, start)}
+		case p.peek() == ',':
+			p.next()
+		case p.peek() == ')':
+			p.next()
+			return call, nil
+		default:
+			return nil, p.errorf("expected , or ) in arguments of %s, got %s", name, p.describe())
+		}
+	}
+}
+
+func (p *parser) parseColumnName() (string, error) {
+	switch r := p.peek(); {
+	case p.eof():
+		return "", p.errorf("expected a column name after $")
+	case r == '"' || r == '`':
+		name, err := p.parseString()
+		if err != nil {
+			return "", err
+		}
+		if name == "" {
+			return "", p.errorf("empty column name")
+		}
+		return name, nil
+	case isIdentStart(r):
+		return p.parseIdent(), nil
+	default:
+		return "", p.errorf("expected a column name after $, got %s", p.describe())
+	}
+}
+
+func (p *parser) parseIdent() string {
+	start := p.off
+	for !p.eof() && isIdentPart(p.peek()) {
+		p.next()
+	}
+	return p.src[start:p.off]
+}
+
+// parseString reads a double-quoted string with backslash escapes or a
+// backquoted raw string, as used for regular expressions.
+func (p *parser) parseString() (string, error) {
+	start := p.pos()
+	quote := p.next()
+	var b strings.Builder
+	for {
+		if p.eof() {
+			return "", &ParseError{Pos: start, Msg: "unterminated string"}
+		}
+		r := p.next()
+		switch {
+		case r == quote:
+			return b.String(), nil
+		case r == '\\' && quote == '"':
+			if p.eof() {
+				return "", &ParseError{Pos: start, Msg: "unterminated string"}
+			}
+			switch e := p.next(); e {
+			case 'n':
+				b.WriteRune('\n')
+			case 't':
+				b.WriteRune('\t')
+			case 'r':
+				b.WriteRune('\r')
+			default:
+				b.WriteRune(e)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+}
+
+func (p *parser) parseNumber() (Node, error) {
+	start := p.pos()
+	if p.peek() == '-' {
+		p.next()
+	}
+	isFloat := false
+	for !p.eof() {
+		r := p.peek()
+		exponentSign := (r == '+' || r == '-') && strings.ContainsAny(p.src[p.off-1:p.off], "eE")
+		if !isDigit(r) && r != '.' && r != 'e' && r != 'E' && !exponentSign {
+			break
+		}
+		if !isDigit(r) {
+			isFloat = true
+		}
+		p.next()
+	}
+	text := p.src[start.Offset:p.off]
+	if isFloat {
+		f, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, &ParseError{Pos: start, Msg: fmt.Sprintf("invalid number %s", text)}
+		}
+		return &Literal{Value: f, At: start}, nil
+	}
+	n, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return nil, &ParseError{Pos: start, Msg: fmt.Sprintf("invalid number %s", text)}
+	}
+	return &Literal{Value: n, At: start}, nil
+}
+
+func isDigit(r rune) bool {
+	return r >= '0' && r <= '9'
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
+
+func isIdentPart(r rune) bool {
+	return isIdentStart(r) || isDigit(r) || r == '.'
+}
@@ -2,4 +2,7 @@ module github.com/LarsEckart/hccli
 
 go 1.25.6
 
-require github.com/urfave/cli/v3 v3.6.2 // indirect
+require (
+	github.com/urfave/cli/v3 v3.6.2
+	gopkg.in/yaml.v3 v3.0.1
+)
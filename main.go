@@ -21,13 +21,18 @@ Authentication:
 
 Output:
   All commands output JSON with 2-space indentation, making them easy to parse
-  and pipe into tools like jq for further processing.`,
+  and pipe into tools like jq for further processing.
+
+Safety:
+  Delete commands print the resource and ask for its name to be typed back;
+  --yes skips the question, which is required when stdin is not a terminal.
+  With --dry-run, every request that would change anything is printed to
+  stderr instead of being sent; queries still run.`,
 		Flags: []cli.Flag{
 			&cli.StringFlag{
-				Name:     "api-key",
-				Sources:  cli.EnvVars("HONEYCOMB_API_KEY"),
-				Usage:    "Honeycomb API key",
-				Required: true,
+				Name:    "api-key",
+				Sources: cli.EnvVars("HONEYCOMB_API_KEY"),
+				Usage:   "Honeycomb API key",
 			},
 			&cli.IntFlag{
 				Name:        "timeout",
@@ -42,6 +47,10 @@ Output:
 				Value:   "https://api.honeycomb.io",
 				Sources: cli.EnvVars("HONEYCOMB_API_URL"),
 			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Print requests that would change anything instead of sending them",
+			},
 		},
 		Commands: []*cli.Command{
 			cmd.AuthCmd(),
@@ -51,11 +60,13 @@ Output:
 			cmd.CreateBoardCmd(),
 			cmd.UpdateBoardCmd(),
 			cmd.DeleteBoardCmd(),
+			cmd.SnapshotBoardCmd(),
 			cmd.ListBoardViewsCmd(),
 			cmd.GetBoardViewCmd(),
 			cmd.CreateBoardViewCmd(),
 			cmd.UpdateBoardViewCmd(),
 			cmd.DeleteBoardViewCmd(),
+			cmd.CopyBoardViewsCmd(),
 			cmd.GetQueryCmd(),
 			cmd.CreateQueryCmd(),
 			cmd.CreateQueryResultCmd(),
@@ -70,35 +81,58 @@ Output:
 			cmd.CreateColumnCmd(),
 			cmd.UpdateColumnCmd(),
 			cmd.DeleteColumnCmd(),
+			cmd.ColumnAuditCmd(),
+			cmd.ExportSchemaCmd(),
+			cmd.DiffSchemaCmd(),
+			cmd.ApplyColumnsCmd(),
 			cmd.ListDatasetsCmd(),
 			cmd.GetDatasetCmd(),
 			cmd.CreateDatasetCmd(),
 			cmd.UpdateDatasetCmd(),
 			cmd.DeleteDatasetCmd(),
+			cmd.DatasetInventoryCmd(),
+			cmd.GetDatasetDefinitionsCmd(),
+			cmd.UpdateDatasetDefinitionsCmd(),
 			cmd.ListDerivedColumnsCmd(),
 			cmd.GetDerivedColumnCmd(),
 			cmd.CreateDerivedColumnCmd(),
 			cmd.UpdateDerivedColumnCmd(),
 			cmd.DeleteDerivedColumnCmd(),
+			cmd.LintDerivedColumnCmd(),
+			cmd.EvalDerivedColumnCmd(),
+			cmd.DerivedColumnGraphCmd(),
 			cmd.ListMarkersCmd(),
 			cmd.CreateMarkerCmd(),
 			cmd.UpdateMarkerCmd(),
 			cmd.DeleteMarkerCmd(),
+			cmd.DeleteMarkersCmd(),
+			cmd.MarkCmd(),
+			cmd.MarkersFromGitCmd(),
 			cmd.ListMarkerSettingsCmd(),
 			cmd.CreateMarkerSettingCmd(),
 			cmd.UpdateMarkerSettingCmd(),
 			cmd.DeleteMarkerSettingCmd(),
+			cmd.SyncMarkerSettingsCmd(),
 			cmd.ListSLOsCmd(),
 			cmd.GetSLOCmd(),
 			cmd.CreateSLOCmd(),
 			cmd.UpdateSLOCmd(),
 			cmd.DeleteSLOCmd(),
+			cmd.SLOReportCmd(),
+			cmd.SLOBudgetCmd(),
 			cmd.ListBurnAlertsCmd(),
 			cmd.GetBurnAlertCmd(),
 			cmd.CreateBurnAlertCmd(),
 			cmd.UpdateBurnAlertCmd(),
 			cmd.DeleteBurnAlertCmd(),
+			cmd.CreateBurnAlertPolicyCmd(),
 			cmd.GetTraceCmd(),
+			cmd.TraceCmd(),
+			cmd.ExportAllCmd(),
+			cmd.RestoreCmd(),
+			cmd.PlanCmd(),
+			cmd.ApplyCmd(),
+			cmd.DriftCmd(),
 		},
 	}
 
@@ -0,0 +1,220 @@
+package manifest
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Actions a change can take.
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionDelete = "delete"
+	ActionNoop   = "no-op"
+)
+
+// FieldChange is a single field whose live value differs from the manifest.
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   any    `json:"old"`
+	New   any    `json:"new"`
+}
+
+// Change is the action needed to reconcile one resource.
+type Change struct {
+	Action  string        `json:"action"`
+	Kind    string        `json:"kind"`
+	Dataset string        `json:"dataset,omitempty"`
+	Key     string        `json:"key"`
+	ID      string        `json:"id,omitempty"`
+	Fields  []FieldChange `json:"fields,omitempty"`
+
+	// Desired and Live are the manifest and live resources; either is nil
+	// for creates and deletes respectively.
+	Desired *Resource `json:"-"`
+	Live    *Resource `json:"-"`
+}
+
+// Ref returns a human-readable identifier for the changed resource.
+func (c Change) Ref() string {
+	return Resource{Kind: c.Kind, Dataset: c.Dataset, Key: c.Key}.Ref()
+}
+
+// Plan compares desired resources with live ones and returns one change per
+// resource, ordered so that applying them in sequence respects dependencies:
+// creates and updates run in kind order, then deletes in reverse kind order.
+// Live resources absent from the manifest are deleted only when prune is set.
+func Plan(desired, live []Resource, prune bool) []Change {
+	liveByID := map[string]*Resource{}
+	for i := range live {
+		liveByID[live[i].id()] = &live[i]
+	}
+
+	var changes []Change
+	matched := map[string]bool{}
+	for i := range desired {
+		d := &desired[i]
+		c := Change{Kind: d.Kind, Dataset: d.Dataset, Key: d.Key, Desired: d}
+		if l, ok := liveByID[d.id()]; ok {
+			matched[d.id()] = true
+			c.ID = l.ID
+			c.Live = l
+			c.Fields = Diff(d.Fields, l.Fields)
+			if len(c.Fields) > 0 {
+				c.Action = ActionUpdate
+			} else {
+				c.Action = ActionNoop
+			}
+		} else {
+			c.Action = ActionCreate
+			c.Fields = Diff(d.Fields, nil)
+		}
+		changes = append(changes, c)
+	}
+
+	if prune {
+		for i := range live {
+			l := &live[i]
+			if matched[l.id()] {
+				continue
+			}
+			changes = append(changes, Change{
+				Action:  ActionDelete,
+				Kind:    l.Kind,
+				Dataset: l.Dataset,
+				Key:     l.Key,
+				ID:      l.ID,
+				Live:    l,
+			})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		ci, cj := changes[i], changes[j]
+		di, dj := ci.Action == ActionDelete, cj.Action == ActionDelete
+		if di != dj {
+			return dj
+		}
+		if ci.Kind != cj.Kind {
+			if di {
+				return kindOrder[ci.Kind] > kindOrder[cj.Kind]
+			}
+			return kindOrder[ci.Kind] < kindOrder[cj.Kind]
+		}
+		if ci.Dataset != cj.Dataset {
+			return ci.Dataset < cj.Dataset
+		}
+		return ci.Key < cj.Key
+	})
+	return changes
+}
+
+// Diff returns the fields of desired whose values are not matched by live,
+// sorted by field name. Nested objects and lists match when every value the
+// manifest sets is present in live, so fields only the server fills in (such
+// as recipient IDs) do not count as differences.
+func Diff(desired, live map[string]any) []FieldChange {
+	var out []FieldChange
+	for k, v := range desired {
+		lv, ok := live[k]
+		if ok && matches(v, lv) {
+			continue
+		}
+		out = append(out, FieldChange{Field: k, Old: lv, New: v})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Field < out[j].Field })
+	return out
+}
+
+func matches(desired, live any) bool {
+	switch d := desired.(type) {
+	case map[string]any:
+		l, ok := live.(map[string]any)
+		if !ok {
+			return false
+		}
+		for k, v := range d {
+			lv, ok := l[k]
+			if !ok {
+				if v == nil || v == "" {
+					continue
+				}
+				return false
+			}
+			if !matches(v, lv) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		l, ok := live.([]any)
+		if !ok {
+			return len(d) == 0 && live == nil
+		}
+		if len(d) != len(l) {
+			return false
+		}
+		for i := range d {
+			if !matches(d[i], l[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return reflect.DeepEqual(desired, live)
+	}
+}
+
+// Summary counts changes by action.
+func Summary(changes []Change) map[string]int {
+	out := map[string]int{ActionCreate: 0, ActionUpdate: 0, ActionDelete: 0, ActionNoop: 0}
+	for _, c := range changes {
+		out[c.Action]++
+	}
+	return out
+}
+
+// FormatText renders changes as a human-readable plan, omitting no-ops.
+func FormatText(changes []Change) string {
+	var b strings.Builder
+	symbols := map[string]string{ActionCreate: "+", ActionUpdate: "~", ActionDelete: "-"}
+	for _, c := range changes {
+		if c.Action == ActionNoop {
+			continue
+		}
+		fmt.Fprintf(&b, "%s %s\n", symbols[c.Action], c.Ref())
+		for _, f := range c.Fields {
+			if c.Action == ActionCreate {
+				fmt.Fprintf(&b, "    %s: %s\n", f.Field, FormatValue(f.New))
+				continue
+			}
+			fmt.Fprintf(&b, "    %s: %s -> %s\n", f.Field, FormatValue(f.Old), FormatValue(f.New))
+		}
+	}
+	s := Summary(changes)
+	fmt.Fprintf(&b, "Plan: %d to create, %d to update, %d to delete, %d unchanged.\n",
+		s[ActionCreate], s[ActionUpdate], s[ActionDelete], s[ActionNoop])
+	return b.String()
+}
+
+// FormatValue renders a decoded JSON value for a plan: strings quoted,
+// whole numbers without a fraction and nil as (unset).
+func FormatValue(v any) string {
+	switch x := v.(type) {
+	case nil:
+		return "(unset)"
+	case string:
+		return fmt.Sprintf("%q", x)
+	default:
+		return fmt.Sprintf("%v", toJSONish(x))
+	}
+}
+
+func toJSONish(v any) any {
+	if f, ok := v.(float64); ok && f == float64(int64(f)) {
+		return int64(f)
+	}
+	return v
+}
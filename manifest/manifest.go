@@ -0,0 +1,233 @@
+// Package manifest loads declarative resource manifests and computes the
+// changes needed to bring live Honeycomb resources in line with them.
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/datafile"
+)
+
+// Resource kinds, in the order they must be created.
+const (
+	KindDerivedColumn = "derived_column"
+	KindSLO           = "slo"
+	KindBurnAlert     = "burn_alert"
+	KindMarkerSetting = "marker_setting"
+	KindBoard         = "board"
+)
+
+// kindOrder is the dependency order of resource kinds: derived columns are
+// referenced by SLOs, SLOs by burn alerts and boards.
+var kindOrder = map[string]int{
+	KindDerivedColumn: 0,
+	KindSLO:           1,
+	KindBurnAlert:     2,
+	KindMarkerSetting: 3,
+	KindBoard:         4,
+}
+
+// Document is the contents of one manifest file. Dataset-scoped resources
+// belong to Dataset; boards are environment-wide.
+type Document struct {
+	Dataset        string              `json:"dataset,omitempty"`
+	DerivedColumns []api.DerivedColumn `json:"derived_columns,omitempty"`
+	SLOs           []api.SLO           `json:"slos,omitempty"`
+	BurnAlerts     []BurnAlert         `json:"burn_alerts,omitempty"`
+	MarkerSettings []api.MarkerSetting `json:"marker_settings,omitempty"`
+	Boards         []api.Board         `json:"boards,omitempty"`
+}
+
+// rawDocument mirrors Document with each resource kept as the generic JSON
+// written in the file, so that only fields the manifest sets are managed.
+type rawDocument struct {
+	DerivedColumns []map[string]any `json:"derived_columns,omitempty"`
+	SLOs           []map[string]any `json:"slos,omitempty"`
+	BurnAlerts     []map[string]any `json:"burn_alerts,omitempty"`
+	MarkerSettings []map[string]any `json:"marker_settings,omitempty"`
+	Boards         []map[string]any `json:"boards,omitempty"`
+}
+
+// BurnAlert is a burn alert that names its SLO instead of referencing it by ID.
+type BurnAlert struct {
+	SLOName string `json:"slo_name"`
+	api.BurnAlert
+}
+
+// Resource is a single managed resource, identified by kind, dataset and a
+// stable key. Fields holds the resource as generic JSON.
+type Resource struct {
+	Kind    string
+	Dataset string
+	Key     string
+	ID      string
+	Fields  map[string]any
+}
+
+// Ref returns a human-readable identifier such as "slo api/Availability".
+func (r Resource) Ref() string {
+	if r.Dataset == "" {
+		return r.Kind + " " + r.Key
+	}
+	return r.Kind + " " + r.Dataset + "/" + r.Key
+}
+
+func (r Resource) id() string {
+	return r.Kind + "\x00" + r.Dataset + "\x00" + r.Key
+}
+
+// BurnAlertKey returns the stable key of a burn alert: its SLO name, alert
+// type and description.
+func BurnAlertKey(sloName, alertType, description string) string {
+	return sloName + "/" + alertType + "/" + description
+}
+
+// Load reads every .json, .yaml and .yml file under dir and returns the
+// resources they declare. Duplicate keys are an error.
+func Load(dir string) ([]Resource, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if !d.IsDir() && (ext == ".json" || datafile.IsYAML(path)) {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(paths)
+
+	var resources []Resource
+	seen := map[string]string{}
+	for _, path := range paths {
+		var doc Document
+		if err := datafile.Read(path, &doc); err != nil {
+			return nil, err
+		}
+		var raw rawDocument
+		if err := datafile.Read(path, &raw); err != nil {
+			return nil, err
+		}
+		rs, err := doc.resources(&raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, r := range rs {
+			if prev, ok := seen[r.id()]; ok {
+				return nil, fmt.Errorf("%s: %s is already declared in %s", path, r.Ref(), prev)
+			}
+			seen[r.id()] = path
+			resources = append(resources, r)
+		}
+	}
+	return resources, nil
+}
+
+// resources converts a document into resources, taking each resource's
+// managed fields from the matching entry in raw.
+func (d *Document) resources(raw *rawDocument) ([]Resource, error) {
+	needsDataset := len(d.DerivedColumns)+len(d.SLOs)+len(d.BurnAlerts)+len(d.MarkerSettings) > 0
+	if needsDataset && d.Dataset == "" {
+		return nil, fmt.Errorf("dataset is required for derived columns, SLOs, burn alerts and marker settings")
+	}
+
+	var out []Resource
+	add := func(kind, dataset, key string, fields map[string]any) error {
+		if key == "" {
+			return fmt.Errorf("%s is missing its key field", kind)
+		}
+		for k := range fields {
+			if ignoredFields[k] {
+				delete(fields, k)
+			}
+		}
+		out = append(out, Resource{Kind: kind, Dataset: dataset, Key: key, Fields: fields})
+		return nil
+	}
+
+	for i, dc := range d.DerivedColumns {
+		if err := add(KindDerivedColumn, d.Dataset, dc.Alias, raw.DerivedColumns[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i, slo := range d.SLOs {
+		if err := add(KindSLO, d.Dataset, slo.Name, raw.SLOs[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i, ba := range d.BurnAlerts {
+		if ba.SLOName == "" {
+			return nil, fmt.Errorf("burn alert %q is missing slo_name", ba.Description)
+		}
+		if ba.AlertType == "" {
+			ba.AlertType = "exhaustion_time"
+		}
+		if err := add(KindBurnAlert, d.Dataset, BurnAlertKey(ba.SLOName, ba.AlertType, ba.Description), raw.BurnAlerts[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i, ms := range d.MarkerSettings {
+		if err := add(KindMarkerSetting, d.Dataset, ms.Type, raw.MarkerSettings[i]); err != nil {
+			return nil, err
+		}
+	}
+	for i, b := range d.Boards {
+		if err := add(KindBoard, "", b.Name, raw.Boards[i]); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// ignoredFields are set by the server and never managed by manifests.
+var ignoredFields = map[string]bool{
+	"id":               true,
+	"created_at":       true,
+	"updated_at":       true,
+	"links":            true,
+	"slo":              true,
+	"triggered":        true,
+	"compliance":       true,
+	"budget_remaining": true,
+	"status":           true,
+	"burn_rate":        true,
+	"reset_at":         true,
+	"last_written":     true,
+}
+
+// ToFields converts v to generic JSON fields, dropping server-managed fields.
+func ToFields(v any) (map[string]any, error) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(buf, &fields); err != nil {
+		return nil, err
+	}
+	for k := range fields {
+		if ignoredFields[k] {
+			delete(fields, k)
+		}
+	}
+	return fields, nil
+}
+
+// Decode converts fields into v, typically an api struct.
+func Decode(fields map[string]any, v any) error {
+	buf, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(buf, v)
+}
@@ -0,0 +1,166 @@
+package manifest_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/LarsEckart/hccli/manifest"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "api.yaml", `
+dataset: api
+derived_columns:
+  - alias: sli_ok
+    expression: LT($status_code, 500)
+slos:
+  - name: Availability
+    sli: {alias: sli_ok}
+    time_period_days: 30
+    target_per_million: 999000
+burn_alerts:
+  - slo_name: Availability
+    exhaustion_minutes: 60
+    description: page
+`)
+	writeFile(t, dir, "boards.json", `{"boards":[{"name":"Overview","type":"flexible"}]}`)
+	writeFile(t, dir, "README.md", "ignored")
+
+	resources, err := manifest.Load(dir)
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	var refs []string
+	for _, r := range resources {
+		refs = append(refs, r.Ref())
+	}
+	want := "derived_column api/sli_ok,slo api/Availability,burn_alert api/Availability/exhaustion_time/page,board Overview"
+	if got := strings.Join(refs, ","); got != want {
+		t.Errorf("Load refs = %s, want %s", got, want)
+	}
+
+	slo := resources[1]
+	if _, ok := slo.Fields["description"]; ok {
+		t.Error("expected unset description to be unmanaged")
+	}
+	if slo.Fields["target_per_million"] != float64(999000) {
+		t.Errorf("unexpected target: %v", slo.Fields["target_per_million"])
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			"missing dataset",
+			map[string]string{"a.yaml": "derived_columns: [{alias: x, expression: INT(1)}]"},
+			"dataset is required",
+		},
+		{
+			"duplicate key",
+			map[string]string{
+				"a.yaml": "dataset: api\nslos: [{name: A}]",
+				"b.yaml": "dataset: api\nslos: [{name: A}]",
+			},
+			"already declared",
+		},
+		{
+			"burn alert without slo",
+			map[string]string{"a.yaml": "dataset: api\nburn_alerts: [{description: x}]"},
+			"missing slo_name",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeFile(t, dir, name, content)
+			}
+			_, err := manifest.Load(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Load error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	desired := map[string]any{
+		"name":       "A",
+		"target":     float64(999000),
+		"recipients": []any{map[string]any{"type": "email", "target": "a@b.com"}},
+	}
+	live := map[string]any{
+		"name":       "A",
+		"target":     float64(990000),
+		"recipients": []any{map[string]any{"id": "r-1", "type": "email", "target": "a@b.com"}},
+		"extra":      "ignored",
+	}
+
+	got := manifest.Diff(desired, live)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 field change, got %v", got)
+	}
+	if got[0].Field != "target" || got[0].Old != float64(990000) || got[0].New != float64(999000) {
+		t.Errorf("unexpected change: %+v", got[0])
+	}
+}
+
+func TestPlan(t *testing.T) {
+	res := func(kind, key string, fields map[string]any) manifest.Resource {
+		return manifest.Resource{Kind: kind, Dataset: "api", Key: key, ID: "id-" + key, Fields: fields}
+	}
+	desired := []manifest.Resource{
+		res(manifest.KindBurnAlert, "A/exhaustion_time/page", map[string]any{"exhaustion_minutes": float64(60)}),
+		res(manifest.KindSLO, "A", map[string]any{"target_per_million": float64(999000)}),
+		res(manifest.KindDerivedColumn, "sli", map[string]any{"expression": "INT(1)"}),
+	}
+	live := []manifest.Resource{
+		res(manifest.KindDerivedColumn, "sli", map[string]any{"expression": "INT(1)"}),
+		res(manifest.KindSLO, "A", map[string]any{"target_per_million": float64(990000)}),
+		res(manifest.KindDerivedColumn, "old", map[string]any{"expression": "INT(0)"}),
+		res(manifest.KindSLO, "B", map[string]any{}),
+	}
+
+	changes := manifest.Plan(desired, live, false)
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.Action+" "+c.Ref())
+	}
+	want := "no-op derived_column api/sli,update slo api/A,create burn_alert api/A/exhaustion_time/page"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Plan = %s, want %s", strings.Join(got, ","), want)
+	}
+
+	changes = manifest.Plan(desired, live, true)
+	got = nil
+	for _, c := range changes {
+		got = append(got, c.Action+" "+c.Ref())
+	}
+	want += ",delete slo api/B,delete derived_column api/old"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Plan with prune = %s, want %s", strings.Join(got, ","), want)
+	}
+
+	text := manifest.FormatText(changes)
+	for _, s := range []string{"~ slo api/A", "target_per_million: 990000 -> 999000", "Plan: 1 to create, 1 to update, 2 to delete, 1 unchanged."} {
+		if !strings.Contains(text, s) {
+			t.Errorf("expected text plan to contain %q, got:\n%s", s, text)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+// Package schema captures the columns and derived columns of a dataset in a
+// stable form and compares two captures.
+package schema
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/LarsEckart/hccli/api"
+)
+
+// Schema is the shape of a dataset at one point in time. Columns and
+// derived columns are sorted by name so that saved schemas diff cleanly.
+type Schema struct {
+	Dataset        string          `json:"dataset"`
+	Columns        []Column        `json:"columns"`
+	DerivedColumns []DerivedColumn `json:"derived_columns"`
+}
+
+type Column struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	Hidden      bool   `json:"hidden,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type DerivedColumn struct {
+	Alias       string `json:"alias"`
+	Expression  string `json:"expression"`
+	Description string `json:"description,omitempty"`
+}
+
+// Kinds of schema entries.
+const (
+	KindColumn        = "column"
+	KindDerivedColumn = "derived_column"
+)
+
+// Changes reported by Diff.
+const (
+	Added             = "added"
+	Removed           = "removed"
+	TypeChanged       = "type_changed"
+	ExpressionChanged = "expression_changed"
+)
+
+// Change is one difference between two schemas.
+type Change struct {
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Change string `json:"change"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+// New builds a sorted schema from API resources.
+func New(dataset string, columns []api.Column, derived []api.DerivedColumn) *Schema {
+	s := &Schema{Dataset: dataset, Columns: []Column{}, DerivedColumns: []DerivedColumn{}}
+	for _, c := range columns {
+		s.Columns = append(s.Columns, Column{
+			Name:        c.KeyName,
+			Type:        c.Type,
+			Hidden:      c.Hidden != nil && *c.Hidden,
+			Description: c.Description,
+		})
+	}
+	for _, dc := range derived {
+		s.DerivedColumns = append(s.DerivedColumns, DerivedColumn{
+			Alias:       dc.Alias,
+			Expression:  dc.Expression,
+			Description: dc.Description,
+		})
+	}
+	s.Sort()
+	return s
+}
+
+// Sort orders columns and derived columns by name.
+func (s *Schema) Sort() {
+	sort.Slice(s.Columns, func(i, j int) bool { return s.Columns[i].Name < s.Columns[j].Name })
+	sort.Slice(s.DerivedColumns, func(i, j int) bool { return s.DerivedColumns[i].Alias < s.DerivedColumns[j].Alias })
+}
+
+// Diff reports how next differs from base: columns added, removed or with
+// a different type, and derived columns added, removed or with a different
+// expression. Changes are sorted by kind and name.
+func Diff(base, next *Schema) []Change {
+	changes := []Change{}
+
+	baseCols := map[string]Column{}
+	for _, c := range base.Columns {
+		baseCols[c.Name] = c
+	}
+	nextCols := map[string]Column{}
+	for _, c := range next.Columns {
+		nextCols[c.Name] = c
+		old, ok := baseCols[c.Name]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Kind: KindColumn, Name: c.Name, Change: Added, New: c.Type})
+		case old.Type != c.Type:
+			changes = append(changes, Change{Kind: KindColumn, Name: c.Name, Change: TypeChanged, Old: old.Type, New: c.Type})
+		}
+	}
+	for _, c := range base.Columns {
+		if _, ok := nextCols[c.Name]; !ok {
+			changes = append(changes, Change{Kind: KindColumn, Name: c.Name, Change: Removed, Old: c.Type})
+		}
+	}
+
+	baseDerived := map[string]DerivedColumn{}
+	for _, dc := range base.DerivedColumns {
+		baseDerived[dc.Alias] = dc
+	}
+	nextDerived := map[string]bool{}
+	for _, dc := range next.DerivedColumns {
+		nextDerived[dc.Alias] = true
+		old, ok := baseDerived[dc.Alias]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Kind: KindDerivedColumn, Name: dc.Alias, Change: Added, New: dc.Expression})
+		case old.Expression != dc.Expression:
+			changes = append(changes, Change{Kind: KindDerivedColumn, Name: dc.Alias, Change: ExpressionChanged, Old: old.Expression, New: dc.Expression})
+		}
+	}
+	for _, dc := range base.DerivedColumns {
+		if !nextDerived[dc.Alias] {
+			changes = append(changes, Change{Kind: KindDerivedColumn, Name: dc.Alias, Change: Removed, Old: dc.Expression})
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind == KindColumn
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+// FormatText renders changes as a human-readable diff.
+func FormatText(baseName, nextName string, changes []Change) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", baseName, nextName)
+	if len(changes) == 0 {
+		b.WriteString("No schema changes.\n")
+		return b.String()
+	}
+	for _, c := range changes {
+		switch c.Change {
+		case Added:
+			fmt.Fprintf(&b, "+ %s %s%s\n", c.Kind, c.Name, detail(c.New))
+		case Removed:
+			fmt.Fprintf(&b, "- %s %s%s\n", c.Kind, c.Name, detail(c.Old))
+		default:
+			fmt.Fprintf(&b, "~ %s %s: %s -> %s\n", c.Kind, c.Name, c.Old, c.New)
+		}
+	}
+
+	counts := map[string]int{}
+	for _, c := range changes {
+		counts[c.Change]++
+	}
+	fmt.Fprintf(&b, "\n%d added, %d removed, %d changed.\n",
+		counts[Added], counts[Removed], counts[TypeChanged]+counts[ExpressionChanged])
+	return b.String()
+}
+
+func detail(s string) string {
+	if s == "" {
+		return ""
+	}
+	return " (" + s + ")"
+}
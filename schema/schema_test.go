@@ -0,0 +1,79 @@
+package schema_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/schema"
+)
+
+func TestNewSortsColumns(t *testing.T) {
+	hidden := true
+	s := schema.New("api", []api.Column{
+		{KeyName: "b", Type: "string"},
+		{KeyName: "a", Type: "integer", Hidden: &hidden},
+	}, []api.DerivedColumn{
+		{Alias: "z", Expression: "INT(1)"},
+		{Alias: "y", Expression: "INT(2)"},
+	})
+
+	if s.Columns[0].Name != "a" || !s.Columns[0].Hidden || s.Columns[1].Name != "b" {
+		t.Errorf("unexpected columns: %+v", s.Columns)
+	}
+	if s.DerivedColumns[0].Alias != "y" {
+		t.Errorf("unexpected derived columns: %+v", s.DerivedColumns)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	base := &schema.Schema{
+		Columns: []schema.Column{
+			{Name: "duration_ms", Type: "float"},
+			{Name: "status_code", Type: "integer"},
+			{Name: "user.id", Type: "string"},
+		},
+		DerivedColumns: []schema.DerivedColumn{
+			{Alias: "sli", Expression: "LT($status_code, 500)"},
+			{Alias: "old", Expression: "INT(1)"},
+		},
+	}
+	next := &schema.Schema{
+		Columns: []schema.Column{
+			{Name: "duration_ms", Type: "float"},
+			{Name: "status_code", Type: "string"},
+			{Name: "region", Type: "string"},
+		},
+		DerivedColumns: []schema.DerivedColumn{
+			{Alias: "sli", Expression: "LT($status_code, 400)"},
+		},
+	}
+
+	changes := schema.Diff(base, next)
+	var got []string
+	for _, c := range changes {
+		got = append(got, c.Change+" "+c.Kind+" "+c.Name)
+	}
+	want := "added column region,type_changed column status_code,removed column user.id," +
+		"removed derived_column old,expression_changed derived_column sli"
+	if strings.Join(got, ",") != want {
+		t.Errorf("Diff = %s, want %s", strings.Join(got, ","), want)
+	}
+
+	text := schema.FormatText("base", "next", changes)
+	for _, s := range []string{"--- base\n+++ next", "+ column region (string)", "- column user.id (string)", "~ column status_code: integer -> string", "1 added, 2 removed, 2 changed."} {
+		if !strings.Contains(text, s) {
+			t.Errorf("expected text diff to contain %q, got:\n%s", s, text)
+		}
+	}
+}
+
+func TestDiffIdentical(t *testing.T) {
+	s := &schema.Schema{Columns: []schema.Column{{Name: "a", Type: "string"}}}
+	if changes := schema.Diff(s, s); len(changes) != 0 {
+		t.Errorf("expected no changes, got %v", changes)
+	}
+	if text := schema.FormatText("a", "b", nil); !strings.Contains(text, "No schema changes.") {
+		t.Errorf("unexpected text: %s", text)
+	}
+}
@@ -0,0 +1,184 @@
+package snapshot
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/LarsEckart/hccli/api"
+)
+
+const (
+	chartWidth   = 800
+	chartHeight  = 240
+	chartPadLeft = 60
+	chartPadTop  = 10
+	chartPadBot  = 30
+	maxLines     = 10
+)
+
+var palette = []string{
+	"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd",
+	"#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf",
+}
+
+type point struct {
+	t time.Time
+	v float64
+}
+
+type line struct {
+	label  string
+	points []point
+}
+
+// seriesLines groups time series rows into one line per calculation and
+// breakdown combination. Numeric values are calculations; any other values
+// are treated as breakdown labels.
+func seriesLines(series []map[string]any) []line {
+	byLabel := map[string]*line{}
+	var order []string
+
+	for _, row := range series {
+		ts, _ := row["time"].(string)
+		t, err := time.Parse(time.RFC3339, ts)
+		if err != nil {
+			continue
+		}
+		data, _ := row["data"].(map[string]any)
+
+		var groups []string
+		for k, v := range data {
+			if _, ok := v.(float64); !ok && v != nil {
+				groups = append(groups, fmt.Sprintf("%s=%v", k, v))
+			}
+		}
+		sort.Strings(groups)
+		group := strings.Join(groups, ", ")
+
+		for k, v := range data {
+			f, ok := v.(float64)
+			if !ok {
+				continue
+			}
+			label := k
+			if group != "" {
+				label = k + " (" + group + ")"
+			}
+			l, ok := byLabel[label]
+			if !ok {
+				l = &line{label: label}
+				byLabel[label] = l
+				order = append(order, label)
+			}
+			l.points = append(l.points, point{t: t, v: f})
+		}
+	}
+
+	sort.Strings(order)
+	if len(order) > maxLines {
+		order = order[:maxLines]
+	}
+	lines := make([]line, 0, len(order))
+	for _, label := range order {
+		l := byLabel[label]
+		sort.Slice(l.points, func(i, j int) bool { return l.points[i].t.Before(l.points[j].t) })
+		lines = append(lines, *l)
+	}
+	return lines
+}
+
+// Chart renders the time series of a query result as an inline SVG line chart
+// spanning from..to, with markers drawn as vertical lines. It returns an empty
+// string when the result has no plottable series.
+func Chart(result *api.QueryResult, markers []api.Marker, from, to time.Time) string {
+	if result == nil {
+		return ""
+	}
+	lines := seriesLines(result.Data.Series)
+	if len(lines) == 0 {
+		return ""
+	}
+
+	maxV := 0.0
+	for _, l := range lines {
+		for _, p := range l.points {
+			maxV = math.Max(maxV, p.v)
+		}
+	}
+	if maxV == 0 {
+		maxV = 1
+	}
+
+	plotW := float64(chartWidth - chartPadLeft - 10)
+	plotH := float64(chartHeight - chartPadTop - chartPadBot)
+	span := to.Sub(from).Seconds()
+	if span <= 0 {
+		span = 1
+	}
+	x := func(t time.Time) float64 {
+		return chartPadLeft + plotW*t.Sub(from).Seconds()/span
+	}
+	y := func(v float64) float64 {
+		return chartPadTop + plotH*(1-v/maxV)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" class="chart">`, chartWidth, chartHeight)
+	fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" class="axis"/>`, chartPadLeft, y(0), chartWidth-10, y(0))
+	fmt.Fprintf(&b, `<line x1="%d" y1="%d" x2="%d" y2="%.1f" class="axis"/>`, chartPadLeft, chartPadTop, chartPadLeft, y(0))
+	fmt.Fprintf(&b, `<text x="%d" y="%.1f" class="label" text-anchor="end">%s</text>`, chartPadLeft-4, y(maxV)+4, formatNumber(maxV))
+	fmt.Fprintf(&b, `<text x="%d" y="%.1f" class="label" text-anchor="end">0</text>`, chartPadLeft-4, y(0)+4)
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="label">%s</text>`, chartPadLeft, chartHeight-8, from.UTC().Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, `<text x="%d" y="%d" class="label" text-anchor="end">%s</text>`, chartWidth-10, chartHeight-8, to.UTC().Format("2006-01-02 15:04"))
+
+	for _, m := range markers {
+		if m.StartTime == nil {
+			continue
+		}
+		mx := x(time.Unix(*m.StartTime, 0))
+		if mx < chartPadLeft || mx > chartWidth-10 {
+			continue
+		}
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%.1f" class="marker"><title>%s</title></line>`,
+			mx, chartPadTop, mx, y(0), html.EscapeString(markerLabel(m)))
+	}
+
+	for i, l := range lines {
+		pts := make([]string, 0, len(l.points))
+		for _, p := range l.points {
+			pts = append(pts, fmt.Sprintf("%.1f,%.1f", x(p.t), y(p.v)))
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="1.5" points="%s"><title>%s</title></polyline>`,
+			palette[i%len(palette)], strings.Join(pts, " "), html.EscapeString(l.label))
+	}
+	b.WriteString(`</svg>`)
+
+	b.WriteString(`<ul class="legend">`)
+	for i, l := range lines {
+		fmt.Fprintf(&b, `<li><span style="background:%s"></span>%s</li>`, palette[i%len(palette)], html.EscapeString(l.label))
+	}
+	b.WriteString(`</ul>`)
+	return b.String()
+}
+
+func markerLabel(m api.Marker) string {
+	parts := []string{}
+	if m.Type != "" {
+		parts = append(parts, m.Type)
+	}
+	if m.Message != "" {
+		parts = append(parts, m.Message)
+	}
+	return strings.Join(parts, ": ")
+}
+
+func formatNumber(v float64) string {
+	if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+		return fmt.Sprintf("%d", int64(v))
+	}
+	return fmt.Sprintf("%.3g", v)
+}
@@ -0,0 +1,130 @@
+package snapshot
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	headingRe    = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+	unorderedRe  = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	orderedRe    = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	codeSpanRe   = regexp.MustCompile("`([^`]+)`")
+	boldRe       = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	italicRe     = regexp.MustCompile(`\*([^*]+)\*`)
+	linkRe       = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	safeSchemeRe = regexp.MustCompile(`^(?i)(https?:|mailto:|#|/)`)
+)
+
+// Markdown renders the subset of Markdown used in board text panels
+// (headings, paragraphs, lists, fenced code, emphasis, inline code and links)
+// to HTML. All input is escaped, so the result is safe to embed.
+func Markdown(src string) string {
+	var b strings.Builder
+	var para []string
+	list := ""
+	inCode := false
+
+	flushPara := func() {
+		if len(para) > 0 {
+			b.WriteString("<p>" + inline(strings.Join(para, " ")) + "</p>\n")
+			para = nil
+		}
+	}
+	closeList := func() {
+		if list != "" {
+			b.WriteString("</" + list + ">\n")
+			list = ""
+		}
+	}
+	openList := func(tag string) {
+		if list != tag {
+			closeList()
+			b.WriteString("<" + tag + ">\n")
+			list = tag
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if inCode {
+				b.WriteString("</code></pre>\n")
+				inCode = false
+			} else {
+				flushPara()
+				closeList()
+				b.WriteString("<pre><code>")
+				inCode = true
+			}
+			continue
+		}
+		if inCode {
+			b.WriteString(html.EscapeString(line) + "\n")
+			continue
+		}
+
+		if strings.TrimSpace(line) == "" {
+			flushPara()
+			closeList()
+			continue
+		}
+		if m := headingRe.FindStringSubmatch(line); m != nil {
+			flushPara()
+			closeList()
+			level := strconv.Itoa(len(m[1]))
+			b.WriteString("<h" + level + ">" + inline(m[2]) + "</h" + level + ">\n")
+			continue
+		}
+		if m := unorderedRe.FindStringSubmatch(line); m != nil {
+			flushPara()
+			openList("ul")
+			b.WriteString("<li>" + inline(m[1]) + "</li>\n")
+			continue
+		}
+		if m := orderedRe.FindStringSubmatch(line); m != nil {
+			flushPara()
+			openList("ol")
+			b.WriteString("<li>" + inline(m[1]) + "</li>\n")
+			continue
+		}
+
+		closeList()
+		para = append(para, strings.TrimSpace(line))
+	}
+
+	if inCode {
+		b.WriteString("</code></pre>\n")
+	}
+	flushPara()
+	closeList()
+	return b.String()
+}
+
+// inline escapes s and applies inline formatting. Code spans are replaced
+// with placeholders first so that their contents are not formatted.
+func inline(s string) string {
+	s = html.EscapeString(s)
+
+	var spans []string
+	s = codeSpanRe.ReplaceAllStringFunc(s, func(m string) string {
+		spans = append(spans, "<code>"+m[1:len(m)-1]+"</code>")
+		return "\x00" + strconv.Itoa(len(spans)-1) + "\x00"
+	})
+
+	s = linkRe.ReplaceAllStringFunc(s, func(m string) string {
+		parts := linkRe.FindStringSubmatch(m)
+		if !safeSchemeRe.MatchString(parts[2]) {
+			return parts[1]
+		}
+		return `<a href="` + parts[2] + `">` + parts[1] + `</a>`
+	})
+	s = boldRe.ReplaceAllString(s, "<strong>$1</strong>")
+	s = italicRe.ReplaceAllString(s, "<em>$1</em>")
+
+	for i, span := range spans {
+		s = strings.Replace(s, "\x00"+strconv.Itoa(i)+"\x00", span, 1)
+	}
+	return s
+}
@@ -0,0 +1,176 @@
+// Package snapshot renders a point-in-time copy of a board as a single
+// self-contained HTML document.
+package snapshot
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/LarsEckart/hccli/api"
+)
+
+// Snapshot is the data captured for a board over a time window.
+type Snapshot struct {
+	Board       *api.Board
+	From        time.Time
+	To          time.Time
+	GeneratedAt time.Time
+	Panels      []Panel
+	Markers     []api.Marker
+}
+
+// Panel is a board panel together with the data fetched for it.
+// Error is set when fetching the panel's data failed.
+type Panel struct {
+	Type        string
+	Title       string
+	Description string
+	QueryStyle  string
+	Text        string
+	Result      *api.QueryResult
+	SLO         *api.SLO
+	Error       string
+}
+
+// Render writes s as an HTML document to w.
+func Render(w io.Writer, s *Snapshot) error {
+	return page.Execute(w, s)
+}
+
+var page = template.Must(template.New("snapshot").Funcs(template.FuncMap{
+	"chart": func(p Panel, s *Snapshot) template.HTML {
+		return template.HTML(Chart(p.Result, s.Markers, s.From, s.To))
+	},
+	"markdown": func(text string) template.HTML {
+		return template.HTML(Markdown(text))
+	},
+	"columns": resultColumns,
+	"cell": func(row map[string]any, col string) string {
+		data, _ := row["data"].(map[string]any)
+		v, ok := data[col]
+		if !ok || v == nil {
+			return ""
+		}
+		if f, ok := v.(float64); ok {
+			return formatNumber(f)
+		}
+		return fmt.Sprint(v)
+	},
+	"percent": func(v *float64) string {
+		if v == nil {
+			return "n/a"
+		}
+		return fmt.Sprintf("%.2f%%", *v)
+	},
+	"number": func(v *float64) string {
+		if v == nil {
+			return "n/a"
+		}
+		return formatNumber(*v)
+	},
+	"target": func(perMillion int) string {
+		return fmt.Sprintf("%.4g%%", float64(perMillion)/10000)
+	},
+	"unix": func(ts *int64) string {
+		if ts == nil {
+			return ""
+		}
+		return time.Unix(*ts, 0).UTC().Format("2006-01-02 15:04:05 UTC")
+	},
+	"utc": func(t time.Time) string {
+		return t.UTC().Format("2006-01-02 15:04:05 UTC")
+	},
+}).Parse(pageTemplate))
+
+// resultColumns returns the sorted set of keys across all result rows.
+func resultColumns(result *api.QueryResult) []string {
+	if result == nil {
+		return nil
+	}
+	seen := map[string]bool{}
+	var cols []string
+	for _, row := range result.Data.Results {
+		data, _ := row["data"].(map[string]any)
+		for k := range data {
+			if !seen[k] {
+				seen[k] = true
+				cols = append(cols, k)
+			}
+		}
+	}
+	sort.Strings(cols)
+	return cols
+}
+
+const pageTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Board.Name}} — snapshot</title>
+<style>
+body { font-family: -apple-system, BlinkMacSystemFont, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2em auto; max-width: 900px; color: #222; }
+header p { color: #555; margin: 0.2em 0; }
+section.panel { border: 1px solid #ddd; border-radius: 6px; padding: 1em; margin: 1em 0; }
+section.panel h2 { margin-top: 0; font-size: 1.1em; }
+.chart { width: 100%; height: auto; }
+.chart .axis { stroke: #999; }
+.chart .marker { stroke: #d62728; stroke-dasharray: 4 3; }
+.chart .label { font-size: 11px; fill: #555; }
+ul.legend { list-style: none; padding: 0; font-size: 0.85em; }
+ul.legend li { display: inline-block; margin-right: 1em; }
+ul.legend span { display: inline-block; width: 10px; height: 10px; margin-right: 4px; }
+table { border-collapse: collapse; font-size: 0.9em; width: 100%; }
+th, td { border: 1px solid #ddd; padding: 4px 8px; text-align: left; }
+th { background: #f5f5f5; }
+.error { color: #b00; }
+pre { background: #f5f5f5; padding: 0.5em; overflow-x: auto; }
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Board.Name}}</h1>
+{{with .Board.Description}}<p>{{.}}</p>{{end}}
+<p>Window: {{utc .From}} – {{utc .To}}</p>
+<p>Generated: {{utc .GeneratedAt}}</p>
+{{with .Board.Links}}<p><a href="{{.BoardURL}}">Open board in Honeycomb</a></p>{{end}}
+</header>
+{{$s := .}}
+{{range .Panels}}
+<section class="panel panel-{{.Type}}">
+{{with .Title}}<h2>{{.}}</h2>{{end}}
+{{with .Description}}<p>{{.}}</p>{{end}}
+{{if .Error}}<p class="error">{{.Error}}</p>
+{{else if eq .Type "text"}}{{markdown .Text}}
+{{else if eq .Type "slo"}}{{with .SLO}}
+<table>
+<tr><th>Target</th><td>{{target .TargetPerMillion}} over {{.TimePeriodDays}} days</td></tr>
+<tr><th>Compliance</th><td>{{percent .Compliance}}</td></tr>
+<tr><th>Budget remaining</th><td>{{percent .BudgetRemaining}}</td></tr>
+<tr><th>Burn rate</th><td>{{number .BurnRate}}</td></tr>
+<tr><th>Status</th><td>{{.Status}}</td></tr>
+</table>{{end}}
+{{else if eq .Type "query"}}
+{{if ne .QueryStyle "table"}}{{chart . $s}}{{end}}
+{{$cols := columns .Result}}{{if and $cols (ne .QueryStyle "graph")}}
+<table>
+<tr>{{range $cols}}<th>{{.}}</th>{{end}}</tr>
+{{range $row := .Result.Data.Results}}<tr>{{range $cols}}<td>{{cell $row .}}</td>{{end}}</tr>
+{{end}}</table>{{else if not $cols}}<p>No results in this window.</p>{{end}}
+{{end}}
+</section>
+{{end}}
+{{if .Markers}}
+<section class="panel">
+<h2>Markers</h2>
+<table>
+<tr><th>Time</th><th>Type</th><th>Message</th></tr>
+{{range .Markers}}<tr><td>{{unix .StartTime}}</td><td>{{.Type}}</td><td>{{if .URL}}<a href="{{.URL}}">{{.Message}}</a>{{else}}{{.Message}}{{end}}</td></tr>
+{{end}}</table>
+</section>
+{{end}}
+</body>
+</html>
+`
@@ -0,0 +1,106 @@
+package snapshot_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/LarsEckart/hccli/api"
+	"github.com/LarsEckart/hccli/snapshot"
+)
+
+func TestMarkdown(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"paragraph", "hello world", "<p>hello world</p>\n"},
+		{"joined lines", "one\ntwo", "<p>one two</p>\n"},
+		{"heading", "## Latency", "<h2>Latency</h2>\n"},
+		{"unordered list", "- a\n- b", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n"},
+		{"ordered list", "1. a\n2. b", "<ol>\n<li>a</li>\n<li>b</li>\n</ol>\n"},
+		{"bold and italic", "**bold** and *it*", "<p><strong>bold</strong> and <em>it</em></p>\n"},
+		{"code span", "run `a*b*c`", "<p>run <code>a*b*c</code></p>\n"},
+		{"link", "[docs](https://example.com)", `<p><a href="https://example.com">docs</a></p>` + "\n"},
+		{"unsafe link", "[x](javascript:void)", "<p>x</p>\n"},
+		{"escapes html", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{"fenced code", "```\n<b>\n```", "<pre><code>&lt;b&gt;\n</code></pre>\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := snapshot.Markdown(tt.input)
+			if got != tt.want {
+				t.Errorf("Markdown(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChart(t *testing.T) {
+	from := time.Date(2024, 2, 11, 18, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+	markerTS := from.Add(30 * time.Minute).Unix()
+
+	result := &api.QueryResult{Data: api.QueryData{Series: []map[string]any{
+		{"time": "2024-02-11T18:00:00Z", "data": map[string]any{"COUNT": 1.0, "service": "api"}},
+		{"time": "2024-02-11T18:30:00Z", "data": map[string]any{"COUNT": 4.0, "service": "api"}},
+		{"time": "2024-02-11T18:30:00Z", "data": map[string]any{"COUNT": 2.0, "service": "web"}},
+	}}}
+	markers := []api.Marker{{StartTime: &markerTS, Type: "deploy", Message: "v1 <b>"}}
+
+	svg := snapshot.Chart(result, markers, from, to)
+	if !strings.HasPrefix(svg, "<svg") {
+		t.Fatalf("expected svg output, got %q", svg)
+	}
+	if n := strings.Count(svg, "<polyline"); n != 2 {
+		t.Errorf("expected 2 lines (one per breakdown), got %d", n)
+	}
+	if !strings.Contains(svg, "COUNT (service=api)") {
+		t.Errorf("expected breakdown label in legend, got %q", svg)
+	}
+	if !strings.Contains(svg, `class="marker"`) || !strings.Contains(svg, "deploy: v1 &lt;b&gt;") {
+		t.Errorf("expected escaped marker line, got %q", svg)
+	}
+
+	if got := snapshot.Chart(&api.QueryResult{}, nil, from, to); got != "" {
+		t.Errorf("expected empty chart for empty series, got %q", got)
+	}
+}
+
+func TestRender(t *testing.T) {
+	compliance := 99.5
+	s := &snapshot.Snapshot{
+		Board: &api.Board{Name: "Checkout <prod>"},
+		From:  time.Unix(0, 0),
+		To:    time.Unix(3600, 0),
+		Panels: []snapshot.Panel{
+			{Type: "text", Text: "# Notes"},
+			{Type: "slo", Title: "availability", SLO: &api.SLO{TargetPerMillion: 999000, TimePeriodDays: 30, Compliance: &compliance}},
+			{Type: "query", Title: "errors", QueryStyle: "table", Result: &api.QueryResult{Data: api.QueryData{
+				Results: []map[string]any{{"data": map[string]any{"COUNT": 42.0}}},
+			}}},
+			{Type: "query", Title: "broken", Error: "API error (HTTP 500)"},
+		},
+	}
+
+	var b strings.Builder
+	if err := snapshot.Render(&b, s); err != nil {
+		t.Fatalf("Render returned error: %v", err)
+	}
+	out := b.String()
+
+	for _, want := range []string{
+		"Checkout &lt;prod&gt;",
+		"<h1>Notes</h1>",
+		"99.9%",
+		"99.50%",
+		"<td>42</td>",
+		"API error (HTTP 500)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected output to contain %q", want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestApplyColumns(t *testing.T) {
+	srv, writes := newRecordingServer(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/1/columns/api":
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":"c-new"}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/1/derived_columns/api":
+			if strings.Contains(string(body), `"alias":"broken"`) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				fmt.Fprint(w, `{"error":"invalid expression"}`)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":"dc-new"}`)
+		case r.Method == http.MethodPut:
+			w.Write(body)
+		case r.URL.Path == "/1/columns/api":
+			fmt.Fprint(w, `[
+				{"id":"c-1","key_name":"status_code","type":"integer","description":"HTTP status"},
+				{"id":"c-2","key_name":"duration_ms","type":"float"}
+			]`)
+		case r.URL.Path == "/1/derived_columns/api":
+			fmt.Fprint(w, `[
+				{"id":"dc-1","alias":"is_error","expression":"GTE($status_code, 500)","description":"Server errors"},
+				{"id":"dc-2","alias":"is_ok","expression":"LT($status_code, 400)","description":"Successful requests"}
+			]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	})
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "columns.yaml")
+	err := os.WriteFile(path, []byte(`columns:
+  - key_name: status_code
+    description: HTTP status
+  - key_name: duration_ms
+    description: Request duration
+    hidden: true
+  - key_name: route
+    type: string
+derived_columns:
+  - alias: is_slow_error
+    expression: AND($is_slow, $is_error)
+  - alias: is_slow
+    expression: GT($duration_ms, 1000)
+  - alias: is_error
+    expression: GTE($status_code, 500)
+  - alias: is_ok
+    expression: LT($status_code, 500)
+  - alias: typo
+    expression: GT($duraton_ms, 1)
+  - alias: broken
+    expression: INT($status_code)
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"apply-columns", "--dataset", "api", "--file", path)
+	if code == 0 {
+		t.Fatal("expected apply-columns to fail when items fail")
+	}
+	if !strings.Contains(stderr, "2 of 9 item(s) failed") {
+		t.Errorf("unexpected stderr: %s", stderr)
+	}
+
+	actions := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n")[1:] {
+		f := strings.Fields(line)
+		actions[f[0]+" "+f[1]] = f[2]
+	}
+	want := map[string]string{
+		"column status_code":           "unchanged",
+		"column duration_ms":           "updated",
+		"column route":                 "created",
+		"derived_column is_slow_error": "created",
+		"derived_column is_slow":       "created",
+		"derived_column is_error":      "unchanged",
+		"derived_column is_ok":         "updated",
+		"derived_column typo":          "failed",
+		"derived_column broken":        "failed",
+	}
+	for k, v := range want {
+		if actions[k] != v {
+			t.Errorf("%s: action %q, want %q\n%s", k, actions[k], v, stdout)
+		}
+	}
+	if !strings.Contains(stdout, `lint: 1:4: unknown column "duraton_ms"`) {
+		t.Errorf("expected the lint error in the table:\n%s", stdout)
+	}
+
+	var derivedCreates []string
+	for _, w := range writes() {
+		if body, ok := strings.CutPrefix(w, "POST /1/derived_columns/api "); ok {
+			derivedCreates = append(derivedCreates, body)
+		}
+	}
+	order := map[string]int{}
+	for i, body := range derivedCreates {
+		for _, alias := range []string{"is_slow", "is_slow_error"} {
+			if strings.Contains(body, `"alias":"`+alias+`"`) {
+				order[alias] = i + 1
+			}
+		}
+	}
+	if order["is_slow"] == 0 || order["is_slow_error"] < order["is_slow"] {
+		t.Errorf("expected is_slow to be created before is_slow_error: %v", derivedCreates)
+	}
+	var put string
+	for _, w := range writes() {
+		if strings.HasPrefix(w, "PUT /1/columns/api/c-2 ") {
+			put = w
+		}
+	}
+	if !strings.Contains(put, `"type":"float"`) || !strings.Contains(put, `"hidden":true`) || !strings.Contains(put, `"description":"Request duration"`) {
+		t.Errorf("expected merged column update, got %q", put)
+	}
+	var derivedPut string
+	for _, w := range writes() {
+		if strings.HasPrefix(w, "PUT /1/derived_columns/api/dc-2 ") {
+			derivedPut = w
+		}
+	}
+	if !strings.Contains(derivedPut, `"description":"Successful requests"`) {
+		t.Errorf("expected the live derived column description to be kept, got %q", derivedPut)
+	}
+}
@@ -0,0 +1,185 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newBackupServer serves a small environment with one dataset holding one
+// of each exportable resource, or an empty environment when populated is
+// false, and records every mutating request.
+func newBackupServer(t *testing.T, populated bool) (*httptest.Server, func() []string) {
+	t.Helper()
+	return newRecordingServer(t, func(w http.ResponseWriter, r *http.Request, _ []byte) {
+		if r.Method != http.MethodGet {
+			fmt.Fprint(w, `{"id":"new"}`)
+			return
+		}
+		if !populated {
+			if strings.HasPrefix(r.URL.Path, "/1/datasets/") {
+				http.NotFound(w, r)
+				return
+			}
+			fmt.Fprint(w, `[]`)
+			return
+		}
+		switch r.URL.Path {
+		case "/1/datasets":
+			fmt.Fprint(w, `[{"name":"api","slug":"api"}]`)
+		case "/1/derived_columns/__all__":
+			http.Error(w, `{"error":"not supported"}`, http.StatusNotFound)
+		case "/1/columns/api":
+			fmt.Fprint(w, `[{"id":"c-1","key_name":"status_code","type":"integer"}]`)
+		case "/1/derived_columns/api":
+			fmt.Fprint(w, `[{"id":"dc-1","alias":"sli_ok","expression":"LT($status_code, 500)"}]`)
+		case "/1/query_annotations/api":
+			fmt.Fprint(w, `[{"id":"qa-1","name":"Errors","query_id":"q-1"}]`)
+		case "/1/queries/api/q-1":
+			fmt.Fprint(w, `{"id":"q-1","calculations":[{"op":"COUNT"}]}`)
+		case "/1/marker_settings/api":
+			fmt.Fprint(w, `[{"id":"ms-1","type":"deploy","color":"#00ff00"}]`)
+		case "/1/slos/api":
+			fmt.Fprint(w, `[{"id":"slo-1","name":"Availability","sli":{"alias":"sli_ok"},"time_period_days":30,"target_per_million":999000}]`)
+		case "/1/burn_alerts/api":
+			fmt.Fprint(w, `[{"id":"ba-1","alert_type":"exhaustion_time","exhaustion_minutes":60,"description":"page"}]`)
+		case "/1/boards":
+			fmt.Fprint(w, `[{"id":"b-1","name":"Overview","type":"flexible"}]`)
+		case "/1/boards/b-1":
+			fmt.Fprint(w, `{"id":"b-1","name":"Overview","type":"flexible","panels":[{"type":"query","query_panel":{"query_id":"q-1","query_annotation_id":"qa-1"}},{"type":"slo","slo_panel":{"slo_id":"slo-1"}}]}`)
+		case "/1/boards/b-1/views":
+			fmt.Fprint(w, `[{"id":"v-1","name":"errors","filters":[{"column":"error","operation":"exists"}]}]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	})
+}
+
+func TestExportAllAndRestoreDryRun(t *testing.T) {
+	srv, _ := newBackupServer(t, true)
+	defer srv.Close()
+	target, writes := newBackupServer(t, false)
+	defer target.Close()
+	dir := t.TempDir()
+
+	stdout, stderr, code := runCLI(t,
+		"--api-key", "fake-key",
+		"--api-url", srv.URL,
+		"export-all", "--dir", dir,
+	)
+	if code != 0 {
+		t.Fatalf("export-all failed with exit code %d\nstderr: %s", code, stderr)
+	}
+
+	manifest := parseJSON(t, stdout)
+	counts := manifest["counts"].(map[string]any)
+	for kind, want := range map[string]float64{
+		"datasets": 1, "columns": 1, "derived_columns": 1, "query_annotations": 1,
+		"marker_settings": 1, "slos": 1, "burn_alerts": 1, "boards": 1,
+	} {
+		if counts[kind] != want {
+			t.Errorf("expected %s count %v, got %v", kind, want, counts[kind])
+		}
+	}
+	if !strings.Contains(stderr, "Skipping environment-wide resources") {
+		t.Errorf("expected environment-wide skip warning, got: %s", stderr)
+	}
+	for _, rel := range []string{"manifest.json", "datasets/api/slos.json", "boards/b-1.json"} {
+		if _, err := os.Stat(filepath.Join(dir, rel)); err != nil {
+			t.Errorf("expected %s to exist: %v", rel, err)
+		}
+	}
+
+	stdout, stderr, code = runCLI(t,
+		"--api-key", "fake-key",
+		"--api-url", target.URL,
+		"restore", "--dir", dir, "--dry-run",
+	)
+	if code != 0 {
+		t.Fatalf("restore --dry-run failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if w := writes(); len(w) != 0 {
+		t.Errorf("expected no mutating requests in dry-run, got %v", w)
+	}
+
+	actions := parseJSONArray(t, stdout)
+	var order []string
+	for _, a := range actions {
+		m := a.(map[string]any)
+		if m["action"] == "create" {
+			order = append(order, m["kind"].(string))
+		}
+	}
+	want := "dataset,column,derived_column,query_annotation,marker_setting,slo,burn_alert,board"
+	if strings.Join(order, ",") != want {
+		t.Errorf("expected creation order %s, got %s", want, strings.Join(order, ","))
+	}
+}
+
+func TestRestoreRejectsTamperedExport(t *testing.T) {
+	srv, _ := newBackupServer(t, true)
+	defer srv.Close()
+	dir := t.TempDir()
+
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "export-all", "--dir", dir, "--format", "yaml")
+	if code != 0 {
+		t.Fatalf("export-all failed with exit code %d\nstderr: %s", code, stderr)
+	}
+
+	path := filepath.Join(dir, "datasets", "api", "slos.yaml")
+	if err := os.WriteFile(path, []byte("[]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr, code = runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "restore", "--dir", dir, "--dry-run")
+	if code == 0 {
+		t.Fatal("expected non-zero exit code for tampered export")
+	}
+	if !strings.Contains(stderr, "checksum mismatch") {
+		t.Errorf("expected checksum mismatch error, got: %s", stderr)
+	}
+}
+
+func TestRestoreExitsNonZeroOnFailedItems(t *testing.T) {
+	srv, _ := newBackupServer(t, true)
+	defer srv.Close()
+	target, _ := newRecordingServer(t, func(w http.ResponseWriter, r *http.Request, _ []byte) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/1/slos/"):
+			http.Error(w, `{"error":"invalid SLI"}`, http.StatusUnprocessableEntity)
+		case r.Method != http.MethodGet:
+			fmt.Fprint(w, `{"id":"new"}`)
+		case strings.HasPrefix(r.URL.Path, "/1/datasets/"):
+			http.NotFound(w, r)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	})
+	defer target.Close()
+	dir := t.TempDir()
+
+	if _, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "export-all", "--dir", dir); code != 0 {
+		t.Fatalf("export-all failed with exit code %d\nstderr: %s", code, stderr)
+	}
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", target.URL, "restore", "--dir", dir)
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d\nstderr: %s", code, stderr)
+	}
+	if !strings.Contains(stderr, "failed to restore") {
+		t.Errorf("expected failure summary on stderr, got: %s", stderr)
+	}
+	var failed []string
+	for _, a := range parseJSONArray(t, stdout) {
+		if m := a.(map[string]any); m["action"] == "error" {
+			failed = append(failed, m["kind"].(string))
+		}
+	}
+	if len(failed) == 0 || failed[0] != "slo" {
+		t.Errorf("expected the SLO to be reported as an error, got %v", failed)
+	}
+}
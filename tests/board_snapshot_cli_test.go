@@ -0,0 +1,90 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshotBoardWritesHTML(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/1/boards/b-1":
+			fmt.Fprint(w, `{"id":"b-1","name":"Checkout","type":"flexible","panels":[
+				{"type":"text","text_panel":{"content":"## Runbook\nCheck **payments**"},"position":{"x_coordinate":0,"y_coordinate":0,"height":2,"width":6}},
+				{"type":"query","query_panel":{"query_id":"q-1","query_annotation_id":"qa-1","query_style":"combo"},"position":{"x_coordinate":0,"y_coordinate":2,"height":4,"width":6}},
+				{"type":"slo","slo_panel":{"slo_id":"slo-1"},"position":{"x_coordinate":6,"y_coordinate":2,"height":4,"width":6}}
+			]}`)
+		case r.URL.Path == "/1/markers/__all__":
+			fmt.Fprint(w, `[{"id":"m-1","start_time":1707675000,"type":"deploy","message":"v42"},{"id":"m-2","start_time":1600000000,"message":"old"}]`)
+		case r.URL.Path == "/1/query_annotations/__all__/qa-1":
+			fmt.Fprint(w, `{"id":"qa-1","name":"Error count","query_id":"q-1"}`)
+		case r.URL.Path == "/1/queries/__all__/q-1":
+			fmt.Fprint(w, `{"id":"q-1","calculations":[{"op":"COUNT"}],"time_range":7200}`)
+		case r.URL.Path == "/1/queries/__all__" && r.Method == http.MethodPost:
+			fmt.Fprint(w, `{"id":"q-2","calculations":[{"op":"COUNT"}],"start_time":1707674400,"end_time":1707678000}`)
+		case r.URL.Path == "/1/query_results/__all__":
+			fmt.Fprint(w, `{"id":"r-1","complete":true,"data":{
+				"series":[{"time":"2024-02-11T18:00:00Z","data":{"COUNT":3}},{"time":"2024-02-11T18:30:00Z","data":{"COUNT":9}}],
+				"results":[{"data":{"COUNT":12}}]}}`)
+		case r.URL.Path == "/1/slos/__all__/slo-1":
+			fmt.Fprint(w, `{"id":"slo-1","name":"Checkout availability","sli":{"alias":"sli"},"time_period_days":30,"target_per_million":999000,"compliance":99.95,"budget_remaining":50.5,"status":"ok"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	out := filepath.Join(t.TempDir(), "snapshot.html")
+	stdout, stderr, code := runCLI(t,
+		"--api-key", "fake-key",
+		"--api-url", srv.URL,
+		"snapshot-board",
+		"--id", "b-1",
+		"--from", "2024-02-11 18:00",
+		"--to", "2024-02-11 19:00",
+		"--output", out,
+	)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", code, stderr)
+	}
+
+	summary := parseJSON(t, stdout)
+	if summary["panels"] != float64(3) {
+		t.Errorf("expected 3 panels, got %v", summary["panels"])
+	}
+	if summary["markers"] != float64(1) {
+		t.Errorf("expected 1 marker in window, got %v", summary["markers"])
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("failed to read snapshot: %v", err)
+	}
+	html := string(data)
+	for _, want := range []string{
+		"<h2>Runbook</h2>",
+		"<strong>payments</strong>",
+		"Error count",
+		"<polyline",
+		"<td>12</td>",
+		"Checkout availability",
+		"99.95%",
+		"v42",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected snapshot to contain %q", want)
+		}
+	}
+	if strings.Contains(html, "<script") || strings.Contains(html, "<link") {
+		t.Error("expected snapshot without external resources")
+	}
+	if strings.Index(html, "Runbook") > strings.Index(html, "Error count") {
+		t.Error("expected panels ordered by position")
+	}
+}
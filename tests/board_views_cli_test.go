@@ -1,6 +1,12 @@
 package main_test
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -16,7 +22,7 @@ func createTestBoard(t *testing.T) string {
 
 func deleteTestBoard(t *testing.T, id string) {
 	t.Helper()
-	runCLIWithKey(t, "delete-board", "--id", id)
+	runCLIWithKey(t, "delete-board", "--id", id, "--yes")
 }
 
 func TestCreateBoardViewCLI_Smoke(t *testing.T) {
@@ -155,7 +161,7 @@ func TestDeleteBoardViewCLI_Smoke(t *testing.T) {
 	view := parseJSON(t, stdout)
 	viewID := view["id"].(string)
 
-	_, _, exitCode = runCLIWithKey(t, "delete-board-view", "--board-id", boardID, "--view-id", viewID)
+	_, _, exitCode = runCLIWithKey(t, "delete-board-view", "--board-id", boardID, "--view-id", viewID, "--yes")
 	if exitCode != 0 {
 		t.Fatalf("delete-board-view failed with exit code %d", exitCode)
 	}
@@ -165,3 +171,98 @@ func TestDeleteBoardViewCLI_Smoke(t *testing.T) {
 		t.Error("expected non-zero exit code when getting deleted view")
 	}
 }
+
+func TestCreateBoardViewMultipleFilters(t *testing.T) {
+	var got map[string]any
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &got)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"v-1","name":"multi"}`)
+	}))
+	defer srv.Close()
+
+	_, stderr, code := runCLI(t,
+		"--api-key", "fake-key",
+		"--api-url", srv.URL,
+		"create-board-view",
+		"--board-id", "b-1",
+		"--name", "multi",
+		"--filter", "status = 200",
+		"--filter", "name exists",
+		"--filters-json", `[{"column":"service.name","operation":"in","value":["api","web"]}]`,
+	)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", code, stderr)
+	}
+
+	filters, ok := got["filters"].([]any)
+	if !ok || len(filters) != 3 {
+		t.Fatalf("expected 3 filters in request, got %v", got["filters"])
+	}
+	first := filters[0].(map[string]any)
+	if first["column"] != "status" || first["operation"] != "=" || first["value"] != "200" {
+		t.Errorf("unexpected first filter: %v", first)
+	}
+	second := filters[1].(map[string]any)
+	if _, hasValue := second["value"]; hasValue || second["operation"] != "exists" {
+		t.Errorf("unexpected second filter: %v", second)
+	}
+}
+
+func TestCreateBoardViewRequiresFilter(t *testing.T) {
+	_, stderr, code := runCLI(t,
+		"--api-key", "fake-key",
+		"--api-url", "http://127.0.0.1:0",
+		"create-board-view",
+		"--board-id", "b-1",
+		"--name", "empty",
+	)
+	if code == 0 {
+		t.Fatal("expected non-zero exit code without filters")
+	}
+	if !strings.Contains(stderr, "at least one filter") {
+		t.Errorf("expected missing filter error, got: %s", stderr)
+	}
+}
+
+func TestCopyBoardViews(t *testing.T) {
+	srv, writes := newRecordingServer(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/1/boards/src/views":
+			fmt.Fprint(w, `[{"id":"a","name":"errors","filters":[{"column":"error","operation":"exists"}]},{"id":"b","name":"slow","filters":[{"column":"duration_ms","operation":">","value":"500"}]}]`)
+		case r.Method == http.MethodPost && r.URL.Path == "/1/boards/dst/views":
+			var v map[string]any
+			json.Unmarshal(body, &v)
+			fmt.Fprintf(w, `{"id":"new-%s","name":%q}`, v["name"], v["name"])
+		default:
+			http.NotFound(w, r)
+		}
+	})
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t,
+		"--api-key", "fake-key",
+		"--api-url", srv.URL,
+		"copy-board-views", "--from-board-id", "src", "--to-board-id", "dst",
+	)
+	if code != 0 {
+		t.Fatalf("expected exit code 0, got %d\nstderr: %s", code, stderr)
+	}
+
+	arr := parseJSONArray(t, stdout)
+	if len(arr) != 2 {
+		t.Fatalf("expected 2 copied views, got %d", len(arr))
+	}
+	var created []string
+	for _, w := range writes() {
+		v := requestBody(t, w)
+		if _, hasID := v["id"]; hasID {
+			t.Errorf("expected copied view without id, got %v", v)
+		}
+		created = append(created, v["name"].(string))
+	}
+	if strings.Join(created, ",") != "errors,slow" {
+		t.Errorf("expected views created in order, got %v", created)
+	}
+}
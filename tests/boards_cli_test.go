@@ -31,7 +31,7 @@ func TestCreateAndDeleteBoardCLI_Smoke(t *testing.T) {
 		t.Errorf("expected name 'hccli smoke test board', got %v", board["name"])
 	}
 
-	_, _, exitCode = runCLIWithKey(t, "delete-board", "--id", id)
+	_, _, exitCode = runCLIWithKey(t, "delete-board", "--id", id, "--yes")
 	if exitCode != 0 {
 		t.Fatalf("delete-board failed with exit code %d", exitCode)
 	}
@@ -44,7 +44,7 @@ func TestGetBoardCLI_Smoke(t *testing.T) {
 	}
 	board := parseJSON(t, stdout)
 	id := board["id"].(string)
-	defer runCLIWithKey(t, "delete-board", "--id", id)
+	defer runCLIWithKey(t, "delete-board", "--id", id, "--yes")
 
 	stdout, _, exitCode = runCLIWithKey(t, "get-board", "--id", id)
 	if exitCode != 0 {
@@ -64,7 +64,7 @@ func TestUpdateBoardCLI_Smoke(t *testing.T) {
 	}
 	board := parseJSON(t, stdout)
 	id := board["id"].(string)
-	defer runCLIWithKey(t, "delete-board", "--id", id)
+	defer runCLIWithKey(t, "delete-board", "--id", id, "--yes")
 
 	stdout, _, exitCode = runCLIWithKey(t, "update-board", "--id", id, "--name", "updated name", "--description", "updated desc")
 	if exitCode != 0 {
@@ -87,7 +87,7 @@ func TestUpdateBoardPanelsJsonCLI_Smoke(t *testing.T) {
 	}
 	board := parseJSON(t, stdout)
 	id := board["id"].(string)
-	defer runCLIWithKey(t, "delete-board", "--id", id)
+	defer runCLIWithKey(t, "delete-board", "--id", id, "--yes")
 
 	panelsJSON := `[{"type":"text","text_panel":{"content":"hello"}}]`
 	stdout, _, exitCode = runCLIWithKey(t, "update-board", "--id", id, "--name", "hccli panels-json test", "--panels-json", panelsJSON)
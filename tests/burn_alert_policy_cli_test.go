@@ -0,0 +1,121 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newBurnAlertPolicyServer serves a 30-day SLO with one burn alert that
+// already matches the standard fast burn alert and one outdated alert.
+func newBurnAlertPolicyServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	return newRecordingServer(t, func(w http.ResponseWriter, r *http.Request, _ []byte) {
+		if r.Method != http.MethodGet {
+			fmt.Fprint(w, `{"id":"ba-new"}`)
+			return
+		}
+		switch r.URL.Path {
+		case "/1/slos/api/slo-1":
+			fmt.Fprint(w, `{"id":"slo-1","name":"Availability","target_per_million":999000,"time_period_days":30}`)
+		case "/1/burn_alerts/api":
+			fmt.Fprint(w, `[
+				{"id":"ba-1","description":"standard: fast burn (page)","alert_type":"budget_rate","budget_rate_window_minutes":60,"budget_rate_decrease_threshold_per_million":20000,"recipients":[{"id":"pd1","type":"pagerduty"}]},
+				{"id":"ba-2","description":"standard: medium burn (page)","alert_type":"budget_rate","budget_rate_window_minutes":360,"budget_rate_decrease_threshold_per_million":10000,"recipients":[{"id":"pd1","type":"pagerduty"}]}
+			]`)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func TestCreateBurnAlertPolicyStandard(t *testing.T) {
+	srv, writes := newBurnAlertPolicyServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"create-burn-alert-policy", "--dataset", "api", "--slo-id", "slo-1",
+		"--recipients-json", `{"page":[{"id":"pd1"}],"ticket":[{"type":"email","target":"team@example.com"}]}`)
+	if code != 0 {
+		t.Fatalf("create-burn-alert-policy failed with exit code %d\nstderr: %s", code, stderr)
+	}
+
+	actions := map[string]string{}
+	for _, r := range parseJSONArray(t, stdout) {
+		m := r.(map[string]any)
+		actions[m["description"].(string)] = m["action"].(string)
+	}
+	want := map[string]string{
+		"standard: fast burn (page)":                "unchanged",
+		"standard: medium burn (page)":              "updated",
+		"standard: slow burn (ticket)":              "created",
+		"standard: exhausted within 4 hours (page)": "created",
+		"standard: exhausted within 1 day (ticket)": "created",
+	}
+	for desc, action := range want {
+		if actions[desc] != action {
+			t.Errorf("%s: action = %q, want %q", desc, actions[desc], action)
+		}
+	}
+
+	w := writes()
+	if len(w) != 4 {
+		t.Fatalf("expected 4 mutating requests, got %v", w)
+	}
+	if !strings.HasPrefix(w[0], "PUT /1/burn_alerts/api/ba-2 ") || !strings.Contains(w[0], `"budget_rate_decrease_threshold_per_million":50000`) {
+		t.Errorf("unexpected update request: %s", w[0])
+	}
+	created := requestBody(t, w[1])
+	if created["budget_rate_decrease_threshold_per_million"] != float64(100000) || created["slo"].(map[string]any)["id"] != "slo-1" {
+		t.Errorf("unexpected slow burn alert: %v", created)
+	}
+	if recipients := created["recipients"].([]any); recipients[0].(map[string]any)["target"] != "team@example.com" {
+		t.Errorf("expected ticket recipients on slow burn alert, got %v", recipients)
+	}
+}
+
+func TestCreateBurnAlertPolicyFile(t *testing.T) {
+	srv, writes := newBurnAlertPolicyServer(t)
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "policy.yaml")
+	policy := `
+name: custom
+alerts:
+  - description: "custom: burning"
+    severity: ticket
+    alert_type: exhaustion_time
+    burn_rate: 30
+`
+	if err := os.WriteFile(path, []byte(policy), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"create-burn-alert-policy", "--dataset", "api", "--slo-id", "slo-1", "--policy-file", path)
+	if code != 0 {
+		t.Fatalf("create-burn-alert-policy failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if !strings.Contains(stderr, `No recipients for severity "ticket"`) {
+		t.Errorf("expected missing recipients warning, got: %s", stderr)
+	}
+	results := parseJSONArray(t, stdout)
+	if len(results) != 1 || results[0].(map[string]any)["exhaustion_minutes"] != float64(1440) {
+		t.Errorf("unexpected results: %v", results)
+	}
+	if w := writes(); len(w) != 1 || !strings.HasPrefix(w[0], "POST /1/burn_alerts/api ") {
+		t.Errorf("expected one created alert, got %v", w)
+	}
+}
+
+func TestCreateBurnAlertPolicyUnknown(t *testing.T) {
+	_, stderr, code := runCLI(t, "--api-key", "fake-key",
+		"create-burn-alert-policy", "--dataset", "api", "--slo-id", "slo-1", "--policy", "nope")
+	if code == 0 || !strings.Contains(stderr, `unknown policy "nope" (available: standard)`) {
+		t.Errorf("expected unknown policy error, got code %d, stderr: %s", code, stderr)
+	}
+}
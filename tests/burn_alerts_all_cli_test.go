@@ -0,0 +1,70 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newBurnAlertCoverageServer serves three SLOs: one with a complete burn
+// alert, one with an alert lacking recipients and one without alerts.
+func newBurnAlertCoverageServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/1/slos/api":
+			fmt.Fprint(w, `[{"id":"slo-1","name":"Availability"},{"id":"slo-2","name":"Latency"},{"id":"slo-3","name":"Checkout"}]`)
+		case "/1/burn_alerts/api":
+			switch r.URL.Query().Get("slo_id") {
+			case "slo-1":
+				fmt.Fprint(w, `[{"id":"ba-1","description":"page","slo":{"id":"slo-1"},"recipients":[{"id":"r-1"}]}]`)
+			case "slo-2":
+				fmt.Fprint(w, `[{"id":"ba-2","description":"ticket","slo":{"id":"slo-2"}}]`)
+			default:
+				fmt.Fprint(w, `[]`)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestBurnAlertsAllSLOs(t *testing.T) {
+	srv := newBurnAlertCoverageServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "burn-alerts", "--dataset", "api")
+	if code != 0 {
+		t.Fatalf("burn-alerts failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	alerts := parseJSONArray(t, stdout)
+	if len(alerts) != 2 {
+		t.Fatalf("expected 2 burn alerts, got %v", alerts)
+	}
+	first := alerts[0].(map[string]any)
+	if first["id"] != "ba-1" || first["slo_name"] != "Availability" {
+		t.Errorf("expected alert annotated with its SLO name, got %v", first)
+	}
+}
+
+func TestBurnAlertsCoverageAudit(t *testing.T) {
+	srv := newBurnAlertCoverageServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"burn-alerts", "--dataset", "api", "--missing", "--no-recipients")
+	if code != 2 {
+		t.Fatalf("expected exit code 2 with findings, got %d\nstderr: %s", code, stderr)
+	}
+	var got []string
+	for _, f := range parseJSONArray(t, stdout) {
+		m := f.(map[string]any)
+		got = append(got, m["slo_name"].(string)+": "+m["issue"].(string))
+	}
+	if want := "Latency: no recipients,Checkout: no burn alerts"; strings.Join(got, ",") != want {
+		t.Errorf("findings = %s, want %s", strings.Join(got, ","), want)
+	}
+}
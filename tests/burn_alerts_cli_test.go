@@ -21,7 +21,7 @@ func TestBurnAlertCRUDCLI_Smoke(t *testing.T) {
 	dc := parseJSON(t, stdout)
 	dcID := dc["id"].(string)
 	defer func() {
-		runCLIWithKey(t, "delete-derived-column", "--dataset", dataset, "--id", dcID)
+		runCLIWithKey(t, "delete-derived-column", "--dataset", dataset, "--id", dcID, "--yes")
 	}()
 
 	// Create SLO
@@ -39,7 +39,7 @@ func TestBurnAlertCRUDCLI_Smoke(t *testing.T) {
 	slo := parseJSON(t, stdout)
 	sloID := slo["id"].(string)
 	defer func() {
-		runCLIWithKey(t, "delete-slo", "--dataset", dataset, "--id", sloID)
+		runCLIWithKey(t, "delete-slo", "--dataset", dataset, "--id", sloID, "--yes")
 	}()
 
 	recipientsJSON := `[{"type":"email","target":"hccli-test@example.com"}]`
@@ -69,7 +69,7 @@ func TestBurnAlertCRUDCLI_Smoke(t *testing.T) {
 		t.Errorf("expected description 'test burn alert', got %v", ba["description"])
 	}
 	defer func() {
-		runCLIWithKey(t, "delete-burn-alert", "--dataset", dataset, "--id", baID)
+		runCLIWithKey(t, "delete-burn-alert", "--dataset", dataset, "--id", baID, "--yes")
 	}()
 
 	// Get burn alert
@@ -127,7 +127,7 @@ func TestBurnAlertCRUDCLI_Smoke(t *testing.T) {
 	}
 
 	// Delete burn alert (explicit, before defer)
-	_, stderr, exitCode := runCLIWithKey(t, "delete-burn-alert", "--dataset", dataset, "--id", baID)
+	_, stderr, exitCode := runCLIWithKey(t, "delete-burn-alert", "--dataset", dataset, "--id", baID, "--yes")
 	if exitCode != 0 {
 		t.Fatalf("delete-burn-alert failed with exit code %d: %s", exitCode, stderr)
 	}
@@ -162,7 +162,7 @@ func TestBurnAlertBudgetRateCLI_Smoke(t *testing.T) {
 	dc := parseJSON(t, stdout)
 	dcID := dc["id"].(string)
 	defer func() {
-		runCLIWithKey(t, "delete-derived-column", "--dataset", dataset, "--id", dcID)
+		runCLIWithKey(t, "delete-derived-column", "--dataset", dataset, "--id", dcID, "--yes")
 	}()
 
 	// Create SLO
@@ -180,7 +180,7 @@ func TestBurnAlertBudgetRateCLI_Smoke(t *testing.T) {
 	slo := parseJSON(t, stdout)
 	sloID := slo["id"].(string)
 	defer func() {
-		runCLIWithKey(t, "delete-slo", "--dataset", dataset, "--id", sloID)
+		runCLIWithKey(t, "delete-slo", "--dataset", dataset, "--id", sloID, "--yes")
 	}()
 
 	recipientsJSON := `[{"type":"email","target":"hccli-test@example.com"}]`
@@ -205,7 +205,7 @@ func TestBurnAlertBudgetRateCLI_Smoke(t *testing.T) {
 		t.Fatal("expected non-empty burn alert id")
 	}
 	defer func() {
-		runCLIWithKey(t, "delete-burn-alert", "--dataset", dataset, "--id", baID)
+		runCLIWithKey(t, "delete-burn-alert", "--dataset", dataset, "--id", baID, "--yes")
 	}()
 
 	if ba["alert_type"] != "budget_rate" {
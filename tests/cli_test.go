@@ -4,10 +4,14 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
+	"sync"
 	"testing"
 )
 
@@ -32,8 +36,17 @@ func TestMain(m *testing.M) {
 }
 
 func runCLI(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+	return runCLIWithEnv(t, nil, args...)
+}
+
+// runCLIWithEnv runs the CLI with extra environment variables in "KEY=value" form.
+func runCLIWithEnv(t *testing.T, env []string, args ...string) (string, string, int) {
 	t.Helper()
 	cmd := exec.CommandContext(t.Context(), binaryPath, args...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
 	var stdout, stderr strings.Builder
 	cmd.Stdout = &stdout
 	cmd.Stderr = &stderr
@@ -49,6 +62,46 @@ func runCLI(t *testing.T, args ...string) (string, string, int) {
 	return stdout.String(), stderr.String(), exitCode
 }
 
+// newRecordingServer starts a fake API server that answers JSON through
+// handler, which gets the request body already read, and records every
+// mutating request as "METHOD PATH BODY". The returned function lists the
+// recorded requests in order.
+func newRecordingServer(t *testing.T, handler func(w http.ResponseWriter, r *http.Request, body []byte)) (*httptest.Server, func() []string) {
+	t.Helper()
+	var mu sync.Mutex
+	var writes []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodGet {
+			mu.Lock()
+			writes = append(writes, r.Method+" "+r.URL.Path+" "+string(body))
+			mu.Unlock()
+		}
+		handler(w, r, body)
+	}))
+	return srv, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), writes...)
+	}
+}
+
+// requestBody decodes the JSON body of a request recorded by
+// newRecordingServer.
+func requestBody(t *testing.T, recorded string) map[string]any {
+	t.Helper()
+	parts := strings.SplitN(recorded, " ", 3)
+	if len(parts) < 3 {
+		t.Fatalf("malformed recorded request %q", recorded)
+	}
+	var m map[string]any
+	if err := json.Unmarshal([]byte(parts[2]), &m); err != nil {
+		t.Fatalf("failed to parse request body: %v\nrequest: %s", err, recorded)
+	}
+	return m
+}
+
 func runCLIWithKey(t *testing.T, args ...string) (string, string, int) {
 	t.Helper()
 	apiKey := os.Getenv("HONEYCOMB_API_KEY")
@@ -98,6 +151,20 @@ func TestMissingAPIKeyShowsError(t *testing.T) {
 	}
 }
 
+func TestOfflineCommandsRunWithoutAPIKey(t *testing.T) {
+	events := writeEvents(t, `{"status_code": 200}`)
+	for _, args := range [][]string{
+		{"eval-derived-column", "--events", events, "--expression", "LT($status_code, 500)"},
+		{"lint-derived-column", "--expression", "LT($status_code, 500)"},
+	} {
+		cmd := exec.CommandContext(t.Context(), binaryPath, args...)
+		cmd.Env = filterEnv("HONEYCOMB_API_KEY")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("%s failed without an API key: %v\n%s", args[0], err, out)
+		}
+	}
+}
+
 func filterEnv(exclude string) []string {
 	var env []string
 	for _, e := range os.Environ() {
@@ -0,0 +1,119 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newColumnAuditServer serves columns with different last writes and the
+// resources that reference some of them. Looking up query q-1 responds with
+// queryStatus.
+func newColumnAuditServer(t *testing.T, queryStatus int) (*httptest.Server, func() []string) {
+	t.Helper()
+	recent := time.Now().Add(-time.Hour).UTC().Format(time.RFC3339)
+	old := time.Now().Add(-90 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	return newRecordingServer(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		if r.Method != http.MethodGet {
+			w.Write(body)
+			return
+		}
+		switch r.URL.Path {
+		case "/1/columns/api":
+			fmt.Fprintf(w, `[
+				{"id":"c-1","key_name":"status_code","type":"integer","last_written":%q},
+				{"id":"c-2","key_name":"legacy.user","type":"string","last_written":%q},
+				{"id":"c-3","key_name":"dead","type":"string","last_written":%q},
+				{"id":"c-4","key_name":"queried","type":"string","last_written":%q},
+				{"id":"c-5","key_name":"empty"},
+				{"id":"c-6","key_name":"garbled","type":"string","last_written":"last tuesday"}
+			]`, recent, old, old, old)
+		case "/1/derived_columns/api":
+			fmt.Fprint(w, `[{"id":"dc-1","alias":"sli_ok","expression":"IF(EXISTS($\"legacy.user\"), LT($status_code, 500))"}]`)
+		case "/1/slos/api":
+			fmt.Fprint(w, `[{"id":"slo-1","name":"Availability","sli":{"alias":"sli_ok"}}]`)
+		case "/1/query_annotations/api":
+			fmt.Fprint(w, `[{"id":"qa-1","name":"Errors","query_id":"q-1"}]`)
+		case "/1/queries/api/q-1":
+			if queryStatus != http.StatusOK {
+				http.Error(w, `{"error":"boom"}`, queryStatus)
+				return
+			}
+			fmt.Fprint(w, `{"id":"q-1","breakdowns":["queried"],"calculations":[{"op":"COUNT"}]}`)
+		case "/1/queries/api/q-other":
+			http.Error(w, `{"error":"not found"}`, http.StatusNotFound)
+		case "/1/boards":
+			fmt.Fprint(w, `[{"id":"b-1","name":"Overview","panels":[{"type":"query","query_panel":{"query_id":"q-other"}}]}]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	})
+}
+
+func TestColumnAudit(t *testing.T) {
+	srv, writes := newColumnAuditServer(t, http.StatusOK)
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "column-audit", "--dataset", "api", "--hide-stale")
+	if code != 0 {
+		t.Fatalf("column-audit failed with exit code %d\nstderr: %s", code, stderr)
+	}
+
+	report := parseJSON(t, stdout)
+	got := map[string]string{}
+	for _, c := range report["columns"].([]any) {
+		m := c.(map[string]any)
+		var refs []string
+		for _, r := range m["references"].([]any) {
+			refs = append(refs, r.(string))
+		}
+		got[m["key_name"].(string)] = m["status"].(string) + " " + strings.Join(refs, ",")
+	}
+	want := map[string]string{
+		"status_code": "active derived_column:sli_ok,slo:Availability",
+		"legacy.user": "stale derived_column:sli_ok,slo:Availability",
+		"dead":        "stale ",
+		"queried":     "stale query_annotation:Errors",
+		"empty":       "never_written ",
+		"garbled":     "unknown ",
+	}
+	for name, w := range want {
+		if got[name] != w {
+			t.Errorf("%s = %q, want %q", name, got[name], w)
+		}
+	}
+
+	w := writes()
+	if len(w) != 1 || !strings.HasPrefix(w[0], "PUT /1/columns/api/c-3 ") || !strings.Contains(w[0], `"hidden":true`) {
+		t.Errorf("expected only the unreferenced stale column to be hidden, got %v", w)
+	}
+	summary := report["summary"].(map[string]any)
+	if summary["stale"] != float64(3) || summary["unknown"] != float64(1) || summary["hidden"] != float64(1) || summary["unreferenced"] != float64(3) {
+		t.Errorf("unexpected summary: %v", summary)
+	}
+}
+
+func TestColumnAuditHidesNothingWhenAQueryLookupFails(t *testing.T) {
+	srv, writes := newColumnAuditServer(t, http.StatusInternalServerError)
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "column-audit", "--dataset", "api", "--hide-stale")
+	if code != 1 {
+		t.Fatalf("expected exit code 1, got %d\nstderr: %s", code, stderr)
+	}
+	if w := writes(); len(w) != 0 {
+		t.Errorf("expected no column to be hidden, got %v", w)
+	}
+	if !strings.Contains(stderr, "q-1") || !strings.Contains(stderr, "query_annotation:Errors") {
+		t.Errorf("expected the unreadable query and its reference on stderr, got: %s", stderr)
+	}
+
+	report := parseJSON(t, stdout)
+	unresolved, _ := report["unresolved_references"].([]any)
+	if len(unresolved) != 1 || !strings.Contains(unresolved[0].(string), "q-1") {
+		t.Errorf("expected q-1 in unresolved_references, got %v", report["unresolved_references"])
+	}
+}
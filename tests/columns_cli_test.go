@@ -68,7 +68,7 @@ func TestColumnCRUDCLI_Smoke(t *testing.T) {
 		t.Errorf("expected to find column %q in list", id)
 	}
 
-	_, _, exitCode = runCLIWithKey(t, "delete-column", "--dataset", dataset, "--id", id)
+	_, _, exitCode = runCLIWithKey(t, "delete-column", "--dataset", dataset, "--id", id, "--yes")
 	if exitCode != 0 {
 		t.Fatalf("delete-column failed with exit code %d", exitCode)
 	}
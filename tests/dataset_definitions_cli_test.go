@@ -0,0 +1,82 @@
+package main_test
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDatasetDefinitions(t *testing.T) {
+	var patched string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/1/dataset_definitions/api" && r.Method == http.MethodPatch:
+			body, _ := io.ReadAll(r.Body)
+			patched = string(body)
+			w.Write(body)
+		case r.URL.Path == "/1/dataset_definitions/api":
+			fmt.Fprint(w, `{"trace_id":{"name":"traceId","column_type":"column"},"duration_ms":{"name":"durationMs","column_type":"column"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "dataset-definitions", "--dataset", "api")
+	if code != 0 {
+		t.Fatalf("dataset-definitions failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	defs := parseJSON(t, stdout)
+	if defs["trace_id"].(map[string]any)["name"] != "traceId" {
+		t.Errorf("unexpected definitions: %v", defs)
+	}
+
+	_, stderr, code = runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"update-dataset-definitions", "--dataset", "api", "--parent-id", "parentId", "--user", "")
+	if code != 0 {
+		t.Fatalf("update-dataset-definitions failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if patched != `{"parent_id":{"name":"parentId"},"user":{"name":""}}` {
+		t.Errorf("unexpected PATCH body: %s", patched)
+	}
+
+	_, stderr, code = runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "update-dataset-definitions", "--dataset", "api")
+	if code == 0 || !strings.Contains(stderr, "at least one definition flag is required") {
+		t.Errorf("expected an error without definition flags, got exit code %d\nstderr: %s", code, stderr)
+	}
+}
+
+func TestGetTraceUsesDatasetDefinitions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/1/auth":
+			fmt.Fprint(w, `{"team":{"slug":"acme"},"environment":{"slug":"prod"}}`)
+		case "/1/dataset_definitions/api":
+			fmt.Fprint(w, `{"trace_id":{"name":"traceId"}}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "get-trace", "--dataset", "api", "--trace-id", "abc")
+	if code != 0 {
+		t.Fatalf("get-trace failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if got := parseJSON(t, stdout)["trace_id_column"]; got != "traceId" {
+		t.Errorf("expected trace_id_column traceId, got %v", got)
+	}
+
+	stdout, stderr, _ = runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "get-trace", "--dataset", "web", "--trace-id", "abc")
+	if got := parseJSON(t, stdout)["trace_id_column"]; got != "trace.trace_id" {
+		t.Errorf("expected the default trace ID column, got %v", got)
+	}
+	if !strings.Contains(stderr, "Skipping dataset definitions") {
+		t.Errorf("expected a warning about missing definitions, got: %s", stderr)
+	}
+}
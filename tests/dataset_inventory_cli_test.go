@@ -0,0 +1,97 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newDatasetInventoryServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	recent := time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339)
+	old := time.Now().Add(-100 * 24 * time.Hour).UTC().Format(time.RFC3339)
+	yesterday := time.Now().Add(-24 * time.Hour).Unix()
+	lastMonth := time.Now().Add(-30 * 24 * time.Hour).Unix()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/1/datasets":
+			fmt.Fprintf(w, `[
+				{"slug":"web","name":"Web","last_written_at":%q,"regular_columns_count":12},
+				{"slug":"api","name":"API","last_written_at":%q,"regular_columns_count":40},
+				{"slug":"empty","name":"Empty"}
+			]`, old, recent)
+		case "/1/derived_columns/api":
+			fmt.Fprint(w, `[{"id":"dc-1"},{"id":"dc-2"}]`)
+		case "/1/slos/api":
+			fmt.Fprint(w, `[{"id":"slo-1"}]`)
+		case "/1/slos/web":
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprint(w, `{"error":"boom"}`)
+		case "/1/markers/api":
+			fmt.Fprintf(w, `[{"id":"m-1","start_time":%d},{"id":"m-2","start_time":%d}]`, yesterday, lastMonth)
+		case "/1/queries/api", "/1/queries/web", "/1/queries/empty":
+			fmt.Fprint(w, `{"id":"q-1"}`)
+		case "/1/query_results/api":
+			fmt.Fprint(w, `{"id":"qr-1","complete":true,"data":{"results":[{"data":{"COUNT":1500}}]}}`)
+		case "/1/query_results/web", "/1/query_results/empty":
+			fmt.Fprint(w, `{"id":"qr-2","complete":true,"data":{"results":[]}}`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+}
+
+func TestDatasetInventory(t *testing.T) {
+	srv := newDatasetInventoryServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"dataset-inventory", "--requests-per-second", "100")
+	if code != 0 {
+		t.Fatalf("dataset-inventory failed with exit code %d\nstderr: %s", code, stderr)
+	}
+
+	rows := parseJSONArray(t, stdout)
+	if len(rows) != 3 {
+		t.Fatalf("expected 3 rows, got %d", len(rows))
+	}
+	api := rows[0].(map[string]any)
+	if api["slug"] != "api" || api["derived_columns"] != float64(2) || api["slos"] != float64(1) ||
+		api["markers_last_week"] != float64(1) || api["event_count"] != float64(1500) || api["inactive"] != false {
+		t.Errorf("unexpected api row: %v", api)
+	}
+	empty := rows[1].(map[string]any)
+	if empty["inactive"] != true || empty["days_since_write"] != nil {
+		t.Errorf("expected a never written dataset to be inactive: %v", empty)
+	}
+	web := rows[2].(map[string]any)
+	if web["inactive"] != true || web["days_since_write"] != float64(100) || web["slos"] != nil || web["errors"] == nil {
+		t.Errorf("unexpected web row: %v", web)
+	}
+	if !strings.Contains(stderr, "Skipping SLOs of web") {
+		t.Errorf("expected a warning about the failed lookup, got: %s", stderr)
+	}
+}
+
+func TestDatasetInventoryText(t *testing.T) {
+	srv := newDatasetInventoryServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"dataset-inventory", "--requests-per-second", "100", "--skip-volume", "--inactive-days", "90", "--output", "text")
+	if code != 0 {
+		t.Fatalf("dataset-inventory failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	for _, want := range []string{"api", "0d ago", "active", "100d ago", "INACTIVE (1 lookup(s) failed)", "2 of 3 dataset(s) without writes in 90 days."} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected %q in output:\n%s", want, stdout)
+		}
+	}
+	if strings.Contains(stdout, "1500") {
+		t.Errorf("expected no event counts with --skip-volume:\n%s", stdout)
+	}
+}
@@ -85,7 +85,7 @@ func TestDatasetCRUDCLI_Smoke(t *testing.T) {
 		t.Fatalf("disable delete protection failed with exit code %d", exitCode)
 	}
 
-	_, _, exitCode = runCLIWithKey(t, "delete-dataset", "--slug", slug)
+	_, _, exitCode = runCLIWithKey(t, "delete-dataset", "--slug", slug, "--yes")
 	if exitCode != 0 {
 		t.Fatalf("delete-dataset failed with exit code %d", exitCode)
 	}
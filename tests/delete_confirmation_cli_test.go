@@ -0,0 +1,109 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestDeleteMarkerConfirmation(t *testing.T) {
+	srv, writes := newMarkersServer(t)
+	defer srv.Close()
+
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "delete-marker", "--dataset", "api", "--id", "m-2")
+	if code == 0 || !strings.Contains(stderr, "refusing to delete marker m-2") {
+		t.Fatalf("expected deletion without a terminal to be refused, got code %d, stderr: %s", code, stderr)
+	}
+	if !strings.Contains(stderr, `"message": "Rollback v2"`) {
+		t.Errorf("expected the marker to be shown before deleting, stderr: %s", stderr)
+	}
+	if w := writes(); len(w) != 0 {
+		t.Fatalf("expected no deletions without confirmation, got %v", w)
+	}
+
+	_, stderr, code = runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "delete-marker", "--dataset", "api", "--id", "m-9", "--yes")
+	if code == 0 || !strings.Contains(stderr, "marker m-9 not found") {
+		t.Errorf("expected unknown marker to fail, got code %d, stderr: %s", code, stderr)
+	}
+
+	if _, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "delete-marker", "--dataset", "api", "--id", "m-2", "--yes"); code != 0 {
+		t.Fatalf("delete-marker --yes failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if w := writes(); len(w) != 1 || !strings.HasPrefix(w[0], "DELETE /1/markers/api/m-2") {
+		t.Errorf("unexpected delete requests: %v", w)
+	}
+}
+
+func TestDeleteDatasetProtected(t *testing.T) {
+	var deletes atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			deletes.Add(1)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		slug := strings.TrimPrefix(r.URL.Path, "/1/datasets/")
+		fmt.Fprintf(w, `{"slug":%q,"name":%q,"settings":{"delete_protected":%t}}`, slug, slug, slug == "prod")
+	}))
+	defer srv.Close()
+
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "delete-dataset", "--slug", "prod", "--yes")
+	if code == 0 || !strings.Contains(stderr, "dataset prod is delete protected") {
+		t.Fatalf("expected protected dataset to be refused, got code %d, stderr: %s", code, stderr)
+	}
+	if deletes.Load() != 0 {
+		t.Fatal("expected nothing to be deleted")
+	}
+
+	if _, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "delete-dataset", "--slug", "scratch", "--yes"); code != 0 {
+		t.Fatalf("delete-dataset failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if deletes.Load() != 1 {
+		t.Errorf("expected 1 delete, got %d", deletes.Load())
+	}
+}
+
+func TestGlobalDryRun(t *testing.T) {
+	srv, writes := newMarkersServer(t)
+	defer srv.Close()
+
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "--dry-run", "delete-marker", "--dataset", "api", "--id", "m-1")
+	if code != 0 {
+		t.Fatalf("dry-run delete-marker failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if !strings.Contains(stderr, "DRY RUN: DELETE "+srv.URL+"/1/markers/api/m-1") {
+		t.Errorf("expected the DELETE request to be printed, stderr: %s", stderr)
+	}
+
+	_, stderr, code = runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "--dry-run", "create-marker", "--dataset", "api", "--message", "Deploy v3", "--type", "deploy")
+	if code != 0 {
+		t.Fatalf("dry-run create-marker failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if !strings.Contains(stderr, "DRY RUN: POST "+srv.URL+"/1/markers/api") || !strings.Contains(stderr, `"message": "Deploy v3"`) {
+		t.Errorf("expected the POST request and its body to be printed, stderr: %s", stderr)
+	}
+
+	if w := writes(); len(w) != 0 {
+		t.Errorf("expected nothing to be sent, got %v", w)
+	}
+}
+
+func TestGlobalDryRunRunsQueries(t *testing.T) {
+	srv, writes := newTraceServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "--dry-run", "trace", "--dataset", "api", "--trace-id", "t-1")
+	if code != 0 {
+		t.Fatalf("dry-run trace failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if strings.Contains(stderr, "DRY RUN") {
+		t.Errorf("expected queries to be sent under --dry-run, stderr: %s", stderr)
+	}
+	if len(writes()) == 0 || !strings.Contains(stdout, "Trace t-1: 3 span(s)") {
+		t.Errorf("expected the trace query to run, stdout: %s", stdout)
+	}
+}
@@ -0,0 +1,79 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeEvents(t *testing.T, lines ...string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestEvalDerivedColumn(t *testing.T) {
+	path := writeEvents(t,
+		`{"status_code": 200, "http": {"route": "/api/users"}, "duration_ms": 120.5}`,
+		``,
+		`{"status_code": 503, "http": {"route": "/health"}}`,
+	)
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "eval-derived-column", "--events", path,
+		"--expression", `IF(STARTS_WITH($http.route, "/api"), LT($status_code, 500))`)
+	if code != 0 {
+		t.Fatalf("eval-derived-column failed with exit code %d\nstderr: %s", code, stderr)
+	}
+
+	results := parseJSONArray(t, stdout)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	first := results[0].(map[string]any)
+	input := first["input"].(map[string]any)
+	if first["value"] != true || input["http.route"] != "/api/users" || input["status_code"] != float64(200) {
+		t.Errorf("unexpected first result: %v", first)
+	}
+	if _, ok := input["duration_ms"]; ok {
+		t.Errorf("expected only referenced columns in input, got %v", input)
+	}
+	second := results[1].(map[string]any)
+	if second["line"] != float64(3) || second["value"] != nil {
+		t.Errorf("unexpected second result: %v", second)
+	}
+}
+
+func TestEvalDerivedColumnExpectations(t *testing.T) {
+	path := writeEvents(t,
+		`{"status_code": 200, "expected": 1}`,
+		`{"status_code": 503, "expected": 1}`,
+	)
+
+	stdout, _, code := runCLI(t, "--api-key", "fake-key", "eval-derived-column", "--events", path, "--expect-field", "expected",
+		"--output", "text", "--expression", "INT(LT($status_code, 500))")
+	if code != 2 {
+		t.Fatalf("expected exit code 2 for a mismatch, got %d", code)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "LINE") {
+		t.Fatalf("unexpected output:\n%s", stdout)
+	}
+	if strings.Contains(lines[1], "MISMATCH") || !strings.Contains(lines[2], "MISMATCH") {
+		t.Errorf("expected only the second event to mismatch:\n%s", stdout)
+	}
+}
+
+func TestEvalDerivedColumnRejectsUnknownFunction(t *testing.T) {
+	path := writeEvents(t, `{"a": 1}`)
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "eval-derived-column", "--events", path, "--expression", "NOPE($a)")
+	if code == 0 {
+		t.Fatal("expected an unknown function to fail")
+	}
+	if !strings.Contains(stderr, "unknown function NOPE") {
+		t.Errorf("unexpected stderr: %s", stderr)
+	}
+}
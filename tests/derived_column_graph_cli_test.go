@@ -0,0 +1,147 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func newDerivedColumnGraphServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var deletes atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			deletes.Add(1)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		switch r.URL.Path {
+		case "/1/derived_columns/api":
+			fmt.Fprint(w, `[
+				{"id":"dc-sli","alias":"sli","expression":"AND($is_ok, LT($duration_ms, 300))"},
+				{"id":"dc-ok","alias":"is_ok","expression":"OR(LT($status_code, 500), $is_internal)"},
+				{"id":"dc-a","alias":"loop_a","expression":"INT($loop_b)"},
+				{"id":"dc-b","alias":"loop_b","expression":"INT($loop_a"},
+				{"id":"dc-unused","alias":"unused","expression":"INT(1)"}
+			]`)
+		case "/1/derived_columns/__all__":
+			fmt.Fprint(w, `[{"id":"dc-env","alias":"is_internal","expression":"STARTS_WITH($\"user agent\", \"internal\")"}]`)
+		case "/1/derived_columns/__all__/dc-env":
+			fmt.Fprint(w, `{"id":"dc-env","alias":"is_internal","expression":"STARTS_WITH($\"user agent\", \"internal\")"}`)
+		case "/1/datasets":
+			fmt.Fprint(w, `[{"name":"api","slug":"api"}]`)
+		case "/1/derived_columns/api/dc-ok":
+			fmt.Fprint(w, `{"id":"dc-ok","alias":"is_ok","expression":"LT($status_code, 500)"}`)
+		case "/1/derived_columns/api/dc-unused":
+			fmt.Fprint(w, `{"id":"dc-unused","alias":"unused","expression":"INT(1)"}`)
+		case "/1/slos/api":
+			fmt.Fprint(w, `[{"id":"slo-1","name":"Availability","sli":{"alias":"sli"}}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return srv, &deletes
+}
+
+func TestDerivedColumnGraph(t *testing.T) {
+	srv, _ := newDerivedColumnGraphServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "derived-column-graph", "--dataset", "api")
+	if code != 2 {
+		t.Fatalf("expected exit code 2 for a cycle, got %d\nstderr: %s", code, stderr)
+	}
+
+	g := parseJSON(t, stdout)
+	var edges []string
+	for _, e := range g["edges"].([]any) {
+		m := e.(map[string]any)
+		edges = append(edges, m["from"].(string)+" -> "+m["to"].(string))
+	}
+	want := []string{
+		"derived_column:is_internal -> column:user agent",
+		"derived_column:is_ok -> column:status_code",
+		"derived_column:is_ok -> derived_column:is_internal",
+		"derived_column:loop_a -> derived_column:loop_b",
+		"derived_column:loop_b -> derived_column:loop_a",
+		"derived_column:sli -> column:duration_ms",
+		"derived_column:sli -> derived_column:is_ok",
+		"slo:Availability -> derived_column:sli",
+	}
+	if strings.Join(edges, "\n") != strings.Join(want, "\n") {
+		t.Errorf("edges:\n%s\nwant:\n%s", strings.Join(edges, "\n"), strings.Join(want, "\n"))
+	}
+
+	cycles := g["cycles"].([]any)
+	if len(cycles) != 1 || fmt.Sprint(cycles[0]) != "[derived_column:loop_a derived_column:loop_b]" {
+		t.Errorf("unexpected cycles: %v", cycles)
+	}
+	parseErrors := g["parse_errors"].([]any)
+	if len(parseErrors) != 1 || parseErrors[0].(map[string]any)["alias"] != "loop_b" {
+		t.Errorf("unexpected parse errors: %v", parseErrors)
+	}
+}
+
+func TestDerivedColumnGraphDOT(t *testing.T) {
+	srv, _ := newDerivedColumnGraphServer(t)
+	defer srv.Close()
+
+	stdout, _, _ := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "derived-column-graph", "--dataset", "api", "--output", "dot")
+	for _, want := range []string{
+		`digraph "api" {`,
+		`"derived_column:is_internal" [label="is_internal\n(__all__)", shape=box];`,
+		`"slo:Availability" [label="Availability", shape=doubleoctagon];`,
+		`"derived_column:loop_a" -> "derived_column:loop_b" [color=red];`,
+		`"derived_column:sli" -> "column:duration_ms";`,
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected %s in DOT output:\n%s", want, stdout)
+		}
+	}
+}
+
+func TestDeleteDerivedColumnWithDependents(t *testing.T) {
+	srv, deletes := newDerivedColumnGraphServer(t)
+	defer srv.Close()
+
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "delete-derived-column", "--dataset", "api", "--id", "dc-ok", "--yes")
+	if code == 0 {
+		t.Fatal("expected delete-derived-column to refuse a column with dependents")
+	}
+	if !strings.Contains(stderr, "is_ok is used by derived_column:sli") {
+		t.Errorf("unexpected stderr: %s", stderr)
+	}
+	if deletes.Load() != 0 {
+		t.Fatal("expected nothing to be deleted")
+	}
+
+	if _, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "delete-derived-column", "--dataset", "api", "--id", "dc-ok", "--force", "--yes"); code != 0 {
+		t.Fatalf("delete-derived-column --force failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if _, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "delete-derived-column", "--dataset", "api", "--id", "dc-unused", "--yes"); code != 0 {
+		t.Fatalf("delete-derived-column of an unused column failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if deletes.Load() != 2 {
+		t.Errorf("expected 2 deletes, got %d", deletes.Load())
+	}
+}
+
+func TestDeleteEnvironmentDerivedColumnWithDependents(t *testing.T) {
+	srv, deletes := newDerivedColumnGraphServer(t)
+	defer srv.Close()
+
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "delete-derived-column", "--dataset", "__all__", "--id", "dc-env", "--yes")
+	if code == 0 {
+		t.Fatal("expected delete-derived-column to refuse an environment-wide column used by a dataset")
+	}
+	if !strings.Contains(stderr, "is_internal is used by api/derived_column:is_ok") {
+		t.Errorf("unexpected stderr: %s", stderr)
+	}
+	if deletes.Load() != 0 {
+		t.Fatal("expected nothing to be deleted")
+	}
+}
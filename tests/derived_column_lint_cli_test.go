@@ -0,0 +1,100 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func newDerivedColumnLintServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var creates atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/1/derived_columns/api":
+			creates.Add(1)
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"id":"dc-new","alias":"slow"}`)
+		case r.URL.Path == "/1/columns/api":
+			fmt.Fprint(w, `[{"id":"c-1","key_name":"duration_ms","type":"float"}]`)
+		case r.URL.Path == "/1/derived_columns/api":
+			fmt.Fprint(w, `[{"id":"dc-1","alias":"is_error","expression":"INT(1)"}]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	}))
+	return srv, &creates
+}
+
+func TestLintDerivedColumn(t *testing.T) {
+	srv, _ := newDerivedColumnLintServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"lint-derived-column", "--dataset", "api", "--output", "text",
+		"--expression", "IF($is_error, GT($duration, 1000), TO_UPPER($user))")
+	if code != 2 {
+		t.Fatalf("expected exit code 2, got %d\nstderr: %s", code, stderr)
+	}
+	want := `1:18: unknown column "duration"
+1:36: unknown function TO_UPPER
+1:45: unknown column "user"
+`
+	if stdout != want {
+		t.Errorf("unexpected output:\n%s\nwant:\n%s", stdout, want)
+	}
+}
+
+func TestLintDerivedColumnOffline(t *testing.T) {
+	stdout, _, code := runCLI(t, "--api-key", "fake-key", "--api-url", "http://127.0.0.1:1",
+		"lint-derived-column", "--expression", "LT($anything, 5")
+	if code != 2 {
+		t.Fatalf("expected exit code 2, got %d", code)
+	}
+	report := parseJSON(t, stdout)
+	problems := report["problems"].([]any)
+	if report["valid"] != false || len(problems) != 1 {
+		t.Fatalf("unexpected report: %v", report)
+	}
+	p := problems[0].(map[string]any)
+	pos := p["pos"].(map[string]any)
+	if p["kind"] != "syntax" || pos["line"] != float64(1) || pos["column"] != float64(16) {
+		t.Errorf("unexpected problem: %v", p)
+	}
+
+	_, _, code = runCLI(t, "--api-key", "fake-key", "--api-url", "http://127.0.0.1:1",
+		"lint-derived-column", "--expression", "LT($anything, 5)")
+	if code != 0 {
+		t.Errorf("expected a valid expression to pass, got exit code %d", code)
+	}
+}
+
+func TestCreateDerivedColumnLintsFirst(t *testing.T) {
+	srv, creates := newDerivedColumnLintServer(t)
+	defer srv.Close()
+
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"create-derived-column", "--dataset", "api", "--alias", "slow", "--expression", "GT($duration, 1000)")
+	if code == 0 {
+		t.Fatal("expected create-derived-column to refuse an expression with problems")
+	}
+	if !strings.Contains(stderr, `1:4: unknown column "duration"`) || !strings.Contains(stderr, "--no-lint") {
+		t.Errorf("unexpected stderr: %s", stderr)
+	}
+	if creates.Load() != 0 {
+		t.Fatal("expected no derived column to be created")
+	}
+
+	_, stderr, code = runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"create-derived-column", "--dataset", "api", "--alias", "slow", "--expression", "GT($duration, 1000)", "--no-lint")
+	if code != 0 {
+		t.Fatalf("create-derived-column --no-lint failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if creates.Load() != 1 {
+		t.Errorf("expected one derived column to be created, got %d", creates.Load())
+	}
+}
@@ -69,7 +69,7 @@ func TestDerivedColumnCRUDCLI_Smoke(t *testing.T) {
 		t.Errorf("expected to find derived column %q in list", id)
 	}
 
-	_, _, exitCode = runCLIWithKey(t, "delete-derived-column", "--dataset", dataset, "--id", id)
+	_, _, exitCode = runCLIWithKey(t, "delete-derived-column", "--dataset", dataset, "--id", id, "--yes")
 	if exitCode != 0 {
 		t.Fatalf("delete-derived-column failed with exit code %d", exitCode)
 	}
@@ -0,0 +1,57 @@
+package main_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDriftCLIReportsChangedFields(t *testing.T) {
+	srv, writes := newPlanServer(t)
+	defer srv.Close()
+	dir := writeManifest(t)
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "drift", "--dir", dir)
+	if code != 2 {
+		t.Fatalf("expected exit code 2 for drift, got %d\nstderr: %s", code, stderr)
+	}
+	if !strings.Contains(stderr, "drift detected in 3 resource(s)") {
+		t.Errorf("expected drift summary on stderr, got: %s", stderr)
+	}
+	if w := writes(); len(w) != 0 {
+		t.Errorf("expected drift to make no changes, got %v", w)
+	}
+
+	report := parseJSON(t, stdout)
+	if report["drift"] != true {
+		t.Errorf("expected drift true, got %v", report["drift"])
+	}
+	statuses := map[string]string{}
+	for _, r := range report["resources"].([]any) {
+		m := r.(map[string]any)
+		statuses[m["kind"].(string)] = m["status"].(string)
+	}
+	if statuses["slo"] != "changed" || statuses["derived_column"] != "missing" || statuses["burn_alert"] != "missing" {
+		t.Errorf("unexpected statuses: %v", statuses)
+	}
+}
+
+func TestDriftCLINoDrift(t *testing.T) {
+	srv, _ := newPlanServer(t)
+	defer srv.Close()
+	dir := t.TempDir()
+	content := "dataset: api\nslos:\n  - name: Availability\n    target_per_million: 990000\n"
+	if err := os.WriteFile(filepath.Join(dir, "slos.yaml"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "drift", "--dir", dir)
+	if code != 0 {
+		t.Fatalf("expected exit code 0 without drift, got %d\nstderr: %s", code, stderr)
+	}
+	report := parseJSON(t, stdout)
+	if report["drift"] != false || report["checked"] != float64(1) {
+		t.Errorf("unexpected report: %v", report)
+	}
+}
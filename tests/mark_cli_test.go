@@ -0,0 +1,78 @@
+package main_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newMarkServer records marker requests.
+func newMarkServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	return newRecordingServer(t, func(w http.ResponseWriter, r *http.Request, _ []byte) {
+		w.Write([]byte(`{"id":"m-1"}`))
+	})
+}
+
+func TestMarkSuccess(t *testing.T) {
+	srv, requests := newMarkServer(t)
+	defer srv.Close()
+
+	env := []string{
+		"GITHUB_SHA=0123456789abcdef",
+		"GITHUB_SERVER_URL=https://github.example.com",
+		"GITHUB_REPOSITORY=acme/api",
+		"GITHUB_RUN_ID=42",
+	}
+	stdout, stderr, code := runCLIWithEnv(t, env, "--api-key", "fake-key", "--api-url", srv.URL,
+		"mark", "--dataset", "api", "--", "sh", "-c", "echo deploying")
+	if code != 0 {
+		t.Fatalf("mark failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if stdout != "deploying\n" {
+		t.Errorf("expected command output to pass through, got %q", stdout)
+	}
+
+	r := requests()
+	if len(r) != 2 {
+		t.Fatalf("expected create and update requests, got %v", r)
+	}
+	created, updated := requestBody(t, r[0]), requestBody(t, r[1])
+	if !strings.HasPrefix(r[0], "POST /1/markers/api ") || created["type"] != "deploy" || created["message"] != "Deploy 0123456 (running)" {
+		t.Errorf("unexpected created marker: %v", created)
+	}
+	if created["url"] != "https://github.example.com/acme/api/actions/runs/42" {
+		t.Errorf("unexpected marker URL: %v", created["url"])
+	}
+	if !strings.HasPrefix(r[1], "PUT /1/markers/api/m-1 ") || updated["end_time"] == nil || updated["start_time"] != created["start_time"] {
+		t.Errorf("unexpected updated marker: %v", updated)
+	}
+	if msg, _ := updated["message"].(string); !strings.HasPrefix(msg, "Deploy 0123456 (succeeded in ") {
+		t.Errorf("unexpected final message: %q", msg)
+	}
+}
+
+func TestMarkPropagatesExitCode(t *testing.T) {
+	srv, requests := newMarkServer(t)
+	defer srv.Close()
+
+	_, stderr, code := runCLIWithEnv(t, []string{"GITHUB_SHA=", "CI_COMMIT_SHORT_SHA=", "GITHUB_RUN_ID=", "CI_PIPELINE_URL="},
+		"--api-key", "fake-key", "--api-url", srv.URL,
+		"mark", "--dataset", "api", "--message", "release 1.2", "--", "sh", "-c", "exit 3")
+	if code != 3 {
+		t.Fatalf("expected exit code 3, got %d\nstderr: %s", code, stderr)
+	}
+
+	r := requests()
+	if msg, _ := requestBody(t, r[len(r)-1])["message"].(string); !strings.HasPrefix(msg, "release 1.2 (failed with exit code 3 after ") {
+		t.Errorf("unexpected final message: %q", msg)
+	}
+}
+
+func TestMarkRequiresCommand(t *testing.T) {
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "mark", "--dataset", "api")
+	if code == 0 || !strings.Contains(stderr, "a command to run is required") {
+		t.Errorf("expected missing command error, got code %d, stderr: %s", code, stderr)
+	}
+}
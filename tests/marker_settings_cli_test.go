@@ -61,7 +61,7 @@ func TestMarkerSettingCRUDCLI_Smoke(t *testing.T) {
 		t.Errorf("expected to find marker setting %q in list", id)
 	}
 
-	_, _, exitCode = runCLIWithKey(t, "delete-marker-setting", "--dataset", dataset, "--id", id)
+	_, _, exitCode = runCLIWithKey(t, "delete-marker-setting", "--dataset", dataset, "--id", id, "--yes")
 	if exitCode != 0 {
 		t.Fatalf("delete-marker-setting failed with exit code %d", exitCode)
 	}
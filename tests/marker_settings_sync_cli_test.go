@@ -0,0 +1,82 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newMarkerSettingsServer serves marker settings for three datasets and
+// records mutating requests.
+func newMarkerSettingsServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	return newRecordingServer(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		if r.Method != http.MethodGet {
+			w.Write(body)
+			return
+		}
+		switch r.URL.Path {
+		case "/1/datasets":
+			fmt.Fprint(w, `[{"slug":"api"},{"slug":"web"},{"slug":"worker"}]`)
+		case "/1/marker_settings/api":
+			fmt.Fprint(w, `[{"id":"ms-1","type":"deploy","color":"#1F77B4"},{"id":"ms-2","type":"incident","color":"#d62728"}]`)
+		case "/1/marker_settings/web":
+			fmt.Fprint(w, `[{"id":"ms-3","type":"deploy","color":"#1f77b4"},{"id":"ms-4","type":"incident","color":"#000000"}]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	})
+}
+
+func TestSyncMarkerSettingsFromDataset(t *testing.T) {
+	srv, writes := newMarkerSettingsServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"sync-marker-settings", "--from", "api", "--all")
+	if code != 0 {
+		t.Fatalf("sync-marker-settings failed with exit code %d\nstderr: %s", code, stderr)
+	}
+
+	var got []string
+	for _, c := range parseJSONArray(t, stdout) {
+		m := c.(map[string]any)
+		got = append(got, fmt.Sprintf("%s/%s %s", m["dataset"], m["type"], m["action"]))
+	}
+	want := "web/incident updated,worker/deploy created,worker/incident created"
+	if strings.Join(got, ",") != want {
+		t.Errorf("changes = %s, want %s", strings.Join(got, ","), want)
+	}
+
+	w := writes()
+	if len(w) != 3 || !strings.HasPrefix(w[0], `PUT /1/marker_settings/web/ms-4 {"type":"incident","color":"#d62728"}`) {
+		t.Errorf("unexpected requests: %v", w)
+	}
+}
+
+func TestSyncMarkerSettingsFromPalette(t *testing.T) {
+	srv, writes := newMarkerSettingsServer(t)
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "palette.yaml")
+	if err := os.WriteFile(path, []byte("deploy: \"#1f77b4\"\nrollback: \"#ff7f0e\"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"sync-marker-settings", "--file", path, "--to", "web,worker", "--dry-run")
+	if code != 0 {
+		t.Fatalf("sync-marker-settings failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if w := writes(); len(w) != 0 {
+		t.Errorf("expected dry run to change nothing, got %v", w)
+	}
+	changes := parseJSONArray(t, stdout)
+	if len(changes) != 3 || changes[0].(map[string]any)["action"] != "would-create" {
+		t.Errorf("unexpected changes: %v", changes)
+	}
+}
@@ -61,7 +61,7 @@ func TestMarkerCRUDCLI_Smoke(t *testing.T) {
 		t.Errorf("expected to find marker %q in list", id)
 	}
 
-	_, _, exitCode = runCLIWithKey(t, "delete-marker", "--dataset", dataset, "--id", id)
+	_, _, exitCode = runCLIWithKey(t, "delete-marker", "--dataset", dataset, "--id", id, "--yes")
 	if exitCode != 0 {
 		t.Fatalf("delete-marker failed with exit code %d", exitCode)
 	}
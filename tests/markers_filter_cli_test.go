@@ -0,0 +1,114 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newMarkersServer serves three markers and records mutating requests.
+func newMarkersServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	recent := time.Now().Add(-time.Hour).Unix()
+	return newRecordingServer(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		if r.Method != http.MethodGet {
+			if r.Method == http.MethodDelete {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+			w.Write(body)
+			return
+		}
+		fmt.Fprintf(w, `[
+			{"id":"m-1","start_time":1700000000,"type":"deploy","message":"Deploy v1"},
+			{"id":"m-2","start_time":%d,"type":"deploy","message":"Rollback v2"},
+			{"id":"m-3","start_time":%d,"type":"test","message":"load test"}
+		]`, recent, recent)
+	})
+}
+
+func markerIDs(t *testing.T, stdout string) string {
+	t.Helper()
+	var ids []string
+	for _, m := range parseJSONArray(t, stdout) {
+		ids = append(ids, m.(map[string]any)["id"].(string))
+	}
+	return strings.Join(ids, ",")
+}
+
+func TestMarkersFilters(t *testing.T) {
+	srv, _ := newMarkersServer(t)
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"none", nil, "m-1,m-2,m-3"},
+		{"since duration", []string{"--since", "1 day"}, "m-2,m-3"},
+		{"until timestamp", []string{"--until", "2023-11-15"}, "m-1"},
+		{"type", []string{"--type", "deploy"}, "m-1,m-2"},
+		{"message", []string{"--message-contains", "ROLLBACK"}, "m-2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := append([]string{"--api-key", "fake-key", "--api-url", srv.URL, "markers", "--dataset", "api"}, tt.args...)
+			stdout, stderr, code := runCLI(t, args...)
+			if code != 0 {
+				t.Fatalf("markers failed with exit code %d\nstderr: %s", code, stderr)
+			}
+			if got := markerIDs(t, stdout); got != tt.want {
+				t.Errorf("markers = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateMarkerHumanTimes(t *testing.T) {
+	srv, writes := newMarkersServer(t)
+	defer srv.Close()
+
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"create-marker", "--dataset", "api", "--message", "maintenance",
+		"--start-time", "2024-03-01 10:00", "--end-time", "2024-03-01T12:00:00Z", "--timezone", "Europe/Berlin")
+	if code != 0 {
+		t.Fatalf("create-marker failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	m := requestBody(t, writes()[0])
+	if m["start_time"] != float64(1709283600) || m["end_time"] != float64(1709294400) {
+		t.Errorf("unexpected marker times: %v", m)
+	}
+}
+
+func TestDeleteMarkers(t *testing.T) {
+	srv, writes := newMarkersServer(t)
+	defer srv.Close()
+
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "delete-markers", "--dataset", "api", "--type", "deploy")
+	if code == 0 || !strings.Contains(stderr, "refusing to delete 2 marker(s)") {
+		t.Fatalf("expected unconfirmed deletion to abort, got code %d, stderr: %s", code, stderr)
+	}
+	if w := writes(); len(w) != 0 {
+		t.Fatalf("expected no deletions without confirmation, got %v", w)
+	}
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "delete-markers", "--dataset", "api", "--type", "deploy", "--yes")
+	if code != 0 {
+		t.Fatalf("delete-markers failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if got := markerIDs(t, stdout); got != "m-1,m-2" {
+		t.Errorf("deleted = %s, want m-1,m-2", got)
+	}
+	if w := writes(); len(w) != 2 || !strings.HasPrefix(w[0], "DELETE /1/markers/api/m-1") {
+		t.Errorf("unexpected delete requests: %v", w)
+	}
+
+	_, stderr, code = runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "delete-markers", "--dataset", "api", "--yes")
+	if code == 0 || !strings.Contains(stderr, "at least one filter is required") {
+		t.Errorf("expected filter requirement, got code %d, stderr: %s", code, stderr)
+	}
+}
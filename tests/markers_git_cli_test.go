@@ -0,0 +1,107 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// newGitRepo creates a repository with two commits tagged v1 (lightweight)
+// and v2 (annotated) at fixed commit times.
+func newGitRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	run := func(date string, args ...string) {
+		t.Helper()
+		c := exec.Command("git", append([]string{"-C", dir}, args...)...)
+		c.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+			"GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+		if out, err := c.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	run("", "init", "-q")
+	run("@1700000000 +0000", "commit", "-q", "--allow-empty", "-m", "First release")
+	run("@1700000000 +0000", "tag", "v1")
+	run("@1700003600 +0000", "commit", "-q", "--allow-empty", "-m", "Second release")
+	run("@1700007200 +0000", "tag", "-a", "v2", "-m", "Version 2")
+	run("@1700007200 +0000", "tag", "other")
+	return dir
+}
+
+func TestMarkersFromGitTags(t *testing.T) {
+	repo := newGitRepo(t)
+	srv, posts := newRecordingServer(t, func(w http.ResponseWriter, r *http.Request, _ []byte) {
+		if r.Method == http.MethodPost {
+			fmt.Fprint(w, `{"id":"m-new"}`)
+			return
+		}
+		fmt.Fprint(w, `[{"id":"m-1","start_time":1700000000,"message":"Release v1"}]`)
+	})
+	defer srv.Close()
+
+	args := []string{"--api-key", "fake-key", "--api-url", srv.URL,
+		"markers-from-git", "--dataset", "api", "--repo", repo, "--tags", "v*",
+		"--message-template", "Release {{.Tag}}", "--url-template", "https://example.com/releases/{{.Tag}}?sha={{.ShortSHA}}"}
+
+	stdout, stderr, code := runCLI(t, append(args, "--dry-run")...)
+	if code != 0 {
+		t.Fatalf("markers-from-git --dry-run failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if p := posts(); len(p) != 0 {
+		t.Fatalf("expected dry run to create nothing, got %v", p)
+	}
+	results := parseJSONArray(t, stdout)
+	if len(results) != 2 {
+		t.Fatalf("expected 2 tags, got %v", results)
+	}
+	v1, v2 := results[0].(map[string]any), results[1].(map[string]any)
+	if v1["action"] != "skipped" || v2["action"] != "would-create" {
+		t.Errorf("unexpected actions: %v, %v", v1["action"], v2["action"])
+	}
+	if v2["start_time"] != float64(1700003600) || v2["message"] != "Release v2" {
+		t.Errorf("expected annotated tag to use its commit time, got %v", v2)
+	}
+	if url, _ := v2["url"].(string); !strings.HasPrefix(url, "https://example.com/releases/v2?sha=") {
+		t.Errorf("unexpected URL: %v", v2["url"])
+	}
+
+	_, stderr, code = runCLI(t, args...)
+	if code != 0 {
+		t.Fatalf("markers-from-git failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if p := posts(); len(p) != 1 {
+		t.Errorf("expected 1 created marker, got %v", p)
+	}
+}
+
+func TestMarkersFromGitCommits(t *testing.T) {
+	repo := newGitRepo(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `[]`)
+	}))
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"markers-from-git", "--dataset", "api", "--repo", repo, "--rev-range", "HEAD", "--dry-run")
+	if code != 0 {
+		t.Fatalf("markers-from-git failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	var messages []string
+	for _, r := range parseJSONArray(t, stdout) {
+		messages = append(messages, r.(map[string]any)["message"].(string))
+	}
+	if got := strings.Join(messages, ","); got != "First release,Second release" {
+		t.Errorf("messages = %s, want oldest commit first", got)
+	}
+}
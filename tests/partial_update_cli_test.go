@@ -0,0 +1,125 @@
+package main_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newPartialUpdateServer serves one SLO, marker and dataset and records
+// every PUT.
+func newPartialUpdateServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	return newRecordingServer(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		if r.Method == http.MethodPut {
+			w.Write(body)
+			return
+		}
+		switch r.URL.Path {
+		case "/1/slos/api/slo-1":
+			w.Write([]byte(`{"id":"slo-1","name":"Checkout","description":"Checkout requests",
+				"sli":{"alias":"sli_checkout"},"time_period_days":30,"target_per_million":999000,
+				"tags":[{"key":"team","value":"payments"}],"created_at":"2024-01-01T00:00:00Z"}`))
+		case "/1/slos/__all__/slo-2":
+			w.Write([]byte(`{"id":"slo-2","name":"Latency","sli":{"alias":"sli_latency"},
+				"time_period_days":30,"target_per_million":999000,"dataset_slugs":["api","web"]}`))
+		case "/1/markers/api":
+			w.Write([]byte(`[{"id":"m-1","type":"deploy","message":"v1","url":"https://ci/1","start_time":1700000000}]`))
+		case "/1/datasets/api":
+			w.Write([]byte(`{"slug":"api","name":"api","description":"API traffic",
+				"expand_json_depth":2,"settings":{"delete_protected":true}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func TestUpdateSLOKeepsUnsetFields(t *testing.T) {
+	srv, puts := newPartialUpdateServer(t)
+	defer srv.Close()
+
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "update-slo", "--dataset", "api", "--id", "slo-1", "--target-per-million", "995000", "--show-diff")
+	if code != 0 {
+		t.Fatalf("update-slo failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if strings.TrimSpace(stderr) != "~ target_per_million: 999000 -> 995000" {
+		t.Errorf("unexpected diff: %s", stderr)
+	}
+
+	p := puts()
+	if len(p) != 1 {
+		t.Fatalf("expected 1 PUT, got %d", len(p))
+	}
+	body := requestBody(t, p[0])
+	if body["target_per_million"] != float64(995000) || body["name"] != "Checkout" || body["time_period_days"] != float64(30) {
+		t.Errorf("unexpected PUT body: %v", body)
+	}
+	if sli, _ := body["sli"].(map[string]any); sli["alias"] != "sli_checkout" {
+		t.Errorf("expected the SLI to be kept, got %v", body["sli"])
+	}
+	if tags, _ := body["tags"].([]any); len(tags) != 1 {
+		t.Errorf("expected the tags to be kept, got %v", body["tags"])
+	}
+}
+
+func TestUpdateEnvironmentWideSLOKeepsDatasets(t *testing.T) {
+	srv, puts := newPartialUpdateServer(t)
+	defer srv.Close()
+
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "update-slo", "--dataset", "__all__", "--id", "slo-2", "--name", "API latency")
+	if code != 0 {
+		t.Fatalf("update-slo failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	body := requestBody(t, puts()[0])
+	if slugs, _ := body["dataset_slugs"].([]any); len(slugs) != 2 || slugs[0] != "api" || slugs[1] != "web" {
+		t.Errorf("expected the dataset slugs to be kept, got %v", body["dataset_slugs"])
+	}
+}
+
+func TestUpdateDatasetKeepsUnsetFields(t *testing.T) {
+	srv, puts := newPartialUpdateServer(t)
+	defer srv.Close()
+
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "update-dataset", "--slug", "api", "--delete-protected=false", "--show-diff")
+	if code != 0 {
+		t.Fatalf("update-dataset failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if !strings.Contains(stderr, "~ settings.delete_protected: true -> false") {
+		t.Errorf("unexpected diff: %s", stderr)
+	}
+	body := requestBody(t, puts()[0])
+	if body["description"] != "API traffic" || body["expand_json_depth"] != float64(2) {
+		t.Errorf("expected description and depth to be kept, got %v", body)
+	}
+}
+
+func TestUpdateWithoutFields(t *testing.T) {
+	srv, puts := newPartialUpdateServer(t)
+	defer srv.Close()
+
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "update-slo", "--dataset", "api", "--id", "slo-1")
+	if code == 0 || !strings.Contains(stderr, "nothing to update") {
+		t.Errorf("expected update without fields to fail, got code %d, stderr: %s", code, stderr)
+	}
+	if len(puts()) != 0 {
+		t.Error("expected nothing to be sent")
+	}
+}
+
+func TestUpdateMarkerKeepsUnsetFields(t *testing.T) {
+	srv, puts := newPartialUpdateServer(t)
+	defer srv.Close()
+
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "update-marker", "--dataset", "api", "--id", "m-1", "--message", "v1.0.1", "--show-diff")
+	if code != 0 {
+		t.Fatalf("update-marker failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if strings.TrimSpace(stderr) != `~ message: "v1" -> "v1.0.1"` {
+		t.Errorf("unexpected diff: %s", stderr)
+	}
+	body := requestBody(t, puts()[0])
+	if body["type"] != "deploy" || body["url"] != "https://ci/1" || body["start_time"] != float64(1700000000) {
+		t.Errorf("expected type, url and start time to be kept, got %v", body)
+	}
+}
@@ -0,0 +1,173 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const planManifest = `
+dataset: api
+derived_columns:
+  - alias: sli_ok
+    expression: LT($status_code, 500)
+slos:
+  - name: Availability
+    sli: {alias: sli_ok}
+    time_period_days: 30
+    target_per_million: 999000
+burn_alerts:
+  - slo_name: Availability
+    exhaustion_minutes: 60
+    description: page
+    recipients: [{type: email, target: oncall@example.com}]
+`
+
+// newPlanServer serves one existing SLO with an outdated target and records
+// mutating requests with their bodies.
+func newPlanServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	return newRecordingServer(t, func(w http.ResponseWriter, r *http.Request, _ []byte) {
+		if r.Method != http.MethodGet {
+			if r.Method == http.MethodPut {
+				fmt.Fprintf(w, `{"id":%q}`, filepath.Base(r.URL.Path))
+				return
+			}
+			fmt.Fprint(w, `{"id":"created-1"}`)
+			return
+		}
+		switch r.URL.Path {
+		case "/1/derived_columns/api":
+			fmt.Fprint(w, `[{"id":"dc-old","alias":"unmanaged","expression":"INT(1)"}]`)
+		case "/1/slos/api":
+			fmt.Fprint(w, `[{"id":"slo-1","name":"Availability","sli":{"alias":"sli_ok"},"time_period_days":30,"target_per_million":990000}]`)
+		default:
+			fmt.Fprint(w, `[]`)
+		}
+	})
+}
+
+func writeManifest(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "api.yaml"), []byte(planManifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestPlanCLI(t *testing.T) {
+	srv, writes := newPlanServer(t)
+	defer srv.Close()
+	dir := writeManifest(t)
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "plan", "--dir", dir, "--prune")
+	if code != 0 {
+		t.Fatalf("plan failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if w := writes(); len(w) != 0 {
+		t.Errorf("expected plan to make no changes, got %v", w)
+	}
+
+	result := parseJSON(t, stdout)
+	summary := result["summary"].(map[string]any)
+	if summary["create"] != float64(2) || summary["update"] != float64(1) || summary["delete"] != float64(1) {
+		t.Errorf("unexpected summary: %v", summary)
+	}
+
+	var slo map[string]any
+	for _, c := range result["changes"].([]any) {
+		m := c.(map[string]any)
+		if m["kind"] == "slo" {
+			slo = m
+		}
+	}
+	fields := slo["fields"].([]any)
+	if len(fields) != 1 {
+		t.Fatalf("expected 1 changed SLO field, got %v", fields)
+	}
+	f := fields[0].(map[string]any)
+	if f["field"] != "target_per_million" || f["old"] != float64(990000) || f["new"] != float64(999000) {
+		t.Errorf("unexpected SLO field change: %v", f)
+	}
+
+	stdout, _, code = runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "plan", "--dir", dir, "--output", "text")
+	if code != 0 {
+		t.Fatalf("plan --output text failed with exit code %d", code)
+	}
+	if !strings.Contains(stdout, "~ slo api/Availability") || !strings.Contains(stdout, "Plan: 2 to create, 1 to update, 0 to delete") {
+		t.Errorf("unexpected text plan:\n%s", stdout)
+	}
+}
+
+func TestApplyCLI(t *testing.T) {
+	srv, writes := newPlanServer(t)
+	defer srv.Close()
+	dir := writeManifest(t)
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "apply", "--dir", dir, "--prune")
+	if code != 0 {
+		t.Fatalf("apply failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if n := len(parseJSONArray(t, stdout)); n != 4 {
+		t.Errorf("expected 4 applied changes, got %d", n)
+	}
+
+	w := writes()
+	if len(w) != 4 {
+		t.Fatalf("expected 4 mutating requests, got %v", w)
+	}
+	wantPrefixes := []string{
+		"POST /1/derived_columns/api ",
+		"PUT /1/slos/api/slo-1 ",
+		"POST /1/burn_alerts/api ",
+		"DELETE /1/derived_columns/api/dc-old",
+	}
+	for i, prefix := range wantPrefixes {
+		if !strings.HasPrefix(w[i], prefix) {
+			t.Errorf("request %d = %q, want prefix %q", i, w[i], prefix)
+		}
+	}
+
+	ba := requestBody(t, w[2])
+	if slo, _ := ba["slo"].(map[string]any); slo["id"] != "slo-1" {
+		t.Errorf("expected burn alert to reference slo-1, got %v", ba["slo"])
+	}
+	if _, ok := ba["slo_name"]; ok {
+		t.Errorf("expected slo_name not to be sent to the API, got %v", ba)
+	}
+}
+
+func TestApplyCLIBurnAlertsForUndeclaredSLO(t *testing.T) {
+	srv, writes := newPlanServer(t)
+	defer srv.Close()
+	dir := t.TempDir()
+	manifest := `
+dataset: api
+burn_alerts:
+  - slo_name: Availability
+    exhaustion_minutes: 60
+    recipients: [{type: email, target: oncall@example.com}]
+`
+	if err := os.WriteFile(filepath.Join(dir, "api.yaml"), []byte(manifest), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "apply", "--dir", dir)
+	if code != 0 {
+		t.Fatalf("apply failed with exit code %d\nstderr: %s", code, stderr)
+	}
+
+	w := writes()
+	if len(w) != 1 || !strings.HasPrefix(w[0], "POST /1/burn_alerts/api ") {
+		t.Fatalf("expected 1 burn alert create, got %v", w)
+	}
+	ba := requestBody(t, w[0])
+	if slo, _ := ba["slo"].(map[string]any); slo["id"] != "slo-1" {
+		t.Errorf("expected burn alert to reference slo-1, got %v", ba["slo"])
+	}
+}
@@ -82,7 +82,7 @@ func TestQueryAnnotationCRUDCLI_Smoke(t *testing.T) {
 		t.Errorf("expected to find annotation %q in list", id)
 	}
 
-	_, _, exitCode = runCLIWithKey(t, "delete-query-annotation", "--dataset", dataset, "--id", id)
+	_, _, exitCode = runCLIWithKey(t, "delete-query-annotation", "--dataset", dataset, "--id", id, "--yes")
 	if exitCode != 0 {
 		t.Fatalf("delete-query-annotation failed with exit code %d", exitCode)
 	}
@@ -0,0 +1,113 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newSchemaServer serves a different schema of dataset "api" for each API
+// key, standing in for two environments.
+func newSchemaServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		prod := r.Header.Get("X-Honeycomb-Team") == "prod-key"
+		switch r.URL.Path {
+		case "/1/columns/api":
+			if prod {
+				fmt.Fprint(w, `[{"id":"c-1","key_name":"status_code","type":"integer"},{"id":"c-2","key_name":"legacy","type":"string"}]`)
+			} else {
+				fmt.Fprint(w, `[{"id":"c-1","key_name":"status_code","type":"string"},{"id":"c-3","key_name":"duration_ms","type":"float"}]`)
+			}
+		case "/1/derived_columns/api":
+			if prod {
+				fmt.Fprint(w, `[{"id":"dc-1","alias":"is_error","expression":"GTE($status_code, 500)"}]`)
+			} else {
+				fmt.Fprint(w, `[{"id":"dc-1","alias":"is_error","expression":"GTE($status_code, 400)"}]`)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestDiffSchemaAcrossEnvironments(t *testing.T) {
+	srv := newSchemaServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runCLIWithEnv(t, append(os.Environ(), "PROD_KEY=prod-key"),
+		"--api-key", "staging-key", "--api-url", srv.URL,
+		"diff-schema", "--dataset", "api", "--other-api-key-env", "PROD_KEY")
+	if code != 2 {
+		t.Fatalf("expected exit code 2, got %d\nstderr: %s", code, stderr)
+	}
+
+	report := parseJSON(t, stdout)
+	var got []string
+	for _, c := range report["changes"].([]any) {
+		m := c.(map[string]any)
+		got = append(got, fmt.Sprintf("%s %s %s", m["kind"], m["name"], m["change"]))
+	}
+	want := []string{
+		"column duration_ms added",
+		"column legacy removed",
+		"column status_code type_changed",
+		"derived_column is_error expression_changed",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("changes = %q, want %q", got, want)
+	}
+}
+
+func TestDiffSchemaAgainstSnapshot(t *testing.T) {
+	srv := newSchemaServer(t)
+	defer srv.Close()
+
+	path := filepath.Join(t.TempDir(), "api.yaml")
+	_, stderr, code := runCLI(t, "--api-key", "prod-key", "--api-url", srv.URL,
+		"export-schema", "--dataset", "api", "--file", path)
+	if code != 0 {
+		t.Fatalf("export-schema failed with exit code %d\nstderr: %s", code, stderr)
+	}
+
+	stdout, stderr, code := runCLI(t, "--api-key", "prod-key", "--api-url", srv.URL,
+		"diff-schema", "--dataset", "api", "--snapshot", path, "--output", "text")
+	if code != 0 {
+		t.Fatalf("expected no changes against own snapshot, got exit code %d\nstdout: %s\nstderr: %s", code, stdout, stderr)
+	}
+	if !strings.Contains(stdout, "No schema changes.") {
+		t.Errorf("expected no changes, got:\n%s", stdout)
+	}
+
+	stdout, _, code = runCLI(t, "--api-key", "staging-key", "--api-url", srv.URL,
+		"diff-schema", "--dataset", "api", "--snapshot", path, "--output", "text")
+	if code != 2 {
+		t.Fatalf("expected exit code 2, got %d", code)
+	}
+	for _, want := range []string{
+		"+ column duration_ms (float)",
+		"- column legacy (string)",
+		"~ column status_code: integer -> string",
+		"~ derived_column is_error: GTE($status_code, 500) -> GTE($status_code, 400)",
+		"1 added, 1 removed, 2 changed.",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected %q in output:\n%s", want, stdout)
+		}
+	}
+}
+
+func TestDiffSchemaRequiresBaseline(t *testing.T) {
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "diff-schema", "--dataset", "api")
+	if code == 0 {
+		t.Fatal("expected diff-schema without a baseline to fail")
+	}
+	if !strings.Contains(stderr, "--snapshot") {
+		t.Errorf("expected error to mention --snapshot, got: %s", stderr)
+	}
+}
@@ -0,0 +1,103 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSLOBudgetCLICalculator(t *testing.T) {
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key",
+		"slo-budget", "--target-per-million", "999000", "--time-period-days", "30", "--exhaustion-minutes", "120")
+	if code != 0 {
+		t.Fatalf("slo-budget failed with exit code %d\nstderr: %s", code, stderr)
+	}
+
+	report := parseJSON(t, stdout)
+	if report["allowed_downtime"] != "43m 12s" || report["allowed_bad_per_million"] != float64(1000) {
+		t.Errorf("unexpected budget: %v", report)
+	}
+	exhaustion := report["exhaustion_time"].([]any)
+	if len(exhaustion) != 1 {
+		t.Fatalf("expected only the requested exhaustion minutes to be explained, got %v", exhaustion)
+	}
+	if e := exhaustion[0].(map[string]any); e["burn_rate"] != float64(360) {
+		t.Errorf("expected 120 exhaustion minutes to mean burn rate 360, got %v", e)
+	}
+}
+
+func TestSLOBudgetCLIExhaustedBudget(t *testing.T) {
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "slo-budget", "--target-per-million", "999000",
+		"--time-period-days", "30", "--budget-remaining", "-5", "--exhaustion-minutes", "120", "--output", "text")
+	if code != 0 {
+		t.Fatalf("slo-budget failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "exhaustion_minutes=120: budget exhausted; exhaustion_time alerts fire at any burn rate") {
+		t.Errorf("expected the exhausted budget to be explained, got:\n%s", stdout)
+	}
+	if strings.Contains(stdout, "burn rate of -") {
+		t.Errorf("expected no negative burn rate, got:\n%s", stdout)
+	}
+}
+
+func TestSLOBudgetCLIExistingSLO(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/1/slos/api/slo-1":
+			fmt.Fprint(w, `{"id":"slo-1","name":"Availability","target_per_million":990000,"time_period_days":30,"budget_remaining":50,"burn_rate":2}`)
+		case "/1/burn_alerts/api":
+			fmt.Fprint(w, `[{"id":"ba-1","description":"page","alert_type":"budget_rate","budget_rate_window_minutes":60,"budget_rate_decrease_threshold_per_million":20000}]`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"slo-budget", "--dataset", "api", "--id", "slo-1", "--output", "text")
+	if code != 0 {
+		t.Fatalf("slo-budget failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	for _, want := range []string{"SLO Availability (slo-1)", "Budget remaining:  50.00%", "Exhausted in:      7d 12h", "(page): fires when 2.00% of the budget is spent within 1h"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected output to contain %q, got:\n%s", want, stdout)
+		}
+	}
+	if strings.Contains(stdout, "example") {
+		t.Errorf("expected existing burn alerts instead of examples, got:\n%s", stdout)
+	}
+}
+
+func TestSLOBudgetCLIRequiresInput(t *testing.T) {
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "slo-budget", "--target-per-million", "999000")
+	if code == 0 {
+		t.Fatal("expected slo-budget without a period to fail")
+	}
+	if !strings.Contains(stderr, "--time-period-days") {
+		t.Errorf("expected usage hint on stderr, got: %s", stderr)
+	}
+}
+
+func TestSLOBudgetCLIRejectsInvalidThresholds(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		args []string
+		want string
+	}{
+		{"zero exhaustion minutes", []string{"--exhaustion-minutes", "0"}, "--exhaustion-minutes must be positive"},
+		{"decrease without window", []string{"--budget-rate-decrease-per-million", "20000"}, "must be given together"},
+		{"window without decrease", []string{"--budget-rate-window-minutes", "60"}, "must be given together"},
+		{"zero window", []string{"--budget-rate-window-minutes", "0", "--budget-rate-decrease-per-million", "20000"}, "must be positive"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			args := append([]string{"--api-key", "fake-key", "slo-budget", "--target-per-million", "999000", "--time-period-days", "30"}, tc.args...)
+			_, stderr, code := runCLI(t, args...)
+			if code == 0 || !strings.Contains(stderr, tc.want) {
+				t.Errorf("expected %q, got code %d, stderr: %s", tc.want, code, stderr)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newSLOReportServer serves two datasets with SLOs in different states.
+func newSLOReportServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/1/datasets":
+			fmt.Fprint(w, `[{"slug":"api"},{"slug":"web"}]`)
+		case "/1/slos/api":
+			fmt.Fprint(w, `[{"id":"slo-1","name":"Availability"},{"id":"slo-2","name":"Latency"}]`)
+		case "/1/slos/web":
+			fmt.Fprint(w, `[{"id":"slo-3","name":"Page loads"}]`)
+		case "/1/slos/api/slo-1":
+			fmt.Fprint(w, `{"id":"slo-1","name":"Availability","target_per_million":999000,"compliance":99.95,"budget_remaining":50,"burn_rate":0.5,"status":"ok"}`)
+		case "/1/slos/api/slo-2":
+			fmt.Fprint(w, `{"id":"slo-2","name":"Latency","target_per_million":990000,"compliance":98.2,"budget_remaining":-80,"burn_rate":3.1,"status":"failing"}`)
+		case "/1/slos/web/slo-3":
+			fmt.Fprint(w, `{"id":"slo-3","name":"Page loads","target_per_million":995000,"compliance":99.6,"budget_remaining":12,"burn_rate":1.4,"status":"ok"}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestSLOReportCLI(t *testing.T) {
+	srv := newSLOReportServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "slo-report")
+	if code != 2 {
+		t.Fatalf("expected exit code 2 with a failing SLO, got %d\nstderr: %s", code, stderr)
+	}
+
+	rows := parseJSONArray(t, stdout)
+	var names []string
+	for _, r := range rows {
+		names = append(names, r.(map[string]any)["name"].(string))
+	}
+	if got := strings.Join(names, ","); got != "Latency,Page loads,Availability" {
+		t.Errorf("expected SLOs sorted by risk, got %s", got)
+	}
+	first := rows[0].(map[string]any)
+	if first["failing"] != true || first["target_percent"] != float64(99) {
+		t.Errorf("unexpected first row: %v", first)
+	}
+}
+
+func TestSLOReportCLIBelowBudget(t *testing.T) {
+	srv := newSLOReportServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"slo-report", "--dataset", "api", "--dataset", "web", "--below-budget", "60%", "--output", "text")
+	if code != 2 {
+		t.Fatalf("expected exit code 2 with a failing SLO, got %d\nstderr: %s", code, stderr)
+	}
+	for _, want := range []string{"Latency", "Page loads", "Availability", "FAILING"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected text report to contain %q, got:\n%s", want, stdout)
+		}
+	}
+
+	stdout, stderr, code = runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"slo-report", "--dataset", "web", "--below-budget", "20")
+	if code != 0 {
+		t.Fatalf("expected exit code 0 without failing SLOs, got %d\nstderr: %s", code, stderr)
+	}
+	if rows := parseJSONArray(t, stdout); len(rows) != 1 || rows[0].(map[string]any)["name"] != "Page loads" {
+		t.Errorf("unexpected rows: %v", rows)
+	}
+}
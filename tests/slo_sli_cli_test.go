@@ -0,0 +1,150 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newSLIServer answers the SLI verification query with the given result rows
+// and records mutating requests. Requests to failPath fail.
+func newSLIServer(t *testing.T, results, failPath string) (*httptest.Server, func() []string) {
+	t.Helper()
+	return newRecordingServer(t, func(w http.ResponseWriter, r *http.Request, body []byte) {
+		switch {
+		case r.URL.Path == failPath:
+			http.Error(w, `{"error":"boom"}`, http.StatusInternalServerError)
+		case r.URL.Path == "/1/derived_columns/api" && r.Method == http.MethodPost:
+			fmt.Fprint(w, `{"id":"dc-1","alias":"sli_api_availability"}`)
+		case r.URL.Path == "/1/queries/api":
+			fmt.Fprint(w, `{"id":"q-1"}`)
+		case r.URL.Path == "/1/query_results/api":
+			fmt.Fprintf(w, `{"id":"qr-1","complete":true,"data":{"results":%s}}`, results)
+		case r.URL.Path == "/1/slos/api":
+			w.Write(body)
+		case r.Method == http.MethodDelete:
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func TestCreateSLOWithGeneratedSLI(t *testing.T) {
+	srv, writes := newSLIServer(t, `[
+		{"data":{"sli_api_availability":true,"COUNT":990}},
+		{"data":{"sli_api_availability":false,"COUNT":10}},
+		{"data":{"sli_api_availability":null,"COUNT":500}}
+	]`, "")
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"create-slo", "--dataset", "api", "--name", "API availability",
+		"--sli-good", "status_code < 500", "--sli-eligible", "service.name = api",
+		"--time-period-days", "30", "--target-per-million", "999000")
+	if code != 0 {
+		t.Fatalf("create-slo failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	for _, want := range []string{"990 good, 10 bad, 500 not eligible", "Historical compliance: 99.0000%", "would not have met the target"} {
+		if !strings.Contains(stderr, want) {
+			t.Errorf("expected stderr to contain %q, got: %s", want, stderr)
+		}
+	}
+
+	w := writes()
+	if len(w) != 4 {
+		t.Fatalf("expected derived column, query, query result and SLO requests, got %v", w)
+	}
+	dc := requestBody(t, w[0])
+	if dc["alias"] != "sli_api_availability" || dc["expression"] != `IF(EQUALS($service.name, "api"), LT($status_code, 500))` {
+		t.Errorf("unexpected derived column: %v", dc)
+	}
+
+	slo := parseJSON(t, stdout)
+	if sli, _ := slo["sli"].(map[string]any); sli["alias"] != "sli_api_availability" {
+		t.Errorf("expected SLO to use the generated SLI, got %v", slo)
+	}
+}
+
+func TestCreateSLOWithGeneratedSLINoEligibleEvents(t *testing.T) {
+	srv, writes := newSLIServer(t, `[{"data":{"sli_x":null,"COUNT":500}}]`, "")
+	defer srv.Close()
+
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+		"create-slo", "--dataset", "api", "--name", "x", "--sli-alias", "sli_x",
+		"--sli-good", "status_code < 500", "--sli-good", "duration_ms <= 300",
+		"--time-period-days", "30", "--target-per-million", "999000")
+	if code == 0 {
+		t.Fatal("expected create-slo to fail without eligible events")
+	}
+	if !strings.Contains(stderr, "no eligible events in the last day") {
+		t.Errorf("unexpected stderr: %s", stderr)
+	}
+
+	w := writes()
+	if !strings.Contains(w[0], `AND(LT($status_code, 500), LTE($duration_ms, 300))`) {
+		t.Errorf("expected conditions combined with AND, got %s", w[0])
+	}
+	if last := w[len(w)-1]; !strings.HasPrefix(last, "DELETE /1/derived_columns/api/dc-1") {
+		t.Errorf("expected generated derived column to be deleted, got %v", w)
+	}
+}
+
+func TestCreateSLOWithGeneratedSLIDeletesColumnOnFailure(t *testing.T) {
+	for name, failPath := range map[string]string{
+		"verification": "/1/query_results/api",
+		"slo create":   "/1/slos/api",
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv, writes := newSLIServer(t, `[{"data":{"sli_x":true,"COUNT":10}}]`, failPath)
+			defer srv.Close()
+
+			_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL,
+				"create-slo", "--dataset", "api", "--name", "x", "--sli-alias", "sli_x",
+				"--sli-good", "status_code < 500",
+				"--time-period-days", "30", "--target-per-million", "999000")
+			if code == 0 {
+				t.Fatal("expected create-slo to fail")
+			}
+			w := writes()
+			if last := w[len(w)-1]; !strings.HasPrefix(last, "DELETE /1/derived_columns/api/dc-1") {
+				t.Errorf("expected generated derived column to be deleted, got %v", w)
+			}
+			if !strings.Contains(stderr, "Deleted derived column sli_x") {
+				t.Errorf("expected deletion to be reported, got: %s", stderr)
+			}
+		})
+	}
+}
+
+func TestCreateSLORequiresSLI(t *testing.T) {
+	_, stderr, code := runCLI(t, "--api-key", "fake-key",
+		"create-slo", "--dataset", "api", "--name", "x", "--time-period-days", "30", "--target-per-million", "999000")
+	if code == 0 || !strings.Contains(stderr, "either --sli-alias or --sli-good is required") {
+		t.Errorf("expected missing SLI error, got code %d, stderr: %s", code, stderr)
+	}
+}
+
+func TestCreateSLOValidatesBeforeGeneratingSLI(t *testing.T) {
+	for name, args := range map[string][]string{
+		"tags":   {"--target-per-million", "999000", "--tags-json", "not json"},
+		"target": {"--target-per-million", "1000000"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv, writes := newSLIServer(t, `[{"data":{"sli_x":true,"COUNT":10}}]`, "")
+			defer srv.Close()
+
+			_, stderr, code := runCLI(t, append([]string{"--api-key", "fake-key", "--api-url", srv.URL,
+				"create-slo", "--dataset", "api", "--name", "x", "--sli-alias", "sli_x",
+				"--sli-good", "status_code < 500", "--time-period-days", "30"}, args...)...)
+			if code == 0 {
+				t.Fatal("expected create-slo to fail")
+			}
+			if w := writes(); len(w) != 0 {
+				t.Errorf("expected nothing to be created, got %v\nstderr: %s", w, stderr)
+			}
+		})
+	}
+}
@@ -23,7 +23,7 @@ func TestSLOCRUDCLI_Smoke(t *testing.T) {
 		t.Fatal("expected non-empty derived column id")
 	}
 	defer func() {
-		runCLIWithKey(t, "delete-derived-column", "--dataset", dataset, "--id", dcID)
+		runCLIWithKey(t, "delete-derived-column", "--dataset", dataset, "--id", dcID, "--yes")
 	}()
 
 	// Create SLO
@@ -48,7 +48,7 @@ func TestSLOCRUDCLI_Smoke(t *testing.T) {
 		t.Errorf("expected name 'test SLO', got %v", slo["name"])
 	}
 	defer func() {
-		runCLIWithKey(t, "delete-slo", "--dataset", dataset, "--id", sloID)
+		runCLIWithKey(t, "delete-slo", "--dataset", dataset, "--id", sloID, "--yes")
 	}()
 
 	// Get SLO
@@ -99,7 +99,7 @@ func TestSLOCRUDCLI_Smoke(t *testing.T) {
 	}
 
 	// Delete SLO (explicit, before defer)
-	_, _, exitCode = runCLIWithKey(t, "delete-slo", "--dataset", dataset, "--id", sloID)
+	_, _, exitCode = runCLIWithKey(t, "delete-slo", "--dataset", dataset, "--id", sloID, "--yes")
 	if exitCode != 0 {
 		t.Fatalf("delete-slo failed with exit code %d", exitCode)
 	}
@@ -0,0 +1,112 @@
+package main_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// newTraceServer serves the spans of one trace, with traceId as the trace
+// ID column, and records the query and query result requests. Span c is
+// split over two rows because its events differ in the error column.
+func newTraceServer(t *testing.T) (*httptest.Server, func() []string) {
+	t.Helper()
+	return newRecordingServer(t, func(w http.ResponseWriter, r *http.Request, _ []byte) {
+		switch r.URL.Path {
+		case "/1/dataset_definitions/api":
+			w.Write([]byte(`{"trace_id":{"name":"traceId"}}`))
+		case "/1/queries/api":
+			w.Write([]byte(`{"id":"q-1"}`))
+		case "/1/query_results/api":
+			w.Write([]byte(`{"id":"r-1","complete":true,"data":{"results":[
+				{"data":{"trace.span_id":"c","trace.parent_id":"a","service.name":"payments","name":"charge","error":true,"MAX(duration_ms)":50,"MIN(span_start_ms)":1040}},
+				{"data":{"trace.span_id":"a","trace.parent_id":null,"service.name":"api","name":"GET /checkout","error":null,"MAX(duration_ms)":100,"MIN(span_start_ms)":1000}},
+				{"data":{"trace.span_id":"b","trace.parent_id":"a","service.name":"db","name":"SELECT","error":null,"MAX(duration_ms)":20,"MIN(span_start_ms)":1010}},
+				{"data":{"trace.span_id":"c","trace.parent_id":"a","service.name":"payments","name":"charge","error":false,"MAX(duration_ms)":30,"MIN(span_start_ms)":1045}}
+			]}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	})
+}
+
+func TestTraceWaterfall(t *testing.T) {
+	srv, writes := newTraceServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "trace", "--dataset", "api", "--trace-id", "t-1", "--start-column", "span_start_ms")
+	if code != 0 {
+		t.Fatalf("trace failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	for _, want := range []string{
+		"Trace t-1: 3 span(s), 100.0ms",
+		"GET /checkout",
+		"├─ SELECT",
+		"└─ ✗ charge",
+		"|" + strings.Repeat("█", 40) + "|",
+		"|" + strings.Repeat(" ", 16) + strings.Repeat("█", 20) + strings.Repeat(" ", 4) + "|",
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("expected %q in waterfall:\n%s", want, stdout)
+		}
+	}
+
+	query := requestBody(t, writes()[0])
+	filters, _ := query["filters"].([]any)
+	if len(filters) != 1 || filters[0].(map[string]any)["column"] != "traceId" {
+		t.Errorf("expected a filter on the defined trace ID column, got %v", query["filters"])
+	}
+}
+
+func TestTraceJSON(t *testing.T) {
+	srv, _ := newTraceServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "trace", "--dataset", "api", "--trace-id", "t-1", "--start-column", "span_start_ms", "--output", "json")
+	if code != 0 {
+		t.Fatalf("trace failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	tree := parseJSON(t, stdout)
+	roots := tree["roots"].([]any)
+	if len(roots) != 1 {
+		t.Fatalf("expected 1 root, got %d", len(roots))
+	}
+	root := roots[0].(map[string]any)
+	children := root["children"].([]any)
+	if root["span_id"] != "a" || len(children) != 2 {
+		t.Fatalf("unexpected root: %v", root)
+	}
+	charge := children[1].(map[string]any)
+	if charge["span_id"] != "c" || charge["start_offset_ms"] != float64(40) || charge["duration_ms"] != float64(50) || charge["error"] != true {
+		t.Errorf("unexpected second child: %v", charge)
+	}
+}
+
+func TestTraceWarnsAtRowLimit(t *testing.T) {
+	rows := make([]string, 1000)
+	for i := range rows {
+		rows[i] = fmt.Sprintf(`{"data":{"trace.span_id":"s%d","trace.parent_id":null,"name":"work","MAX(duration_ms)":1}}`, i)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/1/queries/api":
+			w.Write([]byte(`{"id":"q-1"}`))
+		case "/1/query_results/api":
+			fmt.Fprintf(w, `{"id":"r-1","complete":true,"data":{"results":[%s]}}`, strings.Join(rows, ","))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	_, stderr, code := runCLI(t, "--api-key", "fake-key", "--api-url", srv.URL, "trace", "--dataset", "api", "--trace-id", "t-1", "--output", "json")
+	if code != 0 {
+		t.Fatalf("trace failed with exit code %d\nstderr: %s", code, stderr)
+	}
+	if !strings.Contains(stderr, "spans of the trace may be missing") {
+		t.Errorf("expected a truncation warning, got: %s", stderr)
+	}
+}